@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AudienceComparator reports whether an aud claim value matches an
+// accepted audience.
+type AudienceComparator func(want, have string) bool
+
+// Audience comparators.
+var (
+	// AudienceExact requires the values to be identical. It is the
+	// default comparator.
+	AudienceExact AudienceComparator = func(want, have string) bool {
+		return want == have
+	}
+
+	// AudienceFold compares values case-insensitively.
+	AudienceFold AudienceComparator = strings.EqualFold
+
+	// AudienceURL compares absolute URLs after normalization, ignoring
+	// the case of the scheme and host, default ports and a trailing
+	// slash. Values that are not absolute URLs must be identical.
+	AudienceURL AudienceComparator = func(want, have string) bool {
+		return normalizeURL(want) == normalizeURL(have)
+	}
+)
+
+// audienceComparators are the comparators selectable by name in Config.
+var audienceComparators = map[string]AudienceComparator{
+	"":      AudienceExact,
+	"exact": AudienceExact,
+	"fold":  AudienceFold,
+	"url":   AudienceURL,
+}
+
+// WithAudienceComparator returns an option that compares aud claim
+// values to the accepted audiences with fn.
+func WithAudienceComparator(fn AudienceComparator) Option {
+	return func(v *Verifier) {
+		v.audienceEqual = fn
+	}
+}
+
+// normalizeURL returns the normalized form of an absolute URL, or s
+// unchanged if it is not an absolute URL.
+func normalizeURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return s
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	port := u.Port()
+	if (u.Scheme == "https" && port == "443") || (u.Scheme == "http" && port == "80") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	return u.String()
+}
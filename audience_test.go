@@ -0,0 +1,37 @@
+package jwt
+
+import "testing"
+
+func TestAudienceComparator(t *testing.T) {
+	var tests = []struct {
+		equal AudienceComparator
+		want  string
+		have  string
+		ok    bool
+	}{
+		{AudienceExact, "api", "api", true},
+		{AudienceExact, "api", "API", false},
+		{AudienceFold, "api", "API", true},
+		{AudienceFold, "https://api.example.com", "https://api.example.com/", false},
+		{AudienceURL, "https://api.example.com", "https://api.example.com/", true},
+		{AudienceURL, "https://api.example.com/v1/", "HTTPS://API.Example.com:443/v1", true},
+		{AudienceURL, "http://api.example.com:80", "http://api.example.com", true},
+		{AudienceURL, "https://api.example.com:8443", "https://api.example.com", false},
+		{AudienceURL, "https://api.example.com/v1", "https://api.example.com/V1", false},
+		{AudienceURL, "https://api.example.com?a=1", "https://api.example.com/?a=1", true},
+		{AudienceURL, "api", "api/", false},
+		{AudienceURL, "urn:example:api", "urn:example:api", true},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims["aud"] = []string{"other", tt.have}
+		jwt, err := token.Sign([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Parse(HS256, jwt, []byte("secret"), WithAudience(tt.want), WithAudienceComparator(tt.equal))
+		if (err == nil) != tt.ok {
+			t.Errorf("%d. Parse %q with %q err\nhave %v\nwant ok %v", i, tt.have, tt.want, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package bench is the benchmark suite of the jwt module. It covers
+// signing and verification for each algorithm family, parsing without
+// verification, the cached key path of a remote key set and the
+// overhead of the HTTP middleware.
+//
+// Run the suite with:
+//
+//	go test -bench . -benchmem ./bench
+//
+// Timings vary by machine and are not checked, but go test fails if
+// an operation allocates more than its published baseline, so changes
+// that add allocations to a hot path are caught in review. Lower the
+// baseline when an optimization removes allocations.
+//
+// BenchmarkRetained reports the heap retained by 100,000 verified
+// tokens, the volume of a server verifying 100k tokens/sec, with and
+// without jwt.WithInterning.
+package bench
+
+// Baselines is the maximum allocations per operation of each
+// benchmark of the suite, measured with Go 1.27 on linux/amd64.
+var Baselines = map[string]float64{
+	"sign/HS256":        40,
+	"verify/HS256":      51,
+	"sign/RS256":        38,
+	"verify/RS256":      56,
+	"sign/PS256":        44,
+	"verify/PS256":      60,
+	"sign/ES256":        100,
+	"verify/ES256":      67,
+	"sign/EdDSA":        35,
+	"verify/EdDSA":      45,
+	"parse":             18,
+	"policy/plain":      48,
+	"policy/interned":   50,
+	"jwks/hit":          49,
+	"middleware/none":   0,
+	"middleware/verify": 70,
+}
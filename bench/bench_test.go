@@ -0,0 +1,261 @@
+package bench
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/pnelson/jwt"
+	"github.com/pnelson/jwt/middleware"
+)
+
+// benchCase is a single operation of the suite.
+type benchCase struct {
+	name string
+	fn   func() error
+}
+
+// algorithm is a signer and its native signing and verification keys.
+type algorithm struct {
+	signer jwt.Signer
+	priv   interface{}
+	pub    interface{}
+}
+
+// suite returns the operations of the suite. The caller must call the
+// returned function to release the fixtures.
+func suite(tb testing.TB) ([]benchCase, func()) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	algorithms := []algorithm{
+		{jwt.HS256, []byte("secret"), []byte("secret")},
+		{jwt.RS256, rsaKey, &rsaKey.PublicKey},
+		{jwt.PS256, rsaKey, &rsaKey.PublicKey},
+		{jwt.ES256, ecKey, &ecKey.PublicKey},
+		{jwt.EdDSA, edKey, edPub},
+	}
+	claims := map[string]interface{}{"iss": "https://issuer.example", "sub": "user", "aud": "api", "exp": 4102444800}
+	var cases []benchCase
+	for _, a := range algorithms {
+		a := a
+		token := jwt.New(a.signer)
+		token.Claims = claims
+		raw, err := token.SignKey(a.priv)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		cases = append(cases,
+			benchCase{"sign/" + a.signer.String(), func() error {
+				t := jwt.New(a.signer)
+				t.Claims = claims
+				_, err := t.SignKey(a.priv)
+				return err
+			}},
+			benchCase{"verify/" + a.signer.String(), func() error {
+				_, err := jwt.ParseWithKey(a.signer, raw, a.pub)
+				return err
+			}},
+		)
+	}
+
+	token := jwt.New(jwt.ES256)
+	token.Header["kid"] = "a"
+	token.Claims = claims
+	raw, err := token.SignKey(ecKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	cases = append(cases, benchCase{"parse", func() error {
+		i, err := jwt.Inspect(raw)
+		if err != nil {
+			return err
+		}
+		var c jwt.Claims
+		return json.Unmarshal(i.Payload, &c)
+	}})
+
+	policy := []jwt.Option{jwt.WithIssuer("https://issuer.example"), jwt.WithAudience("api")}
+	hmac := jwt.New(jwt.HS256)
+	hmac.Claims = claims
+	hmacRaw, err := hmac.Sign([]byte("secret"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, interned := range []bool{false, true} {
+		name, opts := "policy/plain", policy
+		if interned {
+			name, opts = "policy/interned", append([]jwt.Option{jwt.WithInterning()}, policy...)
+		}
+		v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey([]byte("secret")), opts...)
+		cases = append(cases, benchCase{name, func() error {
+			_, err := v.Verify(context.Background(), hmacRaw)
+			return err
+		}})
+	}
+
+	k, err := jwt.NewJWK(&ecKey.PublicKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	k.Kid = "a"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwt.KeySet{Keys: []jwt.JWK{*k}})
+	}))
+	keys := jwt.NewRemoteKeySet(srv.URL)
+	parsed, err := jwt.ParseWithKey(jwt.ES256, raw, &ecKey.PublicKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	_, err = keys.Key(context.Background(), parsed)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	cases = append(cases, benchCase{"jwks/hit", func() error {
+		_, err := keys.Key(context.Background(), parsed)
+		return err
+	}})
+
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.New(jwt.NewVerifier([]jwt.Signer{jwt.ES256}, jwt.StaticNativeKey(&ecKey.PublicKey))).Handler(noop)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+raw)
+	cases = append(cases,
+		benchCase{"middleware/none", func() error {
+			noop.ServeHTTP(httptest.NewRecorder(), req)
+			return nil
+		}},
+		benchCase{"middleware/verify", func() error {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				return jwt.ErrInvalidSignature
+			}
+			return nil
+		}},
+	)
+	return cases, srv.Close
+}
+
+func BenchmarkSuite(b *testing.B) {
+	cases, done := suite(b)
+	defer done()
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err := c.fn()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRetained verifies 100,000 tokens with distinct subjects, as
+// a server handling 100k tokens/sec does each second, and reports the
+// heap retained per token when the verified tokens are kept in memory.
+func BenchmarkRetained(b *testing.B) {
+	const n = 100000
+	tokens := make([]string, n)
+	for i := range tokens {
+		t := jwt.New(jwt.HS256)
+		t.Claims = map[string]interface{}{
+			"iss": "https://issuer.example",
+			"aud": []string{"https://api.example/orders", "https://api.example/billing"},
+			"sub": strconv.Itoa(i),
+		}
+		raw, err := t.Sign([]byte("secret"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		tokens[i] = raw
+	}
+	for _, interned := range []bool{false, true} {
+		name, opts := "plain", []jwt.Option(nil)
+		if interned {
+			name, opts = "interned", []jwt.Option{jwt.WithInterning()}
+		}
+		v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey([]byte("secret")), opts...)
+		b.Run(name, func(b *testing.B) {
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				verified := make([]*jwt.Token, n)
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				for j, raw := range tokens {
+					t, err := v.Verify(context.Background(), raw)
+					if err != nil {
+						b.Fatal(err)
+					}
+					verified[j] = t
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				retained += after.HeapAlloc - before.HeapAlloc
+				runtime.KeepAlive(verified)
+			}
+			b.ReportMetric(float64(retained)/float64(b.N)/n, "retained-B/token")
+		})
+	}
+}
+
+func TestBaselines(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are inflated by the race detector")
+	}
+	cases, done := suite(t)
+	defer done()
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		seen[c.name] = true
+		max, ok := Baselines[c.name]
+		if !ok {
+			t.Errorf("%s has no baseline", c.name)
+			continue
+		}
+		var err error
+		allocs := testing.AllocsPerRun(20, func() {
+			err = c.fn()
+		})
+		if err != nil {
+			t.Errorf("%s err\nhave %v\nwant %v", c.name, err, nil)
+			continue
+		}
+		t.Logf("%s: %.0f allocs/op", c.name, allocs)
+		if allocs > max {
+			t.Errorf("%s allocs/op\nhave %.0f\nwant <= %.0f", c.name, allocs, max)
+		}
+	}
+	var stale []string
+	for name := range Baselines {
+		if !seen[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		t.Errorf("%s baseline has no benchmark", name)
+	}
+}
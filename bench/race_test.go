@@ -0,0 +1,7 @@
+//go:build race
+
+package bench
+
+// raceEnabled is true if the race detector is enabled, which inflates
+// allocation counts.
+const raceEnabled = true
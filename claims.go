@@ -0,0 +1,329 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Claims is a set of token claims. It has the same underlying type as
+// the Token Claims field and may be converted with Claims(t.Claims).
+type Claims map[string]interface{}
+
+// MergePolicy determines how Merge resolves a claim present in both
+// claim sets.
+type MergePolicy int
+
+// Merge policies.
+const (
+	// MergeError returns ErrClaimConflict if a claim is present in both
+	// claim sets with different values.
+	MergeError MergePolicy = iota
+
+	// MergePreferOverride uses the value of the overriding claim set.
+	MergePreferOverride
+
+	// MergePreferBase uses the value of the base claim set.
+	MergePreferBase
+)
+
+// ErrClaimConflict is returned by Merge when claims conflict.
+var ErrClaimConflict = errors.New("jwt: conflicting claim values")
+
+// Merge returns a new claim set combining c with overrides, such as
+// tenant defaults with user claims and per-request grants. Nested
+// objects present in both claim sets are merged recursively and other
+// claims present in both are resolved by the policy. Neither claim set
+// is modified.
+func (c Claims) Merge(overrides Claims, policy MergePolicy) (Claims, error) {
+	m, err := merge(c, overrides, policy, "")
+	if err != nil {
+		return nil, err
+	}
+	return Claims(m), nil
+}
+
+// merge returns the deep merge of base and overrides. The path
+// identifies nested claims in errors.
+func merge(base, overrides map[string]interface{}, policy MergePolicy, path string) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(base)+len(overrides))
+	for k, v := range base {
+		m[k] = copyValue(v)
+	}
+	for k, v := range overrides {
+		prev, ok := m[k]
+		if !ok {
+			m[k] = copyValue(v)
+			continue
+		}
+		a, aok := object(prev)
+		b, bok := object(v)
+		if aok && bok {
+			nested, err := merge(a, b, policy, path+k+".")
+			if err != nil {
+				return nil, err
+			}
+			m[k] = nested
+			continue
+		}
+		if reflect.DeepEqual(prev, v) {
+			continue
+		}
+		switch policy {
+		case MergeError:
+			return nil, fmt.Errorf("%w: %s%s", ErrClaimConflict, path, k)
+		case MergePreferOverride:
+			m[k] = copyValue(v)
+		}
+	}
+	return m, nil
+}
+
+// object returns v as a JSON object if it is one.
+func object(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Claims:
+		return v, true
+	}
+	return nil, false
+}
+
+// copyValue returns a copy of v that does not share nested objects.
+func copyValue(v interface{}) interface{} {
+	o, ok := object(v)
+	if !ok {
+		return v
+	}
+	m := make(map[string]interface{}, len(o))
+	for k, v := range o {
+		m[k] = copyValue(v)
+	}
+	return m
+}
+
+// Hash returns a canonical hash of the claims, excluding the claims
+// named in ignore. Object keys are sorted and numbers are normalized,
+// so claim sets that are equal after JSON decoding hash equally
+// regardless of the Go types used to construct them.
+//
+// Ignoring volatile claims such as "iat" and "jti" allows tokens
+// carrying identical grants to be deduplicated.
+func (c Claims) Hash(ignore ...string) (string, error) {
+	m := make(map[string]interface{}, len(c))
+	for k, v := range c {
+		if !contains(ignore, k) {
+			m[k] = v
+		}
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&v)
+	if err != nil {
+		return "", err
+	}
+	b, err = json.Marshal(canonical(v))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return encode(sum[:]), nil
+}
+
+// Time returns the named date claim, such as "exp", as a time.
+// It returns false if the claim is not present or not a number.
+func (c Claims) Time(name string) (time.Time, bool) {
+	d, ok := c.Date(name)
+	if !ok {
+		return time.Time{}, false
+	}
+	return d.Time(), true
+}
+
+// Date returns the named date claim, such as "exp", as a NumericDate.
+// Integer values, such as int64 and json.Number, are converted exactly
+// and fractional seconds are truncated. It returns false if the claim
+// is not present, not a number or out of range.
+func (c Claims) Date(name string) (NumericDate, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return floatDate(v)
+	case int64:
+		return NumericDate(v), true
+	case int:
+		return NumericDate(v), true
+	case NumericDate:
+		return v, true
+	case json.Number:
+		return numberDate(v)
+	}
+	return 0, false
+}
+
+// Audience returns the aud claim, which may be either a single string
+// or an array of strings. It returns nil if there is no aud claim.
+func (c Claims) Audience() Audience {
+	aud := audience(c[ClaimAudience])
+	if len(aud) == 0 {
+		return nil
+	}
+	return Audience(aud)
+}
+
+// TimeToLive returns the time remaining before the exp claim, or zero
+// if the token has expired. It returns false if there is no exp claim.
+func (c Claims) TimeToLive(now time.Time) (time.Duration, bool) {
+	exp, ok := c.Time(ClaimExpiration)
+	if !ok {
+		return 0, false
+	}
+	ttl := exp.Sub(now)
+	if ttl < 0 {
+		return 0, true
+	}
+	return ttl, true
+}
+
+// Age returns the time elapsed since the iat claim.
+// It returns false if there is no iat claim.
+func (c Claims) Age(now time.Time) (time.Duration, bool) {
+	iat, ok := c.Time(ClaimIssuedAt)
+	if !ok {
+		return 0, false
+	}
+	return now.Sub(iat), true
+}
+
+// RefreshAt returns the time at which a token should be refreshed to
+// leave margin before the exp claim. It returns false if there is no
+// exp claim.
+func (c Claims) RefreshAt(margin time.Duration) (time.Time, bool) {
+	exp, ok := c.Time(ClaimExpiration)
+	if !ok {
+		return time.Time{}, false
+	}
+	return exp.Add(-margin), true
+}
+
+// canonical returns v with all numbers in canonical form.
+// Maps are sorted by key when marshaled by encoding/json.
+func canonical(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = canonical(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = canonical(e)
+		}
+	case json.Number:
+		return canonicalNumber(v)
+	}
+	return v
+}
+
+// canonicalNumber formats integral numbers without a fraction or
+// exponent and all other numbers in their shortest float64 form.
+func canonicalNumber(n json.Number) json.Number {
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return n
+	}
+	if r.IsInt() {
+		return json.Number(r.Num().String())
+	}
+	f, _ := r.Float64()
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// Redacted is the value of redacted claims in the result of Flatten.
+const Redacted = "[REDACTED]"
+
+// Flatten returns the claims as flat key/value pairs for structured log
+// fields and span attributes. Nested objects and arrays are flattened
+// to dotted keys such as "cnf.jkt" and "aud.0", and each key is joined
+// to the prefix, if not empty, by a period. Numbers are formatted
+// without exponents so dates read as Unix timestamps.
+//
+// Claims named in redact, as dotted keys without the prefix, have their
+// value replaced by Redacted. Redacting an object or array redacts it
+// as a whole.
+func (c Claims) Flatten(prefix string, redact ...string) map[string]string {
+	m := make(map[string]string, len(c))
+	for k, v := range c {
+		flatten(m, prefix, k, v, redact)
+	}
+	return m
+}
+
+// flatten adds the value v at the dotted path to m.
+func flatten(m map[string]string, prefix, path string, v interface{}, redact []string) {
+	key := path
+	if prefix != "" {
+		key = prefix + "." + path
+	}
+	if contains(redact, path) {
+		m[key] = Redacted
+		return
+	}
+	switch v := v.(type) {
+	case nil:
+		m[key] = "null"
+	case string:
+		m[key] = v
+	case bool:
+		m[key] = strconv.FormatBool(v)
+	case float64:
+		m[key] = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		m[key] = v.String()
+	case int, int64, int32, uint, uint64, uint32:
+		m[key] = fmt.Sprint(v)
+	case []interface{}:
+		if len(v) == 0 {
+			m[key] = "[]"
+		}
+		for i, e := range v {
+			flatten(m, prefix, path+"."+strconv.Itoa(i), e, redact)
+		}
+	default:
+		o, ok := object(v)
+		if !ok {
+			// Decode other types, such as []string and structs, as
+			// they would appear in the token.
+			b, err := json.Marshal(v)
+			if err != nil {
+				m[key] = fmt.Sprint(v)
+				return
+			}
+			var decoded interface{}
+			d := json.NewDecoder(bytes.NewReader(b))
+			d.UseNumber()
+			if d.Decode(&decoded) != nil {
+				m[key] = fmt.Sprint(v)
+				return
+			}
+			flatten(m, prefix, path, decoded, redact)
+			return
+		}
+		if len(o) == 0 {
+			m[key] = "{}"
+		}
+		for k, e := range o {
+			flatten(m, prefix, path+"."+k, e, redact)
+		}
+	}
+}
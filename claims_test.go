@@ -0,0 +1,216 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClaimsHash(t *testing.T) {
+	base := Claims{
+		"sub":   "a",
+		"exp":   int64(1700000000),
+		"scope": []string{"read", "write"},
+		"ctx":   map[string]interface{}{"n": 1.0, "b": true},
+	}
+	var tests = []struct {
+		claims Claims
+		ignore []string
+		equal  bool
+	}{
+		{Claims{"ctx": map[string]interface{}{"b": true, "n": 1}, "scope": []interface{}{"read", "write"}, "exp": 1.7e9, "sub": "a"}, nil, true},
+		{Claims{"sub": "a", "exp": json.Number("1700000000.0"), "scope": []string{"read", "write"}, "ctx": Claims{"n": uint8(1), "b": true}}, nil, true},
+		{Claims{"sub": "a", "exp": int64(1700000000), "scope": []string{"read", "write"}, "ctx": map[string]interface{}{"n": 1, "b": true}, "iat": 1}, []string{"iat"}, true},
+		{Claims{"sub": "a", "exp": int64(1700000000), "scope": []string{"write", "read"}, "ctx": map[string]interface{}{"n": 1, "b": true}}, nil, false},
+		{Claims{"sub": "a", "exp": int64(1700000001), "scope": []string{"read", "write"}, "ctx": map[string]interface{}{"n": 1, "b": true}}, nil, false},
+		{Claims{"sub": "a", "exp": int64(1700000000), "scope": []string{"read", "write"}, "ctx": map[string]interface{}{"n": 1.5, "b": true}}, nil, false},
+	}
+	want, err := base.Hash("iat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		have, err := tt.claims.Hash(tt.ignore...)
+		if err != nil {
+			t.Errorf("%d. Hash err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if (have == want) != tt.equal {
+			t.Errorf("%d. Hash equal\nhave %v\nwant %v", i, have == want, tt.equal)
+		}
+	}
+}
+
+func TestClaimsDate(t *testing.T) {
+	var tests = []struct {
+		claims Claims
+		date   NumericDate
+		ok     bool
+	}{
+		{Claims{"exp": 1700000000.9}, 1700000000, true},
+		{Claims{"exp": int64(9007199254740993)}, 9007199254740993, true},
+		{Claims{"exp": 9007199254740993}, 9007199254740993, true},
+		{Claims{"exp": NumericDate(1700000000)}, 1700000000, true},
+		{Claims{"exp": json.Number("9007199254740993")}, 9007199254740993, true},
+		{Claims{"exp": json.Number("1.7e9")}, 1700000000, true},
+		{Claims{"exp": json.Number("1e19")}, 0, false},
+		{Claims{"exp": math.NaN()}, 0, false},
+		{Claims{"exp": 1e300}, 0, false},
+		{Claims{"exp": true}, 0, false},
+		{Claims{}, 0, false},
+	}
+	for i, tt := range tests {
+		date, ok := tt.claims.Date(ClaimExpiration)
+		if date != tt.date || ok != tt.ok {
+			t.Errorf("%d. Date\nhave %v %v\nwant %v %v", i, date, ok, tt.date, tt.ok)
+		}
+	}
+}
+
+func TestClaimsTime(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var tests = []struct {
+		claims  Claims
+		ttl     time.Duration
+		age     time.Duration
+		refresh time.Time
+		ok      bool
+	}{
+		{Claims{"iat": 1699999900.0, "exp": 1700000600.0}, 10 * time.Minute, 100 * time.Second, time.Unix(1700000540, 0), true},
+		{Claims{"iat": int64(1699999900), "exp": NumericDate(1700000600)}, 10 * time.Minute, 100 * time.Second, time.Unix(1700000540, 0), true},
+		{Claims{"iat": json.Number("1699999900"), "exp": json.Number("1.7e9")}, 0, 100 * time.Second, time.Unix(1699999940, 0), true},
+		{Claims{"exp": "1700000600"}, 0, 0, time.Time{}, false},
+		{Claims{}, 0, 0, time.Time{}, false},
+	}
+	for i, tt := range tests {
+		ttl, ok := tt.claims.TimeToLive(now)
+		if ok != tt.ok || ttl != tt.ttl {
+			t.Errorf("%d. TimeToLive\nhave %v %v\nwant %v %v", i, ttl, ok, tt.ttl, tt.ok)
+		}
+		age, _ := tt.claims.Age(now)
+		if age != tt.age {
+			t.Errorf("%d. Age\nhave %v\nwant %v", i, age, tt.age)
+		}
+		refresh, ok := tt.claims.RefreshAt(time.Minute)
+		if ok != tt.ok || !refresh.Equal(tt.refresh) {
+			t.Errorf("%d. RefreshAt\nhave %v %v\nwant %v %v", i, refresh, ok, tt.refresh, tt.ok)
+		}
+	}
+}
+
+func TestClaimsAudience(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		payload string
+		want    Audience
+		err     error
+	}{
+		{`{"aud":"api"}`, Audience{"api"}, nil},
+		{`{"aud":["web","api"]}`, Audience{"web", "api"}, nil},
+		{`{"aud":["web"]}`, nil, ErrClaimAudience},
+		{`{}`, nil, ErrClaimAudience},
+	}
+	for i, tt := range tests {
+		token, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), WithAudience("api"))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		have := Claims(token.Claims).Audience()
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%d. Audience\nhave %v\nwant %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestClaimsMerge(t *testing.T) {
+	base := Claims{
+		"iss":    "tenant",
+		"scope":  "read",
+		"tenant": map[string]interface{}{"id": "t1", "plan": "free"},
+	}
+	overrides := Claims{
+		"sub":    "user",
+		"scope":  "write",
+		"tenant": map[string]interface{}{"plan": "pro", "region": "eu"},
+	}
+	var tests = []struct {
+		policy MergePolicy
+		want   Claims
+		err    error
+	}{
+		{
+			MergePreferOverride,
+			Claims{"iss": "tenant", "sub": "user", "scope": "write", "tenant": map[string]interface{}{"id": "t1", "plan": "pro", "region": "eu"}},
+			nil,
+		},
+		{
+			MergePreferBase,
+			Claims{"iss": "tenant", "sub": "user", "scope": "read", "tenant": map[string]interface{}{"id": "t1", "plan": "free", "region": "eu"}},
+			nil,
+		},
+		{MergeError, nil, ErrClaimConflict},
+	}
+	for i, tt := range tests {
+		have, err := base.Merge(overrides, tt.policy)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. Merge err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%d. Merge\nhave %v\nwant %v", i, have, tt.want)
+		}
+	}
+	if base["tenant"].(map[string]interface{})["plan"] != "free" {
+		t.Errorf("should not modify base claims")
+	}
+	_, err := Claims{"scope": "read"}.Merge(Claims{"scope": "read"}, MergeError)
+	if err != nil {
+		t.Errorf("should not conflict on equal values: %v", err)
+	}
+}
+
+func TestClaimsFlatten(t *testing.T) {
+	c := Claims{
+		"sub":   "user",
+		"exp":   float64(1700000000),
+		"iat":   int64(1600000000),
+		"admin": true,
+		"aud":   []interface{}{"a", "b"},
+		"scope": []string{"read"},
+		"cnf":   map[string]interface{}{"jkt": "abc"},
+		"email": "user@example.com",
+		"ext":   map[string]interface{}{"ssn": "123", "tier": 1.5},
+		"nil":   nil,
+		"roles": []interface{}{},
+	}
+	want := map[string]string{
+		"jwt.sub":      "user",
+		"jwt.exp":      "1700000000",
+		"jwt.iat":      "1600000000",
+		"jwt.admin":    "true",
+		"jwt.aud.0":    "a",
+		"jwt.aud.1":    "b",
+		"jwt.scope.0":  "read",
+		"jwt.cnf.jkt":  "abc",
+		"jwt.email":    Redacted,
+		"jwt.ext.ssn":  Redacted,
+		"jwt.ext.tier": "1.5",
+		"jwt.nil":      "null",
+		"jwt.roles":    "[]",
+	}
+	have := c.Flatten("jwt", "email", "ext.ssn")
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Flatten\nhave %v\nwant %v", have, want)
+	}
+	have = c.Flatten("", "cnf")
+	if have["cnf"] != Redacted || have["sub"] != "user" {
+		t.Errorf("Flatten without prefix\nhave %v", have)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// registered maps registered claim names to their Go field name and type.
+var registered = map[string]field{
+	"iss": {Name: "Issuer", Type: "string"},
+	"sub": {Name: "Subject", Type: "string"},
+	"aud": {Name: "Audience", Type: "jwt.Audience"},
+	"exp": {Name: "ExpiresAt", Type: "jwt.NumericDate"},
+	"nbf": {Name: "NotBefore", Type: "jwt.NumericDate"},
+	"iat": {Name: "IssuedAt", Type: "jwt.NumericDate"},
+	"jti": {Name: "ID", Type: "string"},
+}
+
+// order is the field order of registered claims.
+var order = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// initialisms are name segments rendered in upper case.
+var initialisms = map[string]bool{
+	"api": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "jwt": true, "uri": true, "url": true, "uuid": true,
+}
+
+// field is a generated struct field.
+type field struct {
+	Name     string
+	Claim    string
+	Type     string
+	Required bool
+}
+
+// Tag returns the struct tag of the field.
+func (f field) Tag() string {
+	if f.Required {
+		return "`json:\"" + f.Claim + "\"`"
+	}
+	return "`json:\"" + f.Claim + ",omitempty\"`"
+}
+
+// fromToken infers fields from the claims of a sample token.
+// The token signature is not verified.
+func fromToken(raw string) ([]field, error) {
+	parts := strings.Split(strings.TrimSpace(raw), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwtgen: incorrect token string format")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&claims)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]field, 0, len(claims))
+	for name, v := range claims {
+		fields = append(fields, newField(name, inferType(v), false))
+	}
+	return fields, nil
+}
+
+// inferType returns the Go type of a decoded JSON value.
+func inferType(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return "float64"
+		}
+		return "int64"
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]interface{}"
+		}
+		typ := inferType(v[0])
+		for _, e := range v[1:] {
+			if inferType(e) != typ {
+				return "[]interface{}"
+			}
+		}
+		return "[]" + typ
+	case map[string]interface{}:
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// schema is the subset of JSON Schema used to describe claims.
+type schema struct {
+	Type       interface{}        `json:"type"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	Required   []string           `json:"required"`
+}
+
+// fromSchema returns the fields described by a JSON Schema object.
+func fromSchema(b []byte) ([]field, error) {
+	var s schema
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Properties) == 0 {
+		return nil, errors.New("jwtgen: schema has no properties")
+	}
+	fields := make([]field, 0, len(s.Properties))
+	for name, p := range s.Properties {
+		required := false
+		for _, r := range s.Required {
+			required = required || r == name
+		}
+		fields = append(fields, newField(name, p.goType(), required))
+	}
+	return fields, nil
+}
+
+// goType returns the Go type for the schema.
+func (s *schema) goType() string {
+	if s == nil {
+		return "interface{}"
+	}
+	typ, _ := s.Type.(string)
+	switch typ {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + s.Items.goType()
+	case "object":
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// newField returns the field for the named claim, using the
+// registered claim types where applicable.
+func newField(claim, typ string, required bool) field {
+	if f, ok := registered[claim]; ok {
+		f.Claim = claim
+		f.Required = required
+		return f
+	}
+	return field{Name: goName(claim), Claim: claim, Type: typ, Required: required}
+}
+
+// goName returns an exported Go identifier for the claim name.
+func goName(claim string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(claim, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// sortFields orders registered claims first, then by claim name.
+func sortFields(fields []field) {
+	rank := func(f field) int {
+		for i, name := range order {
+			if f.Claim == name {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		ri, rj := rank(fields[i]), rank(fields[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return fields[i].Claim < fields[j].Claim
+	})
+}
+
+var tmpl = template.Must(template.New("claims").Parse(`// Code generated by jwtgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// {{.Type}} is the typed claim set of a token.
+type {{.Type}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+
+// Parse{{.Type}} decodes and validates the claims of a verified token.
+func Parse{{.Type}}(t *jwt.Token) (*{{.Type}}, error) {
+	b, err := json.Marshal(t.Claims)
+	if err != nil {
+		return nil, err
+	}
+	var c {{.Type}}
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Validate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the claims against now.
+func (c *{{.Type}}) Validate(now time.Time) error {
+{{- range .Fields}}
+{{- if eq .Claim "exp"}}
+	if c.ExpiresAt != 0 && now.After(c.ExpiresAt.Time()) {
+		return jwt.ErrClaimExpired
+	}
+{{- else if eq .Claim "nbf"}}
+	if c.NotBefore != 0 && now.Before(c.NotBefore.Time()) {
+		return jwt.ErrClaimNotBefore
+	}
+{{- end}}
+{{- end}}
+{{- range .Fields}}
+{{- if and .Required (eq .Type "string")}}
+	if c.{{.Name}} == "" {
+		return jwt.ErrClaimRequired
+	}
+{{- else if and .Required (eq .Type "jwt.NumericDate")}}
+	if c.{{.Name}} == 0 {
+		return jwt.ErrClaimRequired
+	}
+{{- end}}
+{{- end}}
+	return nil
+}
+`))
+
+// generate returns the formatted Go source for the claims struct.
+func generate(pkg, typ string, fields []field) ([]byte, error) {
+	sortFields(fields)
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Package string
+		Type    string
+		Fields  []field
+	}{pkg, typ, fields})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
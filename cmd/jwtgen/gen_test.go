@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateFromToken(t *testing.T) {
+	// {"aud":["a","b"],"client_id":"x","exp":1700000000,"iss":"i","ratio":0.5,"roles":["admin"]}
+	token := "eyJhbGciOiJub25lIn0.eyJhdWQiOlsiYSIsImIiXSwiY2xpZW50X2lkIjoieCIsImV4cCI6MTcwMDAwMDAwMCwiaXNzIjoiaSIsInJhdGlvIjowLjUsInJvbGVzIjpbImFkbWluIl19."
+	fields, err := fromToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate("claims", "Claims", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package claims",
+		"Issuer    string          `json:\"iss,omitempty\"`",
+		"Audience  jwt.Audience    `json:\"aud,omitempty\"`",
+		"ExpiresAt jwt.NumericDate `json:\"exp,omitempty\"`",
+		"ClientID  string          `json:\"client_id,omitempty\"`",
+		"Ratio     float64         `json:\"ratio,omitempty\"`",
+		"Roles     []string        `json:\"roles,omitempty\"`",
+		"return jwt.ErrClaimExpired",
+		"func ParseClaims(t *jwt.Token) (*Claims, error)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source missing %q\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateFromSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"sub": {"type": "string"},
+			"nbf": {"type": "integer"},
+			"tenant_id": {"type": "string"},
+			"scopes": {"type": "array", "items": {"type": "string"}},
+			"count": {"type": "integer"}
+		},
+		"required": ["sub", "tenant_id"]
+	}`
+	fields, err := fromSchema([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate("main", "Token", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Subject   string          `json:\"sub\"`",
+		"NotBefore jwt.NumericDate `json:\"nbf,omitempty\"`",
+		"TenantID  string          `json:\"tenant_id\"`",
+		"Scopes    []string        `json:\"scopes,omitempty\"`",
+		"Count     int64           `json:\"count,omitempty\"`",
+		"if c.TenantID == \"\" {",
+		"return jwt.ErrClaimNotBefore",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated source missing %q\n%s", want, src)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{"client_id", "ClientID"},
+		{"https://example.com/roles", "HTTPSExampleComRoles"},
+		{"2fa", "X2fa"},
+		{"scope", "Scope"},
+	}
+	for i, tt := range tests {
+		have := goName(tt.in)
+		if have != tt.out {
+			t.Errorf("%d. goName(%q)\nhave %v\nwant %v", i, tt.in, have, tt.out)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Command jwtgen generates a typed Go claims struct from a sample
+// token or a JSON Schema describing the claims.
+//
+// Usage:
+//
+//	jwtgen [-schema] [-package name] [-type name] [-o file] [input]
+//
+// The input is read from the named file, or standard input if omitted.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	var (
+		isSchema = flag.Bool("schema", false, "input is a JSON Schema instead of a sample token")
+		pkg      = flag.String("package", "main", "package name of the generated file")
+		typ      = flag.String("type", "Claims", "type name of the generated struct")
+		out      = flag.String("o", "", "output file (default standard output)")
+	)
+	flag.Parse()
+	err := run(*isSchema, *pkg, *typ, *out, flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(isSchema bool, pkg, typ, out, in string) error {
+	r := io.Reader(os.Stdin)
+	if in != "" {
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var fields []field
+	if isSchema {
+		fields, err = fromSchema(b)
+	} else {
+		fields, err = fromToken(string(b))
+	}
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkg, typ, fields)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0644)
+}
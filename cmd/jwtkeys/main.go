@@ -0,0 +1,105 @@
+// Command jwtkeys reports on the keys of a JSON Web Key Set.
+//
+// Usage:
+//
+//	jwtkeys status [-within duration] [-deadline kid=time ...] [input]
+//
+// The status subcommand lists keys whose rotation deadline or x5c
+// certificate expires within the duration, 30 days by default, and
+// exits with status 2 if there are any. Deadlines are RFC 3339 times.
+// The key set is read from the named file, or standard input if omitted.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// errExpiring is returned when keys need attention.
+var errExpiring = errors.New("jwtkeys: keys are expiring")
+
+// deadlines is a flag.Value mapping kid values to rotation deadlines.
+type deadlines map[string]time.Time
+
+func (d deadlines) String() string {
+	return fmt.Sprint(map[string]time.Time(d))
+}
+
+func (d deadlines) Set(s string) error {
+	kid, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("want kid=time, have %q", s)
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return err
+	}
+	d[kid] = t
+	return nil
+}
+
+func main() {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	within := fs.Duration("within", 30*24*time.Hour, "report keys expiring within this duration")
+	d := make(deadlines)
+	fs.Var(d, "deadline", "rotation deadline of a key as kid=time (repeatable)")
+	if len(os.Args) < 2 || os.Args[1] != "status" {
+		fmt.Fprintln(os.Stderr, "usage: jwtkeys status [-within duration] [-deadline kid=time ...] [input]")
+		os.Exit(1)
+	}
+	fs.Parse(os.Args[2:])
+	err := run(os.Stdout, fs.Arg(0), *within, d, time.Now())
+	if err == errExpiring {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, in string, within time.Duration, d deadlines, now time.Time) error {
+	r := io.Reader(os.Stdin)
+	if in != "" {
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := jwt.ReadKey(r)
+	if err != nil {
+		return err
+	}
+	s, err := jwt.ParseKeySet(b)
+	if err != nil {
+		return err
+	}
+	advisories, err := s.Expiring(now, within, d)
+	if err != nil {
+		return err
+	}
+	if len(advisories) == 0 {
+		fmt.Fprintf(w, "%d keys, none expiring within %s\n", len(s.Keys), within)
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KID\tREASON\tDEADLINE\tREMAINING")
+	for _, a := range advisories {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Kid, a.Reason, a.Deadline.Format(time.RFC3339), a.Deadline.Sub(now).Round(time.Hour))
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	return errExpiring
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "jwks.json")
+	jwks := `{"keys":[{"kty":"oct","kid":"a","k":"c2VjcmV0"},{"kty":"oct","kid":"b","k":"c2VjcmV0"}]}`
+	err := os.WriteFile(name, []byte(jwks), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		deadline string
+		err      error
+		out      string
+	}{
+		{"a=2025-03-01T00:00:00Z", nil, "2 keys, none expiring"},
+		{"a=2025-01-15T00:00:00Z", errExpiring, "a    rotation  2025-01-15T00:00:00Z  336h0m0s"},
+	}
+	for i, tt := range tests {
+		d := make(deadlines)
+		err := d.Set(tt.deadline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		err = run(&buf, name, 30*24*time.Hour, d, now)
+		if err != tt.err {
+			t.Errorf("%d. run err\nhave %v\nwant %v", i, err, tt.err)
+		}
+		if !strings.Contains(buf.String(), tt.out) {
+			t.Errorf("%d. run output\nhave %q\nwant %q", i, buf.String(), tt.out)
+		}
+	}
+}
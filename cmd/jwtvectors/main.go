@@ -0,0 +1,34 @@
+// Command jwtvectors writes a directory of test vectors covering every
+// supported algorithm and a set of edge cases, for verifying other
+// implementations against this package.
+//
+// Usage:
+//
+//	jwtvectors [-o dir]
+//
+// The directory contains the keys of each algorithm and a vectors.json
+// manifest listing each token, the file holding its verification key
+// and whether verification is expected to succeed. HMAC keys are raw
+// bytes; all other keys are PEM-encoded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	out := flag.String("o", "vectors", "output directory")
+	flag.Parse()
+	err := run(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string) error {
+	_, err := generate(dir, signers())
+	return err
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pnelson/jwt"
+)
+
+// Date claims are fixed so vectors remain stable once generated.
+const (
+	past   = 946684800  // 2000-01-01
+	future = 4102444800 // 2100-01-01
+)
+
+// vector is a generated test vector.
+type vector struct {
+	Name   string `json:"name"`
+	Alg    string `json:"alg"`
+	Token  string `json:"token"`
+	Key    string `json:"key"`
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// edgeCase describes a token generated for each algorithm.
+type edgeCase struct {
+	name   string
+	claims map[string]interface{}
+	mutate func(token string) (string, error)
+	reason string
+}
+
+var cases = []edgeCase{
+	{name: "basic", claims: map[string]interface{}{"sub": "1234567890", "exp": future}},
+	{name: "empty_claims", claims: map[string]interface{}{}},
+	{name: "long_claims", claims: map[string]interface{}{"sub": "long", "data": strings.Repeat("0123456789abcdef", 1024)}},
+	{name: "unicode", claims: map[string]interface{}{"sub": "ünïcödé", "name": "日本語 😀", "escape": "\"\\\u0000 "}},
+	{name: "nested", claims: map[string]interface{}{"sub": "nested", "ctx": map[string]interface{}{"roles": []interface{}{"a", "b"}, "n": 1.5}}},
+	{name: "nbf_future", claims: map[string]interface{}{"sub": "nbf", "nbf": future}, reason: "nbf is in the future"},
+	{name: "expired", claims: map[string]interface{}{"sub": "exp", "exp": past}, reason: "exp is in the past"},
+	{name: "tampered_signature", claims: map[string]interface{}{"sub": "tampered"}, mutate: tamper, reason: "signature does not match"},
+	{name: "alg_none", claims: map[string]interface{}{"sub": "none"}, mutate: unsecure, reason: "alg none is not accepted"},
+}
+
+// signers returns the built-in signers sorted by name.
+func signers() []jwt.Signer {
+	s := jwt.DefaultRegistry.Signers()
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].String() < s[j].String()
+	})
+	return s
+}
+
+// generate writes the keys and manifest of the vectors for each signer
+// to dir and returns the vectors.
+func generate(dir string, s []jwt.Signer) ([]vector, error) {
+	err := os.MkdirAll(filepath.Join(dir, "keys"), 0755)
+	if err != nil {
+		return nil, err
+	}
+	var vectors []vector
+	for _, signer := range s {
+		alg := signer.String()
+		signKey, verifyKey, err := generateKey(signer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", alg, err)
+		}
+		signFile, verifyFile := keyFiles(signer)
+		err = os.WriteFile(filepath.Join(dir, signFile), signKey, 0600)
+		if err != nil {
+			return nil, err
+		}
+		if verifyFile != signFile {
+			err = os.WriteFile(filepath.Join(dir, verifyFile), verifyKey, 0644)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, c := range cases {
+			t := jwt.New(signer)
+			for k, v := range c.claims {
+				t.Claims[k] = v
+			}
+			token, err := t.Sign(signKey)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", alg, c.name, err)
+			}
+			if c.mutate != nil {
+				token, err = c.mutate(token)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", alg, c.name, err)
+				}
+			}
+			vectors = append(vectors, vector{
+				Name:   c.name,
+				Alg:    alg,
+				Token:  token,
+				Key:    verifyFile,
+				Valid:  c.reason == "",
+				Reason: c.reason,
+			})
+		}
+	}
+	b, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(filepath.Join(dir, "vectors.json"), append(b, '\n'), 0644)
+	if err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// keyFiles returns the signing and verification key file names.
+func keyFiles(s jwt.Signer) (string, string) {
+	alg := s.String()
+	if _, ok := s.(jwt.HMACSigner); ok {
+		name := filepath.Join("keys", alg+".key")
+		return name, name
+	}
+	return filepath.Join("keys", alg+".pem"), filepath.Join("keys", alg+".pub.pem")
+}
+
+// generateKey returns a new signing and verification key for s.
+func generateKey(s jwt.Signer) ([]byte, []byte, error) {
+	var (
+		pub interface{}
+		der []byte
+		typ string
+		err error
+	)
+	switch s.(type) {
+	case jwt.HMACSigner:
+		key := make([]byte, 64)
+		_, err = rand.Read(key)
+		return key, key, err
+	case jwt.RSASigner, jwt.RSAPSSSigner:
+		k, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, der, typ = &k.PublicKey, x509.MarshalPKCS1PrivateKey(k), "RSA PRIVATE KEY"
+	case jwt.ECDSASigner:
+		c, ok := curves[s.String()]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported curve")
+		}
+		k, err := ecdsa.GenerateKey(c, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err = x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, typ = &k.PublicKey, "EC PRIVATE KEY"
+	case jwt.EdDSASigner:
+		p, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err = x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, typ = p, "PRIVATE KEY"
+	default:
+		return nil, nil, fmt.Errorf("unsupported signer %T", s)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	signKey := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	verifyKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return signKey, verifyKey, nil
+}
+
+// curves maps ECDSA algorithms to their curves.
+var curves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// tamper returns token with the first signature byte flipped.
+func tamper(token string) (string, error) {
+	h, p, sig, err := jwt.SplitCompact(token)
+	if err != nil {
+		return "", err
+	}
+	sig[0] ^= 0xFF
+	return jwt.JoinCompact(h, p, sig), nil
+}
+
+// unsecure returns token with the alg header replaced with "none" and
+// the signature removed.
+func unsecure(token string) (string, error) {
+	_, p, _, err := jwt.SplitCompact(token)
+	if err != nil {
+		return "", err
+	}
+	return jwt.JoinCompact([]byte(`{"alg":"none","typ":"JWT"}`), p, nil), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	s := signers()
+	vectors, err := generate(dir, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != len(s)*len(cases) {
+		t.Fatalf("vectors\nhave %d\nwant %d", len(vectors), len(s)*len(cases))
+	}
+	_, err = os.Stat(filepath.Join(dir, "vectors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vectors {
+		signer, ok := jwt.DefaultRegistry.Lookup(v.Alg)
+		if !ok {
+			t.Fatalf("%d. unknown algorithm %s", i, v.Alg)
+		}
+		key, err := os.ReadFile(filepath.Join(dir, v.Key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = jwt.Parse(signer, v.Token, key)
+		if (err == nil) != v.Valid {
+			t.Errorf("%d. %s %s valid\nhave %v (%v)\nwant %v", i, v.Alg, v.Name, err == nil, err, v.Valid)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package jwt
+
+import "strings"
+
+// JoinCompact returns the compact serialization of the raw header,
+// payload and signature. Each segment is encoded as padding-free
+// URL-safe base64 and joined by periods.
+//
+// See RFC 7515 Section 7.1.
+func JoinCompact(header, payload, sig []byte) string {
+	return encode(header) + sep + encode(payload) + sep + encode(sig)
+}
+
+// SplitCompact returns the decoded header, payload and signature of
+// the compact serialization jwt. The token is not verified.
+func SplitCompact(jwt string) (header, payload, sig []byte, err error) {
+	parts := strings.Split(jwt, sep)
+	if len(parts) != 3 {
+		return nil, nil, nil, ErrMalformed
+	}
+	header, err = decode(parts[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	payload, err = decode(parts[1])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sig, err = decode(parts[2])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return header, payload, sig, nil
+}
@@ -0,0 +1,37 @@
+package jwt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	var tests = []struct {
+		header  []byte
+		payload []byte
+		sig     []byte
+		jwt     string
+	}{
+		{[]byte(`{"alg":"none"}`), []byte(`{"foo":"bar"}`), nil, "eyJhbGciOiJub25lIn0.eyJmb28iOiJiYXIifQ."},
+		{[]byte(`{"alg":"none"}`), []byte{0xfb, 0xff}, []byte{0xff, 0xfe}, "eyJhbGciOiJub25lIn0.-_8.__4"},
+	}
+	for i, tt := range tests {
+		jwt := JoinCompact(tt.header, tt.payload, tt.sig)
+		if jwt != tt.jwt {
+			t.Errorf("%d. JoinCompact\nhave %v\nwant %v", i, jwt, tt.jwt)
+			continue
+		}
+		h, p, s, err := SplitCompact(jwt)
+		if err != nil {
+			t.Errorf("%d. SplitCompact err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if !bytes.Equal(h, tt.header) || !bytes.Equal(p, tt.payload) || !bytes.Equal(s, tt.sig) {
+			t.Errorf("%d. SplitCompact\nhave %s %s %x\nwant %s %s %x", i, h, p, s, tt.header, tt.payload, tt.sig)
+		}
+	}
+	_, _, _, err := SplitCompact("a.b.c.d")
+	if err != ErrMalformed {
+		t.Errorf("should return malformed error")
+	}
+}
@@ -0,0 +1,169 @@
+package compat
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// Claims is the interface implemented by claim sets.
+type Claims interface {
+	GetExpirationTime() (*NumericDate, error)
+	GetIssuedAt() (*NumericDate, error)
+	GetNotBefore() (*NumericDate, error)
+	GetIssuer() (string, error)
+	GetSubject() (string, error)
+	GetAudience() (ClaimStrings, error)
+}
+
+// ClaimsValidator is the interface implemented by claim sets with
+// application-specific validation, called after the registered claims
+// are validated.
+type ClaimsValidator interface {
+	Claims
+	Validate() error
+}
+
+// ClaimStrings is a claim that may be a single string or an array.
+type ClaimStrings = jwt.Audience
+
+// NumericDate is a claim holding seconds since the Unix epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns the NumericDate for t truncated to seconds.
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t.Truncate(time.Second)}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(d.Unix(), 10)), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var n jwt.NumericDate
+	err := n.UnmarshalJSON(b)
+	if err != nil {
+		return err
+	}
+	d.Time = n.Time()
+	return nil
+}
+
+// RegisteredClaims is the set of registered claims. It is intended to
+// be embedded in application claim types.
+//
+// See RFC 7519 Section 4.1.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  ClaimStrings `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// GetExpirationTime implements the Claims interface.
+func (c RegisteredClaims) GetExpirationTime() (*NumericDate, error) {
+	return c.ExpiresAt, nil
+}
+
+// GetIssuedAt implements the Claims interface.
+func (c RegisteredClaims) GetIssuedAt() (*NumericDate, error) {
+	return c.IssuedAt, nil
+}
+
+// GetNotBefore implements the Claims interface.
+func (c RegisteredClaims) GetNotBefore() (*NumericDate, error) {
+	return c.NotBefore, nil
+}
+
+// GetIssuer implements the Claims interface.
+func (c RegisteredClaims) GetIssuer() (string, error) {
+	return c.Issuer, nil
+}
+
+// GetSubject implements the Claims interface.
+func (c RegisteredClaims) GetSubject() (string, error) {
+	return c.Subject, nil
+}
+
+// GetAudience implements the Claims interface.
+func (c RegisteredClaims) GetAudience() (ClaimStrings, error) {
+	return c.Audience, nil
+}
+
+// MapClaims is a claim set decoded into a map.
+type MapClaims map[string]interface{}
+
+// GetExpirationTime implements the Claims interface.
+func (m MapClaims) GetExpirationTime() (*NumericDate, error) {
+	return m.date("exp")
+}
+
+// GetIssuedAt implements the Claims interface.
+func (m MapClaims) GetIssuedAt() (*NumericDate, error) {
+	return m.date("iat")
+}
+
+// GetNotBefore implements the Claims interface.
+func (m MapClaims) GetNotBefore() (*NumericDate, error) {
+	return m.date("nbf")
+}
+
+// GetIssuer implements the Claims interface.
+func (m MapClaims) GetIssuer() (string, error) {
+	return m.string("iss")
+}
+
+// GetSubject implements the Claims interface.
+func (m MapClaims) GetSubject() (string, error) {
+	return m.string("sub")
+}
+
+// GetAudience implements the Claims interface.
+func (m MapClaims) GetAudience() (ClaimStrings, error) {
+	v, ok := m["aud"]
+	if !ok {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var aud ClaimStrings
+	err = json.Unmarshal(b, &aud)
+	if err != nil {
+		return nil, ErrTokenInvalidClaims
+	}
+	return aud, nil
+}
+
+func (m MapClaims) date(name string) (*NumericDate, error) {
+	if _, ok := m[name]; !ok {
+		return nil, nil
+	}
+	t, ok := jwt.Claims(m).Time(name)
+	if !ok {
+		return nil, ErrTokenInvalidClaims
+	}
+	return &NumericDate{t}, nil
+}
+
+func (m MapClaims) string(name string) (string, error) {
+	v, ok := m[name]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", ErrTokenInvalidClaims
+	}
+	return s, nil
+}
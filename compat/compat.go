@@ -0,0 +1,240 @@
+// Package compat exposes the Claims, Keyfunc and parsing patterns of
+// the widely used golang-jwt/jwt v5 API backed by package jwt, so call
+// sites can be migrated incrementally by changing an import.
+//
+//	import jwt "github.com/pnelson/jwt/compat"
+//
+//	token, err := jwt.ParseWithClaims(raw, &MyClaims{}, func(t *jwt.Token) (interface{}, error) {
+//		return publicKey, nil
+//	}, jwt.WithValidMethods([]string{"RS256"}))
+//
+// Keys may be []byte, as accepted by package jwt, or the native key
+// types accepted by golang-jwt, such as *rsa.PublicKey.
+package compat
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// Errors matching the golang-jwt sentinel errors. They are the errors
+// of package jwt, so errors.Is works with either.
+var (
+	ErrTokenMalformed             = jwt.ErrMalformed
+	ErrTokenSignatureInvalid      = jwt.ErrInvalidSignature
+	ErrTokenUnverifiable          = jwt.ErrHeaderAlg
+	ErrTokenExpired               = jwt.ErrClaimExpired
+	ErrTokenNotValidYet           = jwt.ErrClaimNotBefore
+	ErrTokenInvalidIssuer         = jwt.ErrClaimIssuer
+	ErrTokenInvalidAudience       = jwt.ErrClaimAudience
+	ErrTokenRequiredClaimMissing  = jwt.ErrClaimRequired
+	ErrInvalidKeyType             = errors.New("compat: key is of invalid type")
+	ErrTokenInvalidClaims         = errors.New("compat: token has invalid claims")
+	ErrTokenSignatureMethodAbsent = errors.New("compat: signing method is not set")
+)
+
+// Keyfunc returns the key used to verify the token. The token header
+// and method are populated; the claims are not yet verified.
+type Keyfunc func(*Token) (interface{}, error)
+
+// Token is a parsed or new token.
+type Token struct {
+	Raw       string
+	Method    SigningMethod
+	Header    map[string]interface{}
+	Claims    Claims
+	Signature []byte
+	Valid     bool
+}
+
+// New returns a new token with empty map claims.
+func New(method SigningMethod) *Token {
+	return NewWithClaims(method, MapClaims{})
+}
+
+// NewWithClaims returns a new token with the claims.
+func NewWithClaims(method SigningMethod, claims Claims) *Token {
+	return &Token{
+		Method: method,
+		Header: map[string]interface{}{"typ": "JWT", "alg": method.Alg()},
+		Claims: claims,
+	}
+}
+
+// SigningString returns the encoded header and claims joined by a period.
+func (t *Token) SigningString() (string, error) {
+	h, err := json.Marshal(t.Header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(t.Claims)
+	if err != nil {
+		return "", err
+	}
+	return encode(h) + "." + encode(c), nil
+}
+
+// SignedString returns the signed token.
+func (t *Token) SignedString(key interface{}) (string, error) {
+	if t.Method == nil {
+		return "", ErrTokenSignatureMethodAbsent
+	}
+	s, err := t.SigningString()
+	if err != nil {
+		return "", err
+	}
+	sig, err := t.Method.Sign(s, key)
+	if err != nil {
+		return "", err
+	}
+	return s + "." + encode(sig), nil
+}
+
+// ParserOption configures parsing.
+type ParserOption func(*parser)
+
+type parser struct {
+	methods []string
+	opts    []jwt.Option
+}
+
+// WithValidMethods returns an option that accepts only the algorithms
+// named by methods. By default all registered algorithms are accepted.
+func WithValidMethods(methods []string) ParserOption {
+	return func(p *parser) {
+		p.methods = methods
+	}
+}
+
+// WithIssuer returns an option that requires the iss claim.
+func WithIssuer(iss string) ParserOption {
+	return func(p *parser) {
+		p.opts = append(p.opts, jwt.WithIssuer(iss))
+	}
+}
+
+// WithAudience returns an option that requires the aud claim.
+func WithAudience(aud string) ParserOption {
+	return func(p *parser) {
+		p.opts = append(p.opts, jwt.WithAudience(aud))
+	}
+}
+
+// WithLeeway returns an option that allows clock skew.
+func WithLeeway(d time.Duration) ParserOption {
+	return func(p *parser) {
+		p.opts = append(p.opts, jwt.WithLeeway(d))
+	}
+}
+
+// WithExpirationRequired returns an option that requires the exp claim.
+func WithExpirationRequired() ParserOption {
+	return func(p *parser) {
+		p.opts = append(p.opts, jwt.WithExpirationRequired())
+	}
+}
+
+// Parse parses, verifies and validates the token into MapClaims.
+func Parse(tokenString string, keyFunc Keyfunc, options ...ParserOption) (*Token, error) {
+	return ParseWithClaims(tokenString, MapClaims{}, keyFunc, options...)
+}
+
+// ParseWithClaims parses, verifies and validates the token, decoding
+// the claims into claims, which must be a MapClaims or a pointer. The
+// claims are decoded before the Keyfunc is called, so it may inspect
+// them, but are not trusted until verification succeeds. The token is
+// returned even if verification fails.
+func ParseWithClaims(tokenString string, claims Claims, keyFunc Keyfunc, options ...ParserOption) (*Token, error) {
+	var p parser
+	for _, opt := range options {
+		opt(&p)
+	}
+	token := &Token{Raw: tokenString, Claims: claims}
+	h, payload, sig, err := jwt.SplitCompact(tokenString)
+	if err != nil {
+		return token, ErrTokenMalformed
+	}
+	err = json.Unmarshal(h, &token.Header)
+	if err != nil {
+		return token, ErrTokenMalformed
+	}
+	alg, _ := token.Header[jwt.HeaderAlgorithm].(string)
+	token.Method = GetSigningMethod(alg)
+	token.Signature = sig
+	// The unverified claims are available to the Keyfunc, such as to
+	// select a key by the iss claim.
+	if m, ok := claims.(MapClaims); ok {
+		err = json.Unmarshal(payload, &m)
+	} else {
+		err = json.Unmarshal(payload, claims)
+	}
+	if err != nil {
+		return token, ErrTokenMalformed
+	}
+	var signers []jwt.Signer
+	if p.methods == nil {
+		signers = jwt.DefaultRegistry.Signers()
+	} else {
+		for _, name := range p.methods {
+			s, ok := jwt.LookupSigner(name)
+			if ok {
+				signers = append(signers, s)
+			}
+		}
+	}
+	v := jwt.NewVerifier(signers, keyfuncProvider{token, keyFunc}, p.opts...)
+	t, err := v.Verify(context.Background(), tokenString)
+	if err != nil {
+		return token, err
+	}
+	if m, ok := claims.(MapClaims); ok {
+		for k, v := range t.Claims {
+			m[k] = v
+		}
+	}
+	if c, ok := claims.(ClaimsValidator); ok {
+		err = c.Validate()
+		if err != nil {
+			return token, errors.Join(ErrTokenInvalidClaims, err)
+		}
+	}
+	token.Valid = true
+	return token, nil
+}
+
+// encode returns the padding-free URL-safe base64 encoding of b.
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// keyfuncProvider adapts a Keyfunc to the jwt.NativeKeyProvider interface.
+type keyfuncProvider struct {
+	token   *Token
+	keyFunc Keyfunc
+}
+
+// Key implements the jwt.KeyProvider interface.
+func (p keyfuncProvider) Key(ctx context.Context, t *jwt.Token) ([]byte, error) {
+	key, err := p.NativeKey(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return keyBytes(key)
+}
+
+// NativeKey implements the jwt.NativeKeyProvider interface.
+func (p keyfuncProvider) NativeKey(ctx context.Context, t *jwt.Token) (interface{}, error) {
+	key, err := p.keyFunc(p.token)
+	if err != nil {
+		return nil, err
+	}
+	if !validKey(key) {
+		return nil, ErrInvalidKeyType
+	}
+	return key, nil
+}
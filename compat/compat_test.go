@@ -0,0 +1,147 @@
+package compat
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+)
+
+type customClaims struct {
+	Role string `json:"role"`
+	RegisteredClaims
+}
+
+func (c *customClaims) Validate() error {
+	if c.Role == "" {
+		return errors.New("role is required")
+	}
+	return nil
+}
+
+func TestParseWithClaims(t *testing.T) {
+	key := []byte("secret")
+	keyFunc := func(t *Token) (interface{}, error) {
+		return key, nil
+	}
+	now := time.Now()
+	var tests = []struct {
+		claims customClaims
+		opts   []ParserOption
+		err    error
+	}{
+		{customClaims{"admin", RegisteredClaims{Subject: "user", ExpiresAt: NewNumericDate(now.Add(time.Hour))}}, nil, nil},
+		{customClaims{"admin", RegisteredClaims{Issuer: "a", Audience: ClaimStrings{"b"}}}, []ParserOption{WithIssuer("a"), WithAudience("b")}, nil},
+		{customClaims{"admin", RegisteredClaims{ExpiresAt: NewNumericDate(now.Add(-time.Hour))}}, nil, ErrTokenExpired},
+		{customClaims{"admin", RegisteredClaims{NotBefore: NewNumericDate(now.Add(time.Hour))}}, nil, ErrTokenNotValidYet},
+		{customClaims{"admin", RegisteredClaims{}}, []ParserOption{WithExpirationRequired()}, ErrTokenRequiredClaimMissing},
+		{customClaims{"admin", RegisteredClaims{Issuer: "c"}}, []ParserOption{WithIssuer("a")}, ErrTokenInvalidIssuer},
+		{customClaims{"admin", RegisteredClaims{}}, []ParserOption{WithValidMethods([]string{"HS512"})}, ErrTokenUnverifiable},
+		{customClaims{"", RegisteredClaims{}}, nil, ErrTokenInvalidClaims},
+	}
+	for i, tt := range tests {
+		raw, err := NewWithClaims(SigningMethodHS256, &tt.claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var claims customClaims
+		token, err := ParseWithClaims(raw, &claims, keyFunc, tt.opts...)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. ParseWithClaims err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if token.Valid != (tt.err == nil) {
+			t.Errorf("%d. Valid\nhave %v\nwant %v", i, token.Valid, tt.err == nil)
+		}
+		if tt.err == nil && (claims.Role != tt.claims.Role || claims.Subject != tt.claims.Subject) {
+			t.Errorf("%d. claims\nhave %+v\nwant %+v", i, claims, tt.claims)
+		}
+	}
+}
+
+func TestParseNativeKeys(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := NewWithClaims(SigningMethodRS256, MapClaims{"sub": "user"}).SignedString(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := Parse(raw, func(t *Token) (interface{}, error) {
+		if t.Method.Alg() != "RS256" || t.Header["alg"] != "RS256" {
+			return nil, errors.New("unexpected signing method")
+		}
+		return &priv.PublicKey, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := token.Claims.GetSubject()
+	if err != nil || sub != "user" {
+		t.Errorf("GetSubject\nhave %q %v\nwant %q %v", sub, err, "user", nil)
+	}
+	_, err = Parse(raw, func(t *Token) (interface{}, error) {
+		return "not a key", nil
+	})
+	if err != ErrInvalidKeyType {
+		t.Errorf("Parse err\nhave %v\nwant %v", err, ErrInvalidKeyType)
+	}
+}
+
+func TestMapClaims(t *testing.T) {
+	m := MapClaims{"exp": 1700000000.0, "aud": []interface{}{"a", "b"}, "iss": 1.0}
+	exp, err := m.GetExpirationTime()
+	if err != nil || exp.Unix() != 1700000000 {
+		t.Errorf("GetExpirationTime\nhave %v %v\nwant %v %v", exp, err, 1700000000, nil)
+	}
+	aud, err := m.GetAudience()
+	if err != nil || !aud.Contains("b") {
+		t.Errorf("GetAudience\nhave %v %v\nwant %v %v", aud, err, []string{"a", "b"}, nil)
+	}
+	_, err = m.GetIssuer()
+	if err != ErrTokenInvalidClaims {
+		t.Errorf("GetIssuer err\nhave %v\nwant %v", err, ErrTokenInvalidClaims)
+	}
+	nbf, err := m.GetNotBefore()
+	if nbf != nil || err != nil {
+		t.Errorf("GetNotBefore\nhave %v %v\nwant %v %v", nbf, err, nil, nil)
+	}
+}
+
+var (
+	_ Claims          = MapClaims{}
+	_ Claims          = RegisteredClaims{}
+	_ ClaimsValidator = (*customClaims)(nil)
+)
+
+func TestParseWithClaimsKeyfuncClaims(t *testing.T) {
+	keys := map[string][]byte{"a": []byte("secret-a"), "b": []byte("secret-b")}
+	keyFunc := func(t *Token) (interface{}, error) {
+		iss, err := t.Claims.GetIssuer()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := keys[iss]
+		if !ok {
+			return nil, errors.New("unknown issuer")
+		}
+		return key, nil
+	}
+	for iss, key := range keys {
+		raw, err := NewWithClaims(SigningMethodHS256, &RegisteredClaims{Issuer: iss}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var claims RegisteredClaims
+		token, err := ParseWithClaims(raw, &claims, keyFunc)
+		if err != nil || !token.Valid || claims.Issuer != iss {
+			t.Errorf("%s. ParseWithClaims err\nhave %v\nwant %v", iss, err, nil)
+		}
+		token, err = Parse(raw, keyFunc)
+		if err != nil || !token.Valid {
+			t.Errorf("%s. Parse err\nhave %v\nwant %v", iss, err, nil)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package compat
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/pnelson/jwt"
+)
+
+// SigningMethod is the interface that signs and verifies signing strings.
+type SigningMethod interface {
+	Verify(signingString string, sig []byte, key interface{}) error
+	Sign(signingString string, key interface{}) ([]byte, error)
+	Alg() string
+}
+
+// Signing methods backed by the signers of package jwt.
+var (
+	SigningMethodHS256 SigningMethod = signingMethod{jwt.HS256}
+	SigningMethodHS384 SigningMethod = signingMethod{jwt.HS384}
+	SigningMethodHS512 SigningMethod = signingMethod{jwt.HS512}
+	SigningMethodRS256 SigningMethod = signingMethod{jwt.RS256}
+	SigningMethodRS384 SigningMethod = signingMethod{jwt.RS384}
+	SigningMethodRS512 SigningMethod = signingMethod{jwt.RS512}
+	SigningMethodPS256 SigningMethod = signingMethod{jwt.PS256}
+	SigningMethodPS384 SigningMethod = signingMethod{jwt.PS384}
+	SigningMethodPS512 SigningMethod = signingMethod{jwt.PS512}
+	SigningMethodES256 SigningMethod = signingMethod{jwt.ES256}
+	SigningMethodES384 SigningMethod = signingMethod{jwt.ES384}
+	SigningMethodES512 SigningMethod = signingMethod{jwt.ES512}
+	SigningMethodEdDSA SigningMethod = signingMethod{jwt.EdDSA}
+)
+
+// GetSigningMethod returns the signing method for the algorithm name
+// registered with package jwt, or nil if there is none.
+func GetSigningMethod(alg string) SigningMethod {
+	s, ok := jwt.LookupSigner(alg)
+	if !ok {
+		return nil
+	}
+	return signingMethod{s}
+}
+
+// signingMethod adapts a jwt.Signer to the SigningMethod interface.
+type signingMethod struct {
+	jwt.Signer
+}
+
+// Verify implements the SigningMethod interface.
+func (m signingMethod) Verify(signingString string, sig []byte, key interface{}) error {
+	if !validKey(key) {
+		return ErrInvalidKeyType
+	}
+	if s, ok := m.Signer.(jwt.KeySigner); ok {
+		return s.VerifyKey([]byte(signingString), sig, key)
+	}
+	b, err := keyBytes(key)
+	if err != nil {
+		return err
+	}
+	return m.Signer.Verify([]byte(signingString), sig, b)
+}
+
+// Sign implements the SigningMethod interface.
+func (m signingMethod) Sign(signingString string, key interface{}) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKeyType
+	}
+	if s, ok := m.Signer.(jwt.KeySigner); ok {
+		return s.SignKey([]byte(signingString), key)
+	}
+	b, err := keyBytes(key)
+	if err != nil {
+		return nil, err
+	}
+	return m.Signer.Sign([]byte(signingString), b)
+}
+
+// Alg implements the SigningMethod interface.
+func (m signingMethod) Alg() string {
+	return m.String()
+}
+
+// validKey returns true if key is of a type accepted by golang-jwt.
+func validKey(key interface{}) bool {
+	switch key.(type) {
+	case []byte, *rsa.PrivateKey, *rsa.PublicKey, *ecdsa.PrivateKey, *ecdsa.PublicKey, ed25519.PrivateKey, ed25519.PublicKey:
+		return true
+	}
+	return false
+}
+
+// keyBytes returns the encoding of key accepted by package jwt.
+func keyBytes(key interface{}) ([]byte, error) {
+	var (
+		der []byte
+		typ string
+		err error
+	)
+	switch k := key.(type) {
+	case []byte:
+		return k, nil
+	case *rsa.PrivateKey:
+		der, typ = x509.MarshalPKCS1PrivateKey(k), "RSA PRIVATE KEY"
+	case *ecdsa.PrivateKey:
+		der, err = x509.MarshalECPrivateKey(k)
+		typ = "EC PRIVATE KEY"
+	case ed25519.PrivateKey:
+		der, err = x509.MarshalPKCS8PrivateKey(k)
+		typ = "PRIVATE KEY"
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		der, err = x509.MarshalPKIXPublicKey(k)
+		typ = "PUBLIC KEY"
+	default:
+		return nil, ErrInvalidKeyType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), nil
+}
@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Config is a declarative verification policy suitable for decoding
+// from configuration files.
+type Config struct {
+	// Issuers is the list of accepted iss claim values.
+	Issuers []string `json:"issuers,omitempty" yaml:"issuers,omitempty"`
+
+	// Audiences is the list of accepted aud claim values.
+	Audiences []string `json:"audiences,omitempty" yaml:"audiences,omitempty"`
+
+	// AudienceMatch is the comparison of aud claim values to the
+	// accepted audiences, "exact", "fold" or "url". The default is
+	// "exact". See AudienceExact, AudienceFold and AudienceURL.
+	AudienceMatch string `json:"audience_match,omitempty" yaml:"audience_match,omitempty"`
+
+	// StrictURIs requires the issuers and audiences, and the iss, sub
+	// and aud claims, to be valid StringOrURI values. See WithStringOrURI.
+	StrictURIs bool `json:"strict_uris,omitempty" yaml:"strict_uris,omitempty"`
+
+	// TenantClaim is the claim, such as "org_id", that must equal the
+	// expected tenant of the request. See WithTenant.
+	TenantClaim string `json:"tenant_claim,omitempty" yaml:"tenant_claim,omitempty"`
+
+	// Algorithms is the list of accepted alg header values.
+	Algorithms []string `json:"algorithms" yaml:"algorithms"`
+
+	// Types is the list of accepted typ header values. The empty
+	// string accepts tokens without a typ header. The default is "JWT".
+	Types []string `json:"types,omitempty" yaml:"types,omitempty"`
+
+	// JWKSURLs is the list of JWKS documents to source keys from.
+	JWKSURLs []string `json:"jwks_urls" yaml:"jwks_urls"`
+
+	// HTTPClient is the client used to fetch the JWKS documents.
+	// The default is DefaultHTTPClient.
+	HTTPClient *http.Client `json:"-" yaml:"-"`
+
+	// Leeway is the allowed clock skew, such as "30s".
+	Leeway Duration `json:"leeway,omitempty" yaml:"leeway,omitempty"`
+
+	// MaxAge is the maximum age of tokens by their iat claim, such as
+	// "24h". See WithMaxAge.
+	MaxAge Duration `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+
+	// RequiredClaims is the list of claims that must be present.
+	RequiredClaims []string `json:"required_claims,omitempty" yaml:"required_claims,omitempty"`
+}
+
+// Config errors.
+var (
+	ErrConfigAlgorithms = errors.New("jwt: config requires at least one algorithm")
+	ErrConfigKeys       = errors.New("jwt: config requires at least one jwks url")
+)
+
+// NewVerifierFromConfig returns a new Verifier enforcing the policy
+// described by c. Additional options are applied after the config.
+// Algorithms are resolved with DefaultRegistry.
+func NewVerifierFromConfig(c Config, opts ...Option) (*Verifier, error) {
+	return DefaultRegistry.NewVerifierFromConfig(c, opts...)
+}
+
+// NewVerifierFromConfig returns a new Verifier enforcing the policy
+// described by c, resolving algorithms with the registry.
+func (r *Registry) NewVerifierFromConfig(c Config, opts ...Option) (*Verifier, error) {
+	if len(c.Algorithms) == 0 {
+		return nil, ErrConfigAlgorithms
+	}
+	if len(c.JWKSURLs) == 0 {
+		return nil, ErrConfigKeys
+	}
+	equal, ok := audienceComparators[c.AudienceMatch]
+	if !ok {
+		return nil, fmt.Errorf("jwt: unknown audience match %q", c.AudienceMatch)
+	}
+	if c.StrictURIs {
+		for _, values := range [][]string{c.Issuers, c.Audiences} {
+			for _, v := range values {
+				if !ValidStringOrURI(v) {
+					return nil, fmt.Errorf("%w: %q", ErrClaimURI, v)
+				}
+			}
+		}
+	}
+	s := make([]Signer, 0, len(c.Algorithms))
+	for _, name := range c.Algorithms {
+		signer, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("jwt: unknown algorithm %q", name)
+		}
+		s = append(s, signer)
+	}
+	keys := make(KeyProviders, 0, len(c.JWKSURLs))
+	for _, url := range c.JWKSURLs {
+		keys = append(keys, NewRemoteKeySet(url, WithHTTPClient(c.client())))
+	}
+	policy := []Option{
+		WithLeeway(time.Duration(c.Leeway)),
+	}
+	if c.MaxAge > 0 {
+		policy = append(policy, WithMaxAge(time.Duration(c.MaxAge)))
+	}
+	if len(c.Issuers) > 0 {
+		policy = append(policy, WithIssuer(c.Issuers...))
+	}
+	if len(c.Audiences) > 0 {
+		policy = append(policy, WithAudience(c.Audiences...), WithAudienceComparator(equal))
+	}
+	if c.StrictURIs {
+		policy = append(policy, WithStringOrURI())
+	}
+	if c.TenantClaim != "" {
+		policy = append(policy, WithTenant(c.TenantClaim, nil))
+	}
+	if len(c.Types) > 0 {
+		policy = append(policy, WithAcceptedTypes(c.Types...))
+	}
+	if len(c.RequiredClaims) > 0 {
+		policy = append(policy, WithRequired(c.RequiredClaims...))
+	}
+	return NewVerifier(s, keys, append(policy, opts...)...), nil
+}
+
+// client returns the HTTP client used to fetch JWKS documents.
+func (c Config) client() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return DefaultHTTPClient
+}
+
+// Duration is a time.Duration that is encoded as a string such as "1m30s".
+type Duration time.Duration
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
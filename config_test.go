@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewVerifierFromConfig(t *testing.T) {
+	key := []byte("secret")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"keys":[{"kty":"oct","kid":"a","k":"` + encode(key) + `"}]}`))
+	}))
+	defer srv.Close()
+	var c Config
+	err := json.Unmarshal([]byte(`{
+		"issuers": ["https://issuer.example"],
+		"audiences": ["api"],
+		"algorithms": ["HS256"],
+		"jwks_urls": ["`+srv.URL+`"],
+		"leeway": "1m",
+		"required_claims": ["sub"]
+	}`), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(c.Leeway) != time.Minute {
+		t.Fatalf("leeway\nhave %v\nwant %v", time.Duration(c.Leeway), time.Minute)
+	}
+	v, err := NewVerifierFromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	var tests = []struct {
+		claims map[string]interface{}
+		err    error
+	}{
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": "api", "sub": "a"}, nil},
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": []string{"x", "api"}, "sub": "a"}, nil},
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": "api", "sub": "a", "exp": now - 30}, nil},
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": "api", "sub": "a", "exp": now - 90}, ErrClaimExpired},
+		{map[string]interface{}{"iss": "https://other.example", "aud": "api", "sub": "a"}, ErrClaimIssuer},
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": "web", "sub": "a"}, ErrClaimAudience},
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": "api"}, ErrClaimRequired},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Header["kid"] = "a"
+		token.Claims = tt.claims
+		jwt, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = v.Verify(context.Background(), jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestNewVerifierFromConfigInvalid(t *testing.T) {
+	var tests = []struct {
+		c   Config
+		err error
+	}{
+		{Config{JWKSURLs: []string{"https://issuer.example/jwks"}}, ErrConfigAlgorithms},
+		{Config{Algorithms: []string{"HS256"}}, ErrConfigKeys},
+	}
+	for i, tt := range tests {
+		_, err := NewVerifierFromConfig(tt.c)
+		if err != tt.err {
+			t.Errorf("%d. NewVerifierFromConfig err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+	_, err := NewVerifierFromConfig(Config{
+		Algorithms: []string{"none"},
+		JWKSURLs:   []string{"https://issuer.example/jwks"},
+	})
+	if err == nil {
+		t.Errorf("should return unknown algorithm error")
+	}
+	_, err = NewVerifierFromConfig(Config{
+		Algorithms:    []string{"HS256"},
+		JWKSURLs:      []string{"https://issuer.example/jwks"},
+		AudienceMatch: "prefix",
+	})
+	if err == nil {
+		t.Errorf("should return unknown audience match error")
+	}
+	_, err = NewVerifierFromConfig(Config{
+		Issuers:    []string{"https//issuer.example:443"},
+		Algorithms: []string{"HS256"},
+		JWKSURLs:   []string{"https://issuer.example/jwks"},
+		StrictURIs: true,
+	})
+	if !errors.Is(err, ErrClaimURI) {
+		t.Errorf("strict uris err\nhave %v\nwant %v", err, ErrClaimURI)
+	}
+}
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+)
+
+// OIDNamedCurveSecp256k1 is the object identifier of the secp256k1
+// curve used by ES256K.
+//
+// See RFC 8812 Section 3.
+var OIDNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// oidPublicKeyECDSA is the id-ecPublicKey algorithm identifier.
+var oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+
+// curve is an elliptic curve not supported by crypto/x509.
+type curve struct {
+	elliptic.Curve
+	oid asn1.ObjectIdentifier
+}
+
+// NewECDSACurveSigner returns a new ECDSASigner for an elliptic curve
+// implementation not provided by the standard library, such as the
+// secp256k1 curve used by ES256K. Keys are PEM-encoded SEC 1 private
+// keys and PKIX public keys naming the curve by oid.
+//
+//	ES256K := jwt.NewECDSACurveSigner("ES256K", crypto.SHA256, secp256k1.S256(), jwt.OIDNamedCurveSecp256k1)
+//	jwt.DefaultRegistry.Register(ES256K)
+func NewECDSACurveSigner(name string, hash crypto.Hash, c elliptic.Curve, oid asn1.ObjectIdentifier) ECDSASigner {
+	return ECDSASigner{name: name, hash: hash, curve: &curve{Curve: c, oid: oid}}
+}
+
+// ecPrivateKey is a SEC 1 elliptic curve private key.
+//
+// See RFC 5915 Section 3.
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// publicKeyInfo is a PKIX elliptic curve public key.
+//
+// See RFC 5480 Section 2.
+type publicKeyInfo struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		NamedCurve asn1.ObjectIdentifier
+	}
+	PublicKey asn1.BitString
+}
+
+// parsePrivateKey parses a DER-encoded SEC 1 private key on the curve.
+func (c *curve) parsePrivateKey(der []byte) (*ecdsa.PrivateKey, error) {
+	var k ecPrivateKey
+	rest, err := asn1.Unmarshal(der, &k)
+	if err != nil || len(rest) > 0 {
+		return nil, errors.New("jwt: invalid ecdsa private key")
+	}
+	if k.NamedCurveOID != nil && !k.NamedCurveOID.Equal(c.oid) {
+		return nil, errors.New("jwt: invalid ecdsa private key")
+	}
+	d := new(big.Int).SetBytes(k.PrivateKey)
+	n := c.Params().N
+	if d.Sign() <= 0 || d.Cmp(n) >= 0 {
+		return nil, errors.New("jwt: invalid ecdsa private key")
+	}
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.Curve = c.Curve
+	priv.X, priv.Y = c.ScalarBaseMult(k.PrivateKey)
+	return priv, nil
+}
+
+// parsePublicKey parses a DER-encoded PKIX public key on the curve.
+func (c *curve) parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var k publicKeyInfo
+	rest, err := asn1.Unmarshal(der, &k)
+	if err != nil || len(rest) > 0 {
+		return nil, errors.New("jwt: invalid ecdsa public key")
+	}
+	if !k.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) || !k.Algorithm.NamedCurve.Equal(c.oid) {
+		return nil, errors.New("jwt: invalid ecdsa public key")
+	}
+	x, y := elliptic.Unmarshal(c.Curve, k.PublicKey.RightAlign())
+	if x == nil {
+		return nil, errors.New("jwt: invalid ecdsa public key")
+	}
+	return &ecdsa.PublicKey{Curve: c.Curve, X: x, Y: y}, nil
+}
@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+)
+
+// testCurve hides the standard curve implementation from crypto/x509.
+type testCurve struct {
+	elliptic.Curve
+}
+
+func TestECDSACurveSigner(t *testing.T) {
+	b := []byte("foo")
+	c := testCurve{elliptic.P256()}
+	oid := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999}
+	s := NewECDSACurveSigner("ES256T", crypto.SHA256, c, oid)
+	priv, err := ecdsa.GenerateKey(c, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point := elliptic.Marshal(c, priv.X, priv.Y)
+	der, err := asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    priv.D.FillBytes(make([]byte, 32)),
+		NamedCurveOID: oid,
+		PublicKey:     asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	var info publicKeyInfo
+	info.Algorithm.Algorithm = oidPublicKeyECDSA
+	info.Algorithm.NamedCurve = oid
+	info.PublicKey = asn1.BitString{Bytes: point, BitLength: 8 * len(point)}
+	der, err = asn1.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	sig, err := s.Sign(b, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Verify(b, sig, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[0] ^= 0xFF
+	err = s.Verify(b, sig, publicKey)
+	if err != ErrInvalidSignature {
+		t.Fatal("should be invalid")
+	}
+	other := NewECDSACurveSigner("ES256K", crypto.SHA256, c, OIDNamedCurveSecp256k1)
+	err = other.Verify(b, sig, publicKey)
+	if err == nil || err == ErrInvalidSignature {
+		t.Fatalf("should reject key for another curve, have %v", err)
+	}
+}
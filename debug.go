@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Inspection is a byte-level view of a compact token for diagnosing
+// interoperability failures. The token is not verified.
+type Inspection struct {
+	// SigningInput is the exact input to the signature algorithm.
+	SigningInput string
+
+	// Header and Payload are the decoded JSON segments.
+	Header  []byte
+	Payload []byte
+
+	// Signature is the decoded signature.
+	Signature []byte
+}
+
+// Inspect decodes the segments of jwt without verifying it.
+func Inspect(jwt string) (*Inspection, error) {
+	h, p, sig, err := SplitCompact(jwt)
+	if err != nil {
+		return nil, err
+	}
+	return &Inspection{
+		SigningInput: jwt[:strings.LastIndex(jwt, sep)],
+		Header:       h,
+		Payload:      p,
+		Signature:    sig,
+	}, nil
+}
+
+// SignatureHex returns the signature as lowercase hexadecimal.
+func (i *Inspection) SignatureHex() string {
+	return hex.EncodeToString(i.Signature)
+}
+
+// SignatureBase64 returns the signature as padding-free URL-safe base64.
+func (i *Inspection) SignatureBase64() string {
+	return encode(i.Signature)
+}
+
+// String returns a multi-line diagnostic description of the token.
+func (i *Inspection) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "header:        %s\n", i.Header)
+	fmt.Fprintf(&b, "payload:       %s\n", i.Payload)
+	fmt.Fprintf(&b, "signing input: %s\n", i.SigningInput)
+	fmt.Fprintf(&b, "signature:     %d bytes\n", len(i.Signature))
+	fmt.Fprintf(&b, "  hex:         %s\n", i.SignatureHex())
+	fmt.Fprintf(&b, "  base64url:   %s\n", i.SignatureBase64())
+	return b.String()
+}
@@ -0,0 +1,24 @@
+package jwt
+
+import (
+	"crypto/hkdf"
+	stdhash "hash"
+)
+
+// DeriveKey returns the HMAC key for purpose, such as "session" or
+// "email-verification", derived from the master secret using HKDF with
+// the purpose as the context info. Keys derived for different purposes
+// are independent, so one root secret can back several token types
+// without a token of one type verifying as another. The master secret
+// must be at least as long as the hash output.
+//
+// See RFC 5869.
+func (s HMACSigner) DeriveKey(master []byte, purpose string) ([]byte, error) {
+	if !s.hash.Available() {
+		return nil, ErrHashUnavailable
+	}
+	if len(master) < s.hash.Size() {
+		return nil, ErrKeyTooShort
+	}
+	return hkdf.Key(func() stdhash.Hash { return s.hash.New() }, master, nil, purpose, s.hash.Size())
+}
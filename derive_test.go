@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	master := []byte("0123456789abcdef0123456789abcdef")
+	session, err := HS256.DeriveKey(master, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrf, err := HS256.DeriveKey(master, "csrf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session) != 32 || bytes.Equal(session, csrf) || bytes.Equal(session, master) {
+		t.Fatalf("derived keys must be distinct hash-sized keys")
+	}
+	again, err := HS256.DeriveKey(master, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(session, again) {
+		t.Fatalf("derived keys must be deterministic")
+	}
+	token, err := New(HS256).Sign(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(HS256, token, csrf)
+	if err != ErrInvalidSignature {
+		t.Fatalf("Parse err\nhave %v\nwant %v", err, ErrInvalidSignature)
+	}
+	_, err = HS512.DeriveKey(master, "session")
+	if err != ErrKeyTooShort {
+		t.Fatalf("DeriveKey err\nhave %v\nwant %v", err, ErrKeyTooShort)
+	}
+}
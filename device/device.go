@@ -0,0 +1,261 @@
+// Package device implements the OAuth 2.0 device authorization grant
+// for command line applications acquiring tokens on behalf of a user.
+// Refresh tokens are persisted with the tokenstore package and redeemed
+// for fresh access tokens by a jwt.TokenManager.
+//
+// See RFC 8628.
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pnelson/jwt"
+	"github.com/pnelson/jwt/tokenstore"
+)
+
+// Device flow errors.
+var (
+	ErrAccessDenied = errors.New("device: access denied")
+	ErrExpiredToken = errors.New("device: device code expired")
+	ErrNoIDToken    = errors.New("device: no id token issued")
+	ErrNoRefresh    = errors.New("device: no refresh token issued")
+)
+
+// grantType is the device code grant type.
+const grantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// refreshGrantType is the refresh token grant type.
+const refreshGrantType = "refresh_token"
+
+// Config describes an authorization server and client.
+type Config struct {
+	// ClientID is the OAuth client identifier.
+	ClientID string
+
+	// AuthorizationURL is the device authorization endpoint.
+	AuthorizationURL string
+
+	// TokenURL is the token endpoint.
+	TokenURL string
+
+	// Scopes is the list of requested scopes.
+	Scopes []string
+
+	// Verifier verifies the returned tokens, if not nil.
+	Verifier jwt.TokenVerifier
+
+	// Client is the HTTP client. Defaults to jwt.DefaultHTTPClient.
+	Client *http.Client
+}
+
+// Authorization is a pending device authorization.
+// The user must visit VerificationURI and enter UserCode.
+type Authorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// Token is the result of a completed device authorization.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+
+	// Verified is the verified ID token. It is nil if the config has
+	// no verifier.
+	Verified *jwt.Token `json:"-"`
+}
+
+// Authorize starts a device authorization.
+func (c *Config) Authorize(ctx context.Context) (*Authorization, error) {
+	v := url.Values{"client_id": {c.ClientID}}
+	if len(c.Scopes) > 0 {
+		v.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	var a Authorization
+	err := c.post(ctx, c.AuthorizationURL, v, &a)
+	if err != nil {
+		return nil, err
+	}
+	if a.Interval == 0 {
+		a.Interval = 5
+	}
+	return &a, nil
+}
+
+// Poll polls the token endpoint until the user completes or denies
+// the authorization, the device code expires, or ctx is done.
+func (c *Config) Poll(ctx context.Context, a *Authorization) (*Token, error) {
+	interval := time.Duration(a.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(a.ExpiresIn) * time.Second)
+	v := url.Values{
+		"grant_type":  {grantType},
+		"device_code": {a.DeviceCode},
+		"client_id":   {c.ClientID},
+	}
+	for {
+		if a.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, ErrExpiredToken
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		var t Token
+		err := c.post(ctx, c.TokenURL, v, &t)
+		var e *Error
+		if errors.As(err, &e) {
+			switch e.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			case "access_denied":
+				return nil, ErrAccessDenied
+			case "expired_token":
+				return nil, ErrExpiredToken
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = c.verify(ctx, &t, true)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+}
+
+// Refresh redeems the refresh token for a new token. The ID token is
+// verified if one is issued, as it may be omitted from refresh responses.
+//
+// See RFC 6749 Section 6.
+func (c *Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	v := url.Values{
+		"grant_type":    {refreshGrantType},
+		"refresh_token": {refreshToken},
+		"client_id":     {c.ClientID},
+	}
+	var t Token
+	err := c.post(ctx, c.TokenURL, v, &t)
+	if err != nil {
+		return nil, err
+	}
+	err = c.verify(ctx, &t, false)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// TokenSource returns a fetch function for jwt.NewTokenManager that
+// redeems the refresh token saved at path by Token.Save and returns the
+// new access token, which must be a JWT. A refresh token rotated by the
+// server is saved in place of the previous one.
+func (c *Config) TokenSource(path string, ks tokenstore.KeySource) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		refreshToken, err := tokenstore.Load(path, ks)
+		if err != nil {
+			return "", err
+		}
+		t, err := c.Refresh(ctx, refreshToken)
+		if err != nil {
+			return "", err
+		}
+		if t.RefreshToken != "" && t.RefreshToken != refreshToken {
+			err = tokenstore.Save(path, t.RefreshToken, ks)
+			if err != nil {
+				return "", err
+			}
+		}
+		return t.AccessToken, nil
+	}
+}
+
+// Save saves the refresh token of t at path, encrypted with a key from
+// ks, for use by TokenSource.
+func (t *Token) Save(path string, ks tokenstore.KeySource) error {
+	if t.RefreshToken == "" {
+		return ErrNoRefresh
+	}
+	return tokenstore.Save(path, t.RefreshToken, ks)
+}
+
+// verify verifies the ID token with the configured verifier. Access
+// tokens are intended for the resource server and are never verified
+// in place of a missing ID token, which is an error if required.
+func (c *Config) verify(ctx context.Context, t *Token, required bool) error {
+	if c.Verifier == nil {
+		return nil
+	}
+	if t.IDToken == "" && !required {
+		return nil
+	}
+	if t.IDToken == "" {
+		return ErrNoIDToken
+	}
+	verified, err := c.Verifier.Verify(ctx, t.IDToken)
+	if err != nil {
+		return err
+	}
+	t.Verified = verified
+	return nil
+}
+
+// Error is an OAuth error response.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return "device: " + e.Code
+	}
+	return "device: " + e.Code + ": " + e.Description
+}
+
+// post submits the form to endpoint and decodes the JSON response into v.
+func (c *Config) post(ctx context.Context, endpoint string, form url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	client := c.Client
+	if client == nil {
+		client = jwt.DefaultHTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e Error
+		err = json.NewDecoder(resp.Body).Decode(&e)
+		if err != nil || e.Code == "" {
+			return fmt.Errorf("device: %s: unexpected status %s", endpoint, resp.Status)
+		}
+		return &e
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
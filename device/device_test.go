@@ -0,0 +1,173 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pnelson/jwt"
+	"github.com/pnelson/jwt/tokenstore"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	key := []byte("secret")
+	token := jwt.New(jwt.HS256)
+	token.Claims["sub"] = "user"
+	idToken, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Authorization{
+			DeviceCode:      "device",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://issuer.example/device",
+			ExpiresIn:       60,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("device_code") != "device" || r.FormValue("grant_type") != grantType {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Code: "invalid_grant"})
+			return
+		}
+		polls++
+		if polls < 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Code: "authorization_pending"})
+			return
+		}
+		json.NewEncoder(w).Encode(Token{AccessToken: "access", TokenType: "Bearer", IDToken: idToken})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c := &Config{
+		ClientID:         "cli",
+		AuthorizationURL: srv.URL + "/device",
+		TokenURL:         srv.URL + "/token",
+		Verifier:         jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.KeyFunc(func(*jwt.Token) ([]byte, error) { return key, nil })),
+	}
+	a, err := c.Authorize(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.UserCode != "ABCD-EFGH" {
+		t.Fatalf("have %s\nwant %s", a.UserCode, "ABCD-EFGH")
+	}
+	if a.Interval != 5 {
+		t.Fatalf("have %d\nwant %d", a.Interval, 5)
+	}
+	a.Interval = 0
+	have, err := c.Poll(context.Background(), a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 {
+		t.Errorf("polls\nhave %d\nwant %d", polls, 2)
+	}
+	if have.Verified == nil || have.Verified.Claims["sub"] != "user" {
+		t.Errorf("should return verified id token")
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Error{Code: "access_denied"})
+	}))
+	defer srv.Close()
+	c := &Config{ClientID: "cli", TokenURL: srv.URL}
+	_, err := c.Poll(context.Background(), &Authorization{DeviceCode: "device", ExpiresIn: 60})
+	if err != ErrAccessDenied {
+		t.Errorf("should return access denied error")
+	}
+}
+
+func TestDeviceFlowNoIDToken(t *testing.T) {
+	key := []byte("secret")
+	access, err := jwt.New(jwt.HS256).Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Token{AccessToken: access, TokenType: "Bearer"})
+	}))
+	defer srv.Close()
+	c := &Config{
+		ClientID: "cli",
+		TokenURL: srv.URL,
+		Verifier: jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.KeyFunc(func(*jwt.Token) ([]byte, error) { return key, nil })),
+	}
+	_, err = c.Poll(context.Background(), &Authorization{DeviceCode: "device", ExpiresIn: 60})
+	if err != ErrNoIDToken {
+		t.Errorf("Poll err\nhave %v\nwant %v", err, ErrNoIDToken)
+	}
+}
+
+type staticKey []byte
+
+func (k staticKey) Key(salt []byte) ([]byte, error) {
+	return k, nil
+}
+
+func TestDeviceTokenSource(t *testing.T) {
+	key := []byte("secret")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") == grantType {
+			json.NewEncoder(w).Encode(Token{AccessToken: "access", TokenType: "Bearer", RefreshToken: "r1"})
+			return
+		}
+		if r.FormValue("grant_type") != refreshGrantType || r.FormValue("refresh_token") != "r1" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Code: "invalid_grant"})
+			return
+		}
+		token := jwt.New(jwt.HS256)
+		token.Claims["exp"] = time.Now().Add(time.Hour).Unix()
+		access, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(Token{AccessToken: access, TokenType: "Bearer", RefreshToken: "r2"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c := &Config{ClientID: "cli", TokenURL: srv.URL + "/token"}
+	tok, err := c.Poll(context.Background(), &Authorization{DeviceCode: "device", ExpiresIn: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "refresh")
+	ks := staticKey(make([]byte, 32))
+	err = tok.Save(path, ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jwt.NewTokenManager(c.TokenSource(path, ks), time.Minute)
+	access, err := m.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = jwt.Parse(jwt.HS256, access, key)
+	if err != nil {
+		t.Errorf("Parse err\nhave %v\nwant %v", err, nil)
+	}
+	saved, err := tokenstore.Load(path, ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != "r2" {
+		t.Errorf("should save the rotated refresh token\nhave %v\nwant %v", saved, "r2")
+	}
+	err = (&Token{}).Save(path, ks)
+	if err != ErrNoRefresh {
+		t.Errorf("Save err\nhave %v\nwant %v", err, ErrNoRefresh)
+	}
+}
@@ -0,0 +1,224 @@
+// Package devissuer implements a local token issuer for integration
+// tests and development environments. It mints tokens with arbitrary
+// claims, signed with an ephemeral key published as a JSON Web Key Set,
+// so real long-lived tokens never need to be shared.
+//
+// An Issuer is an http.Handler serving:
+//
+//	GET  /.well-known/openid-configuration  discovery document
+//	GET  /.well-known/jwks.json             public key set
+//	POST /token                             mint a token from a JSON claims object
+//
+// The issuer must never be exposed outside of development environments.
+package devissuer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// DefaultTTL is the default lifetime of minted tokens.
+const DefaultTTL = time.Hour
+
+// Issuer mints tokens signed with an ephemeral ES256 key.
+type Issuer struct {
+	// URL is the iss claim of minted tokens and the base of the
+	// discovery document endpoints.
+	URL string
+
+	// TTL is the lifetime of minted tokens. Defaults to DefaultTTL.
+	TTL time.Duration
+
+	kid        string
+	privateKey []byte
+	publicKey  []byte
+	pub        *ecdsa.PublicKey
+}
+
+// New returns a new Issuer for url with a freshly generated key.
+func New(url string) (*Issuer, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	kid := make([]byte, 8)
+	_, err = rand.Read(kid)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{
+		URL:        url,
+		TTL:        DefaultTTL,
+		kid:        hex.EncodeToString(kid),
+		privateKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		publicKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		pub:        &priv.PublicKey,
+	}, nil
+}
+
+// Mint returns a signed token with the claims. The iss, iat and exp
+// claims default to the issuer URL, the current time and the end of
+// the issuer TTL unless present in claims.
+func (i *Issuer) Mint(claims map[string]interface{}) (string, error) {
+	now := time.Now()
+	defaults := jwt.Claims{
+		jwt.ClaimIssuer:     i.URL,
+		jwt.ClaimIssuedAt:   now.Unix(),
+		jwt.ClaimExpiration: now.Add(i.ttl()).Unix(),
+	}
+	c, err := defaults.Merge(claims, jwt.MergePreferOverride)
+	if err != nil {
+		return "", err
+	}
+	t := jwt.New(jwt.ES256)
+	t.Header[jwt.HeaderKeyID] = i.kid
+	t.Claims = c
+	return t.Sign(i.privateKey)
+}
+
+// PublicKey returns the PEM-encoded public key of the issuer.
+func (i *Issuer) PublicKey() []byte {
+	return i.publicKey
+}
+
+// Verifier returns a new Verifier accepting tokens minted by the issuer.
+func (i *Issuer) Verifier(opts ...jwt.Option) *jwt.Verifier {
+	opts = append([]jwt.Option{jwt.WithIssuer(i.URL)}, opts...)
+	return jwt.NewVerifier([]jwt.Signer{jwt.ES256}, jwt.StaticKey(i.publicKey), opts...)
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		i.discovery(w, r)
+	case "/.well-known/jwks.json":
+		i.jwks(w, r)
+	case "/token":
+		i.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	write(w, map[string]interface{}{
+		"issuer":                                i.URL,
+		"jwks_uri":                              i.URL + "/.well-known/jwks.json",
+		"token_endpoint":                        i.URL + "/token",
+		"id_token_signing_alg_values_supported": []string{"ES256"},
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	b64 := base64.RawURLEncoding
+	write(w, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"crv": "P-256",
+			"kid": i.kid,
+			"alg": "ES256",
+			"use": "sig",
+			"x":   b64.EncodeToString(i.pub.X.FillBytes(make([]byte, 32))),
+			"y":   b64.EncodeToString(i.pub.Y.FillBytes(make([]byte, 32))),
+		}},
+	})
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	claims := make(map[string]interface{})
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&claims)
+		if err != nil {
+			http.Error(w, "devissuer: claims must be a json object", http.StatusBadRequest)
+			return
+		}
+	}
+	token, err := i.Mint(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	write(w, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(i.ttl().Seconds()),
+	})
+}
+
+func (i *Issuer) ttl() time.Duration {
+	if i.TTL <= 0 {
+		return DefaultTTL
+	}
+	return i.TTL
+}
+
+// write writes v as a JSON response.
+func write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Server is an Issuer listening on a local loopback address.
+type Server struct {
+	*Issuer
+	srv *httptest.Server
+}
+
+// NewServer starts and returns a new Server. The issuer URL is the
+// address of the server. The caller should call Close when finished.
+func NewServer() (*Server, error) {
+	srv := httptest.NewUnstartedServer(nil)
+	iss, err := New("http://" + srv.Listener.Addr().String())
+	if err != nil {
+		srv.Close()
+		return nil, err
+	}
+	srv.Config.Handler = iss
+	srv.Start()
+	return &Server{Issuer: iss, srv: srv}, nil
+}
+
+// JWKSURL returns the URL of the public key set.
+func (s *Server) JWKSURL() string {
+	return s.URL + "/.well-known/jwks.json"
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
@@ -0,0 +1,78 @@
+package devissuer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+func TestServer(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	resp, err := http.Post(s.URL+"/token", "application/json", strings.NewReader(`{"sub":"dev","scope":"read"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := jwt.NewVerifier([]jwt.Signer{jwt.ES256}, jwt.NewRemoteKeySet(s.JWKSURL()), jwt.WithIssuer(s.URL))
+	token, err := v.Verify(context.Background(), body.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Claims["sub"] != "dev" || token.Claims["scope"] != "read" {
+		t.Errorf("unexpected claims %v", token.Claims)
+	}
+	_, err = s.Verifier().Verify(context.Background(), body.AccessToken)
+	if err != nil {
+		t.Errorf("Verifier err\nhave %v\nwant %v", err, nil)
+	}
+}
+
+func TestIssuerHandler(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var tests = []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/.well-known/openid-configuration", "", http.StatusOK},
+		{http.MethodGet, "/.well-known/jwks.json", "", http.StatusOK},
+		{http.MethodPost, "/token", "", http.StatusOK},
+		{http.MethodPost, "/token", "[]", http.StatusBadRequest},
+		{http.MethodGet, "/token", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/missing", "", http.StatusNotFound},
+	}
+	for i, tt := range tests {
+		req, err := http.NewRequest(tt.method, s.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%d. %s %s status\nhave %d\nwant %d", i, tt.method, tt.path, resp.StatusCode, tt.status)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	stdhash "hash"
+)
+
+// ErrKeyPassphrase is returned when an encrypted key cannot be
+// decrypted with the passphrase.
+var ErrKeyPassphrase = errors.New("jwt: incorrect key passphrase")
+
+// ErrKeyEncryption is returned when an encrypted key uses an
+// unsupported encryption scheme.
+var ErrKeyEncryption = errors.New("jwt: unsupported key encryption")
+
+// PassphraseFunc returns the passphrase of an encrypted key.
+type PassphraseFunc func() ([]byte, error)
+
+// DecryptKey decrypts a passphrase-protected PEM private key and
+// returns the unencrypted PEM key suitable for signing.
+//
+// See DecryptKeyFunc.
+func DecryptKey(b, passphrase []byte) ([]byte, error) {
+	return DecryptKeyFunc(b, func() ([]byte, error) {
+		return passphrase, nil
+	})
+}
+
+// DecryptKeyFunc decrypts a passphrase-protected PEM private key using
+// the passphrase returned by fn and returns the unencrypted PEM key
+// suitable for signing. The fn is only called if the key is encrypted,
+// otherwise b is returned unchanged.
+//
+// Both PKCS #8 "ENCRYPTED PRIVATE KEY" blocks using PBES2 with PBKDF2
+// and AES-CBC, and legacy RFC 1423 encrypted blocks, such as those
+// written by openssl with -aes256, are supported. Legacy encryption is
+// weak and should only be used for existing keys.
+func DecryptKeyFunc(b []byte, fn PassphraseFunc) ([]byte, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, ErrKeyNotPEM
+	}
+	legacy := x509.IsEncryptedPEMBlock(block)
+	if !legacy && block.Type != "ENCRYPTED PRIVATE KEY" {
+		return b, nil
+	}
+	passphrase, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		der, err := x509.DecryptPEMBlock(block, passphrase)
+		if err == x509.IncorrectPasswordError {
+			return nil, ErrKeyPassphrase
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, err)
+		}
+		if !parsesAs(block.Type, der) {
+			return nil, ErrKeyPassphrase
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+	}
+	der, err := decryptPKCS8(block.Bytes, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// parsesAs reports whether der is a private key of the PEM block type.
+// The padding check of legacy encryption passes for about one in 256
+// incorrect passphrases, so the decrypted key must also parse.
+func parsesAs(typ string, der []byte) bool {
+	var err error
+	switch typ {
+	case "RSA PRIVATE KEY":
+		_, err = x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		_, err = x509.ParseECPrivateKey(der)
+	default:
+		_, err = x509.ParsePKCS8PrivateKey(der)
+	}
+	return err == nil
+}
+
+// Object identifiers of the supported PKCS #5 algorithms.
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// encryptedPrivateKeyInfo is the ASN.1 structure of a PKCS #8
+// encrypted private key.
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params is the ASN.1 structure of the PBES2 parameters.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is the ASN.1 structure of the PBKDF2 parameters.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPKCS8 returns the DER-encoded PKCS #8 private key decrypted
+// from the DER-encoded encrypted private key info.
+func decryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	_, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, info.Algorithm.Algorithm)
+	}
+	var params pbes2Params
+	_, err = asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	_, err = asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	var prf func() stdhash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA384):
+		prf = sha512.New384
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, kdf.PRF.Algorithm)
+	}
+	var size int
+	switch {
+	case params.EncryptionScheme.Algorithm.Equal(oidAES128CBC):
+		size = 16
+	case params.EncryptionScheme.Algorithm.Equal(oidAES192CBC):
+		size = 24
+	case params.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		size = 32
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, params.EncryptionScheme.Algorithm)
+	}
+	var iv []byte
+	_, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	if kdf.IterationCount < 1 || kdf.KeyLength != 0 && kdf.KeyLength != size {
+		return nil, fmt.Errorf("%w: invalid pbkdf2 parameters", ErrKeyCorrupt)
+	}
+	if len(iv) != aes.BlockSize || len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, ErrKeyCorrupt
+	}
+	key, err := pbkdf2.Key(prf, string(passphrase), kdf.Salt, kdf.IterationCount, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(c, iv).CryptBlocks(b, info.EncryptedData)
+	n := int(b[len(b)-1])
+	if n == 0 || n > aes.BlockSize {
+		return nil, ErrKeyPassphrase
+	}
+	for _, p := range b[len(b)-n:] {
+		if int(p) != n {
+			return nil, ErrKeyPassphrase
+		}
+	}
+	b = b[:len(b)-n]
+	_, err = x509.ParsePKCS8PrivateKey(b)
+	if err != nil {
+		return nil, ErrKeyPassphrase
+	}
+	return b, nil
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+// encryptPKCS8 returns der encrypted with PBES2 using PBKDF2 with
+// HMAC-SHA256 and AES-256-CBC.
+func encryptPKCS8(t *testing.T, der, passphrase []byte) []byte {
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	rand.Read(salt)
+	rand.Read(iv)
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, 1000, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := aes.BlockSize - len(der)%aes.BlockSize
+	b := append([]byte{}, der...)
+	for i := 0; i < n; i++ {
+		b = append(b, byte(n))
+	}
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher.NewCBCEncrypter(c, iv).CryptBlocks(b, b)
+	marshal := func(v interface{}) asn1.RawValue {
+		b, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asn1.RawValue{FullBytes: b}
+	}
+	info := encryptedPrivateKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm: oidPBES2,
+			Parameters: marshal(pbes2Params{
+				KeyDerivationFunc: pkix.AlgorithmIdentifier{
+					Algorithm: oidPBKDF2,
+					Parameters: marshal(pbkdf2Params{
+						Salt:           salt,
+						IterationCount: 1000,
+						PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+					}),
+				},
+				EncryptionScheme: pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: marshal(iv)},
+			}),
+		},
+		EncryptedData: b,
+	}
+	out, err := asn1.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: out})
+}
+
+func TestDecryptKey(t *testing.T) {
+	passphrase := []byte("passphrase")
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", sec1, passphrase, x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Garbage that decrypts with valid padding, as a wrong passphrase
+	// occasionally does, must not be returned as a key.
+	garbage, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", []byte("not a key"), passphrase, x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})
+	var tests = []struct {
+		key        []byte
+		passphrase []byte
+		err        error
+	}{
+		{encryptPKCS8(t, pkcs8, passphrase), passphrase, nil},
+		{encryptPKCS8(t, pkcs8, passphrase), []byte("wrong"), ErrKeyPassphrase},
+		{pem.EncodeToMemory(legacy), passphrase, nil},
+		{pem.EncodeToMemory(legacy), []byte("wrong"), ErrKeyPassphrase},
+		{pem.EncodeToMemory(garbage), passphrase, ErrKeyPassphrase},
+		{plain, nil, nil},
+		{[]byte("not pem"), passphrase, ErrKeyNotPEM},
+	}
+	publicKey, err := encodePublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		key, err := DecryptKey(tt.key, tt.passphrase)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. DecryptKey err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		sig, err := ES256.Sign([]byte("foo"), key)
+		if err != nil {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		err = ES256.Verify([]byte("foo"), sig, publicKey)
+		if err != nil {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	called := false
+	_, err = DecryptKeyFunc(plain, func() ([]byte, error) {
+		called = true
+		return nil, nil
+	})
+	if err != nil || called {
+		t.Errorf("should not request passphrase for unencrypted key")
+	}
+}
@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"time"
+)
+
+// WithEvaluationTime returns an option that evaluates the exp, nbf and
+// iat claims at the fixed time t rather than the current time.
+func WithEvaluationTime(t time.Time) Option {
+	return func(v *Verifier) {
+		v.now = func() time.Time { return t }
+	}
+}
+
+// EscrowedKeys is a historical key set and the period in which it was
+// used to verify tokens.
+type EscrowedKeys struct {
+	// From is the time the key set came into use.
+	From time.Time
+
+	// Until is the time the key set was retired. The zero time
+	// indicates the key set was never retired.
+	Until time.Time
+
+	// Keys provides the keys of the key set.
+	Keys KeyProvider
+}
+
+// KeyEscrow is an archive of historical key sets.
+type KeyEscrow []EscrowedKeys
+
+// At returns a KeyProvider consulting, in order, each key set that was
+// in use at time t.
+func (e KeyEscrow) At(t time.Time) KeyProvider {
+	var p KeyProviders
+	for _, k := range e {
+		if t.Before(k.From) || !k.Until.IsZero() && !t.Before(k.Until) {
+			continue
+		}
+		p = append(p, k.Keys)
+	}
+	return p
+}
+
+// NewForensicVerifier returns a new Verifier that re-verifies archived
+// tokens exactly as they would have been verified at time t, using
+// the key sets in escrow that were in use at that time.
+func NewForensicVerifier(s []Signer, escrow KeyEscrow, t time.Time, opts ...Option) *Verifier {
+	opts = append(opts[:len(opts):len(opts)], WithEvaluationTime(t))
+	return NewVerifier(s, escrow.At(t), opts...)
+}
@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestForensicVerifier(t *testing.T) {
+	jan := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	escrow := KeyEscrow{
+		{From: jan, Until: jul, Keys: StaticKey([]byte("old"))},
+		{From: jul, Keys: StaticKey([]byte("new"))},
+	}
+	sign := func(key string, iat time.Time) string {
+		token := New(HS256)
+		token.Claims["exp"] = iat.Add(time.Hour).Unix()
+		jwt, err := token.Sign([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jwt
+	}
+	march := jan.AddDate(0, 2, 0)
+	var tests = []struct {
+		jwt string
+		at  time.Time
+		err error
+	}{
+		{sign("old", march), march, nil},
+		{sign("old", march), march.Add(2 * time.Hour), ErrClaimExpired},
+		{sign("old", march), jul, ErrInvalidSignature},
+		{sign("new", jul), jul, nil},
+		{sign("new", jul), jan.AddDate(-1, 0, 0), ErrKeyNotFound},
+	}
+	for i, tt := range tests {
+		v := NewForensicVerifier([]Signer{HS256}, escrow, tt.at)
+		_, err := v.Verify(context.Background(), tt.jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
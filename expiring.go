@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Key advisory reasons.
+const (
+	AdvisoryRotation    = "rotation"
+	AdvisoryCertificate = "certificate"
+)
+
+// KeyAdvisory reports a key nearing its rotation deadline or the expiry
+// of its certificate.
+type KeyAdvisory struct {
+	Kid      string    `json:"kid"`
+	Reason   string    `json:"reason"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// Certificate returns the first certificate of the x5c parameter, or
+// nil if the key has no certificate chain.
+func (k JWK) Certificate() (*x509.Certificate, error) {
+	if len(k.X5c) == 0 {
+		return nil, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(k.X5c[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	return cert, nil
+}
+
+// Expiring returns advisories for the keys whose rotation deadline or
+// certificate expiry is within d of now, including those already past,
+// ordered by deadline. The deadlines map kid values to rotation
+// deadlines and may be nil.
+func (s *KeySet) Expiring(now time.Time, d time.Duration, deadlines map[string]time.Time) ([]KeyAdvisory, error) {
+	var advisories []KeyAdvisory
+	limit := now.Add(d)
+	for _, k := range s.Keys {
+		deadline, ok := deadlines[k.Kid]
+		if ok && !deadline.After(limit) {
+			advisories = append(advisories, KeyAdvisory{Kid: k.Kid, Reason: AdvisoryRotation, Deadline: deadline})
+		}
+		cert, err := k.Certificate()
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil && !cert.NotAfter.After(limit) {
+			advisories = append(advisories, KeyAdvisory{Kid: k.Kid, Reason: AdvisoryCertificate, Deadline: cert.NotAfter})
+		}
+	}
+	sort.SliceStable(advisories, func(i, j int) bool {
+		return advisories[i].Deadline.Before(advisories[j].Deadline)
+	})
+	return advisories, nil
+}
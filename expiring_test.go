@@ -0,0 +1,67 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestKeySetExpiring(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := now.Add(10 * 24 * time.Hour)
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: now, NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewJWK(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := *k, *k
+	a.Kid, b.Kid = "a", "b"
+	b.X5c = []string{base64.StdEncoding.EncodeToString(der)}
+	s := &KeySet{Keys: []JWK{a, b}}
+	deadlines := map[string]time.Time{"a": now.Add(-time.Hour)}
+	var tests = []struct {
+		d    time.Duration
+		want []KeyAdvisory
+	}{
+		{-2 * time.Hour, nil},
+		{time.Hour, []KeyAdvisory{{"a", AdvisoryRotation, now.Add(-time.Hour)}}},
+		{30 * 24 * time.Hour, []KeyAdvisory{
+			{"a", AdvisoryRotation, now.Add(-time.Hour)},
+			{"b", AdvisoryCertificate, notAfter},
+		}},
+	}
+	for i, tt := range tests {
+		have, err := s.Expiring(now, tt.d, deadlines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(have) != len(tt.want) {
+			t.Errorf("%d. Expiring\nhave %v\nwant %v", i, have, tt.want)
+			continue
+		}
+		for j := range have {
+			if have[j].Kid != tt.want[j].Kid || have[j].Reason != tt.want[j].Reason || !have[j].Deadline.Equal(tt.want[j].Deadline) {
+				t.Errorf("%d. Expiring\nhave %v\nwant %v", i, have, tt.want)
+			}
+		}
+	}
+	b.X5c = []string{"!"}
+	s = &KeySet{Keys: []JWK{b}}
+	_, err = s.Expiring(now, time.Hour, nil)
+	if err == nil {
+		t.Errorf("should reject invalid certificate")
+	}
+}
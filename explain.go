@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckStatus is the outcome of a single verification check.
+type CheckStatus int
+
+// Check statuses.
+const (
+	CheckPass CheckStatus = iota
+	CheckFail
+	CheckSkip
+)
+
+var checkStatusNames = []string{"pass", "fail", "skip"}
+
+// String implements the fmt.Stringer interface.
+func (s CheckStatus) String() string {
+	if s < 0 || int(s) >= len(checkStatusNames) {
+		return fmt.Sprintf("CheckStatus(%d)", int(s))
+	}
+	return checkStatusNames[s]
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s CheckStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Check is the result of a single verification check.
+type Check struct {
+	// Name identifies the check, such as "signature" or "exp".
+	Name string `json:"name"`
+
+	// Status is the outcome of the check.
+	Status CheckStatus `json:"status"`
+
+	// Reason describes why the check failed or was skipped.
+	Reason string `json:"reason,omitempty"`
+
+	// Err is the error that caused the check to fail.
+	Err error `json:"-"`
+}
+
+// Report is a structured description of every check performed
+// while verifying a token.
+type Report struct {
+	// Header is the decoded token header, if any.
+	Header map[string]interface{} `json:"header,omitempty"`
+
+	// Claims is the decoded token claims, if any. The claims are
+	// present even if the signature check did not pass.
+	Claims map[string]interface{} `json:"claims,omitempty"`
+
+	// Checks is the result of each check in evaluation order.
+	Checks []Check `json:"checks"`
+}
+
+// Valid returns true if no check failed.
+func (r *Report) Valid() bool {
+	return r.Err() == nil
+}
+
+// Err returns the error of the first failed check.
+func (r *Report) Err() error {
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			return c.Err
+		}
+	}
+	return nil
+}
+
+// Explain evaluates every check against jwt without stopping at the
+// first failure. It is intended for diagnostics; the decoded claims of
+// the report must not be trusted unless the report is valid.
+func (v *Verifier) Explain(ctx context.Context, jwt string) *Report {
+	r := &Report{}
+	t := v.run(ctx, jwt, func(c Check) bool {
+		r.Checks = append(r.Checks, c)
+		return true
+	})
+	r.Header = t.Header
+	r.Claims = t.Claims
+	return r
+}
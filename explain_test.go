@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	key := []byte("secret")
+	token := New(HS256)
+	token.Claims["iss"] = "https://other.example"
+	token.Claims["exp"] = expired
+	jwt, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier([]Signer{HS256}, KeyFunc(func(t *Token) ([]byte, error) {
+		return []byte("wrong"), nil
+	}), WithIssuer("https://issuer.example"))
+	var tests = []struct {
+		jwt  string
+		want map[string]CheckStatus
+		err  error
+	}{
+		{
+			jwt,
+			map[string]CheckStatus{
+				"format":    CheckPass,
+				"header":    CheckPass,
+				"typ":       CheckPass,
+				"alg":       CheckPass,
+				"key":       CheckPass,
+				"signature": CheckFail,
+				"claims":    CheckPass,
+				"exp":       CheckFail,
+				"nbf":       CheckSkip,
+				"required":  CheckSkip,
+				"iss":       CheckFail,
+				"aud":       CheckSkip,
+			},
+			ErrInvalidSignature,
+		},
+		{
+			"malformed",
+			map[string]CheckStatus{
+				"format":    CheckFail,
+				"header":    CheckSkip,
+				"signature": CheckSkip,
+				"claims":    CheckSkip,
+				"exp":       CheckSkip,
+			},
+			ErrMalformed,
+		},
+	}
+	for i, tt := range tests {
+		r := v.Explain(context.Background(), tt.jwt)
+		if r.Err() != tt.err {
+			t.Errorf("%d. Explain err\nhave %v\nwant %v", i, r.Err(), tt.err)
+		}
+		if len(r.Checks) != len(stages) {
+			t.Errorf("%d. Explain checks\nhave %d\nwant %d", i, len(r.Checks), len(stages))
+		}
+		for _, c := range r.Checks {
+			want, ok := tt.want[c.Name]
+			if ok && c.Status != want {
+				t.Errorf("%d. Explain %s\nhave %v\nwant %v", i, c.Name, c.Status, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// defaultPollInterval is the time between checks of a started FileKey
+// for changes.
+const defaultPollInterval = 10 * time.Second
+
+// FileKey is a KeyProvider backed by a key file containing a PEM or DER
+// encoded public key, a JSON Web Key or a JSON Web Key Set. Once
+// started, the file is polled and reloaded when it changes, so keys can
+// be rotated by replacing the file without restarting the server.
+type FileKey struct {
+	fsys     fs.FS
+	name     string
+	interval time.Duration
+	mu       sync.RWMutex
+	pem      []byte
+	keys     []JWK
+	modTime  time.Time
+	size     int64
+	err      error
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// FileKeyOption configures a FileKey.
+type FileKeyOption func(*FileKey)
+
+// WithPollInterval returns an option that sets the time between checks
+// of the file for changes once started. The default is 10 seconds.
+// A non-positive interval disables polling.
+func WithPollInterval(d time.Duration) FileKeyOption {
+	return func(k *FileKey) {
+		k.interval = d
+	}
+}
+
+// NewFileKey returns a new FileKey for the named file in fsys, such as
+// os.DirFS("/etc/jwt"). The file is loaded immediately and an error is
+// returned if it does not contain a valid key.
+func NewFileKey(fsys fs.FS, name string, opts ...FileKeyOption) (*FileKey, error) {
+	k := &FileKey{fsys: fsys, name: name, interval: defaultPollInterval}
+	for _, opt := range opts {
+		opt(k)
+	}
+	err := k.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload loads the file, replacing the current keys. The current keys
+// are kept if the file does not contain a valid key.
+func (k *FileKey) Reload() error {
+	info, err := fs.Stat(k.fsys, k.name)
+	if err != nil {
+		return err
+	}
+	b, err := LoadKey(k.fsys, k.name)
+	if err != nil {
+		return err
+	}
+	var keys []JWK
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		keys, err = parseKeyFile(b)
+		if err != nil {
+			return err
+		}
+		b = nil
+	} else if decodeKeyBlock(b) == nil {
+		return ErrKeyNotPEM
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.pem, k.keys = b, keys
+	k.modTime, k.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// parseKeyFile parses a JSON Web Key or JSON Web Key Set.
+func parseKeyFile(b []byte) ([]JWK, error) {
+	var doc struct {
+		Keys json.RawMessage `json:"keys"`
+	}
+	err := json.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Keys == nil {
+		jwk, err := ParseJWK(b)
+		if err != nil {
+			return nil, err
+		}
+		return []JWK{jwk.Public()}, nil
+	}
+	s, err := ParseKeySet(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Keys) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	keys := make([]JWK, len(s.Keys))
+	for i, jwk := range s.Keys {
+		keys[i] = jwk.Public()
+	}
+	return keys, nil
+}
+
+// Key implements the KeyProvider interface. A PEM encoded key is
+// returned for every token. Keys of a JSON Web Key Set are selected by
+// the kid and alg headers of the token.
+func (k *FileKey) Key(ctx context.Context, t *Token) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.pem != nil {
+		return k.pem, nil
+	}
+	jwk, ok := lookup(k.keys, t, false)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if !jwk.permits("verify") {
+		return nil, ErrKeyUsage
+	}
+	return jwk.Key()
+}
+
+// Start polls the file for changes until ctx is done or the key is
+// closed. It implements the Component interface.
+func (k *FileKey) Start(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.done != nil {
+		return ErrStarted
+	}
+	ctx, k.cancel = context.WithCancel(ctx)
+	k.done = make(chan struct{})
+	go k.poll(ctx, k.done)
+	return nil
+}
+
+// Close stops polling and waits for it to terminate. It implements
+// the Component interface.
+func (k *FileKey) Close() error {
+	k.mu.Lock()
+	cancel, done := k.cancel, k.done
+	k.cancel, k.done = nil, nil
+	k.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// poll reloads the file every interval if it has changed until ctx
+// is done. Failed reloads are retried at the next interval.
+func (k *FileKey) poll(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	if k.interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+	t := time.NewTicker(k.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		info, err := fs.Stat(k.fsys, k.name)
+		if err != nil {
+			continue
+		}
+		k.mu.RLock()
+		changed := !info.ModTime().Equal(k.modTime) || info.Size() != k.size
+		k.mu.RUnlock()
+		if changed {
+			err = k.Reload()
+			k.mu.Lock()
+			k.err = err
+			k.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(s string) {
+		// Write and rename so the poller never reads a partial file.
+		err := os.WriteFile(filepath.Join(dir, "tmp"), []byte(s), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Rename(filepath.Join(dir, "tmp"), filepath.Join(dir, "key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"kty":"oct","kid":"a","k":"YQ"}`)
+	k, err := NewFileKey(os.DirFS(dir), "key", WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = k.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	sign := func(kid, key string) string {
+		token := New(HS256)
+		token.Header["kid"] = kid
+		jwt, err := token.Sign([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jwt
+	}
+	v := NewVerifier([]Signer{HS256}, k)
+	_, err = v.Verify(context.Background(), sign("a", "a"))
+	if err != nil {
+		t.Errorf("Verify err\nhave %v\nwant %v", err, nil)
+	}
+	write(`{"keys":[{"kty":"oct","kid":"a","k":"YQ"},{"kty":"oct","kid":"bb","k":"Yg"}]}`)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err = v.Verify(context.Background(), sign("bb", "b"))
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Errorf("Verify after reload err\nhave %v\nwant %v", err, nil)
+	}
+	write(`not a key`)
+	err = k.Reload()
+	if err != ErrKeyNotPEM {
+		t.Errorf("Reload err\nhave %v\nwant %v", err, ErrKeyNotPEM)
+	}
+	_, err = v.Verify(context.Background(), sign("bb", "b"))
+	if err != nil {
+		t.Errorf("should keep keys after failed reload: %v", err)
+	}
+	_, err = NewFileKey(os.DirFS(dir), "missing")
+	if err == nil {
+		t.Errorf("should return error for missing file")
+	}
+}
+
+func TestFileKeyPollDisabled(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "key"), []byte(`{"kty":"oct","k":"YQ"}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewFileKey(os.DirFS(dir), "key", WithPollInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = k.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = k.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package flags evaluates feature flags carried in a claims block of a
+// verified token, allowing edge services to gate features without
+// consulting the experimentation service.
+//
+// The flags claim is an object mapping flag names to values. Boolean
+// values enable or disable a flag for every subject. Numeric values
+// between 0 and 100 enable a flag for that percentage of subjects,
+// bucketed by the sub claim so each subject sees a stable result.
+//
+//	{"sub": "user", "flags": {"new_ui": true, "checkout_v2": 25}}
+package flags
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/pnelson/jwt"
+)
+
+// Claim is the name of the claim carrying the flags.
+const Claim = "flags"
+
+// Flags is a set of feature flags for a subject.
+type Flags struct {
+	values  map[string]interface{}
+	subject string
+}
+
+// FromToken returns the flags of the verified token t. A token without
+// a flags claim has no flags, so every lookup returns its default.
+func FromToken(t *jwt.Token) Flags {
+	if t == nil {
+		return Flags{}
+	}
+	return FromClaims(t.Claims, Claim)
+}
+
+// FromClaims returns the flags in the named claim, bucketing
+// percentage flags by the sub claim.
+func FromClaims(claims map[string]interface{}, name string) Flags {
+	values, _ := claims[name].(map[string]interface{})
+	subject, _ := claims["sub"].(string)
+	return Flags{values: values, subject: subject}
+}
+
+// Bool returns the value of a boolean flag, or def if the flag is
+// absent or not a boolean.
+func (f Flags) Bool(name string, def bool) bool {
+	v, ok := f.values[name].(bool)
+	if !ok {
+		return def
+	}
+	return v
+}
+
+// Percentage returns the value of a percentage flag, or def if the
+// flag is absent or not a number between 0 and 100.
+func (f Flags) Percentage(name string, def float64) float64 {
+	v, ok := f.values[name].(float64)
+	if !ok || v < 0 || v > 100 {
+		return def
+	}
+	return v
+}
+
+// String returns the value of a string flag, such as an experiment
+// variant, or def if the flag is absent or not a string.
+func (f Flags) String(name string, def string) string {
+	v, ok := f.values[name].(string)
+	if !ok {
+		return def
+	}
+	return v
+}
+
+// Enabled returns true if the boolean flag is set, or the subject falls
+// within the percentage of a percentage flag. It returns def if the
+// flag is absent or has another type, or if a percentage flag is
+// evaluated for a token without a subject.
+func (f Flags) Enabled(name string, def bool) bool {
+	switch v := f.values[name].(type) {
+	case bool:
+		return v
+	case float64:
+		if v < 0 || v > 100 || f.subject == "" {
+			return def
+		}
+		return bucket(name, f.subject) < v
+	}
+	return def
+}
+
+// bucket returns the stable position, in [0, 100), of the subject for
+// the flag. Hashing the flag name with the subject ensures subjects are
+// bucketed independently for each flag.
+func bucket(name, subject string) float64 {
+	sum := sha256.Sum256([]byte(name + "\x00" + subject))
+	n := binary.BigEndian.Uint64(sum[:8])
+	return float64(n%10000) / 100
+}
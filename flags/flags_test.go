@@ -0,0 +1,79 @@
+package flags
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+func TestFlags(t *testing.T) {
+	token := &jwt.Token{Claims: map[string]interface{}{
+		"sub": "user",
+		"flags": map[string]interface{}{
+			"on":      true,
+			"off":     false,
+			"all":     100.0,
+			"none":    0.0,
+			"invalid": 150.0,
+			"variant": "b",
+		},
+	}}
+	f := FromToken(token)
+	var tests = []struct {
+		name    string
+		def     bool
+		enabled bool
+	}{
+		{"on", false, true},
+		{"off", true, false},
+		{"all", false, true},
+		{"none", true, false},
+		{"invalid", true, true},
+		{"invalid", false, false},
+		{"variant", true, true},
+		{"missing", true, true},
+		{"missing", false, false},
+	}
+	for i, tt := range tests {
+		enabled := f.Enabled(tt.name, tt.def)
+		if enabled != tt.enabled {
+			t.Errorf("%d. Enabled(%q)\nhave %v\nwant %v", i, tt.name, enabled, tt.enabled)
+		}
+	}
+	if !f.Bool("on", false) || f.Bool("all", false) {
+		t.Errorf("Bool should only read boolean flags")
+	}
+	if f.Percentage("all", 0) != 100 || f.Percentage("invalid", 5) != 5 {
+		t.Errorf("Percentage should only read valid percentages")
+	}
+	if f.String("variant", "a") != "b" || f.String("on", "a") != "a" {
+		t.Errorf("String should only read string flags")
+	}
+	if FromToken(nil).Enabled("on", false) {
+		t.Errorf("should return default without a token")
+	}
+}
+
+func TestFlagsPercentage(t *testing.T) {
+	var n int
+	for i := 0; i < 1000; i++ {
+		f := FromClaims(map[string]interface{}{
+			"sub":   fmt.Sprintf("user%d", i),
+			"flags": map[string]interface{}{"rollout": 25.0},
+		}, Claim)
+		if f.Enabled("rollout", false) != f.Enabled("rollout", false) {
+			t.Fatalf("should be stable for subject")
+		}
+		if f.Enabled("rollout", false) {
+			n++
+		}
+	}
+	if n < 200 || n > 300 {
+		t.Errorf("enabled subjects\nhave %d\nwant about 250", n)
+	}
+	f := FromClaims(map[string]interface{}{"flags": map[string]interface{}{"rollout": 25.0}}, Claim)
+	if f.Enabled("rollout", false) {
+		t.Errorf("should return default without a subject")
+	}
+}
@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+// ErrKeyAlgorithm is returned when a key cannot be generated for an
+// algorithm.
+var ErrKeyAlgorithm = errors.New("jwt: cannot generate key for algorithm")
+
+// MinRSAKeySize is the minimum size in bits of generated RSA keys.
+const MinRSAKeySize = 2048
+
+// GeneratedKey is a newly generated signing key in each of the forms
+// accepted by the package.
+type GeneratedKey struct {
+	// Private and Public are the native keys: []byte for HMAC, or an
+	// *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey and its
+	// public key. For HMAC both are the secret.
+	Private interface{}
+	Public  interface{}
+
+	// PrivatePEM and PublicPEM are the keys encoded as accepted by
+	// Sign and Verify. For HMAC both are the raw secret.
+	PrivatePEM []byte
+	PublicPEM  []byte
+
+	// JWK is the private JSON Web Key. The kid is the thumbprint of
+	// the key and the alg and use parameters are set. Use JWK.Public
+	// to publish the key.
+	JWK JWK
+}
+
+// GenerateKey returns a new random key for the alg. HMAC secrets are
+// the size of the hash output, RSA keys are 2048 bits, ECDSA keys are
+// on the curve of the alg and EdDSA keys are Ed25519.
+func GenerateKey(alg string) (*GeneratedKey, error) {
+	var key interface{}
+	var err error
+	switch alg {
+	case "HS256", "HS384", "HS512":
+		secret := make([]byte, hmacSecretSize(alg))
+		_, err = rand.Read(secret)
+		key = secret
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		return GenerateRSAKey(alg, MinRSAKeySize)
+	case "ES256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ES512":
+		key, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrKeyAlgorithm, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newGeneratedKey(key, alg)
+}
+
+// GenerateRSAKey returns a new random RSA key of the size in bits for
+// the RS or PS alg, such as 3072 or 4096. The size must be at least
+// MinRSAKeySize.
+func GenerateRSAKey(alg string, bits int) (*GeneratedKey, error) {
+	if len(alg) != 5 || (alg[:2] != "RS" && alg[:2] != "PS") || bits < MinRSAKeySize {
+		return nil, fmt.Errorf("%w: %s with %d bits", ErrKeyAlgorithm, alg, bits)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+	return newGeneratedKey(key, alg)
+}
+
+// hmacSecretSize returns the size in bytes of the hash of the HMAC alg.
+func hmacSecretSize(alg string) int {
+	switch alg {
+	case "HS384":
+		return 48
+	case "HS512":
+		return 64
+	}
+	return 32
+}
+
+// newGeneratedKey returns the forms of the native private key.
+func newGeneratedKey(key interface{}, alg string) (*GeneratedKey, error) {
+	k, err := NewJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	k.Alg, k.Use = alg, "sig"
+	k.Kid, err = k.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	g := &GeneratedKey{Private: key, JWK: *k}
+	g.PrivatePEM, err = k.Key()
+	if err != nil {
+		return nil, err
+	}
+	g.PublicPEM, err = k.Public().Key()
+	if err != nil {
+		return nil, err
+	}
+	g.Public, err = k.Public().NativeKey()
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	var tests = []struct {
+		signer Signer
+	}{
+		{HS256},
+		{HS512},
+		{RS256},
+		{PS384},
+		{ES256},
+		{ES384},
+		{ES512},
+		{EdDSA},
+	}
+	for i, tt := range tests {
+		alg := tt.signer.String()
+		k, err := GenerateKey(alg)
+		if err != nil {
+			t.Errorf("%d. GenerateKey err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if k.JWK.Alg != alg || k.JWK.Kid == "" || !k.JWK.IsPrivate() && k.JWK.Kty != "oct" {
+			t.Errorf("%d. GenerateKey jwk\nhave %+v", i, k.JWK)
+		}
+		jwt, err := New(tt.signer).Sign(k.PrivatePEM)
+		if err != nil {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		_, err = Parse(tt.signer, jwt, k.PublicPEM)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+		}
+		_, err = ParseWithKey(tt.signer, jwt, k.Public)
+		if err != nil {
+			t.Errorf("%d. ParseWithKey err\nhave %v\nwant %v", i, err, nil)
+		}
+		jwt, err = New(tt.signer).SignKey(k.Private)
+		if err != nil {
+			t.Errorf("%d. SignKey err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		_, err = Parse(tt.signer, jwt, k.PublicPEM)
+		if err != nil {
+			t.Errorf("%d. Parse native err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	k, err := GenerateKey("HS384")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.PrivatePEM) != 48 {
+		t.Errorf("HMAC secret size\nhave %d\nwant %d", len(k.PrivatePEM), 48)
+	}
+	_, err = GenerateKey("none")
+	if !errors.Is(err, ErrKeyAlgorithm) {
+		t.Errorf("GenerateKey err\nhave %v\nwant %v", err, ErrKeyAlgorithm)
+	}
+	_, err = GenerateRSAKey("RS256", 1024)
+	if !errors.Is(err, ErrKeyAlgorithm) {
+		t.Errorf("GenerateRSAKey err\nhave %v\nwant %v", err, ErrKeyAlgorithm)
+	}
+}
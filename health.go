@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Status is the health of a background component for operators.
+type Status struct {
+	// Name identifies the component, such as the URL of a key set.
+	Name string `json:"name"`
+
+	// Healthy is false if the component has no keys to serve.
+	Healthy bool `json:"healthy"`
+
+	// Error is the most recent error of the component, if any.
+	Error string `json:"error,omitempty"`
+
+	// Advisories lists keys nearing their rotation deadline or the
+	// expiry of their certificate.
+	Advisories []KeyAdvisory `json:"advisories,omitempty"`
+}
+
+// HealthReporter is implemented by components that report their health.
+type HealthReporter interface {
+	// Health returns the status of the component with advisories for
+	// keys whose rotation deadline or certificate expiry is within d.
+	// The deadlines map kid values to rotation deadlines and may be nil.
+	Health(d time.Duration, deadlines map[string]time.Time) Status
+}
+
+// Health returns the status of each component implementing
+// HealthReporter, with advisories as for KeySet.Expiring.
+func (r *Runtime) Health(d time.Duration, deadlines map[string]time.Time) []Status {
+	r.mu.Lock()
+	components := append([]Component(nil), r.components...)
+	r.mu.Unlock()
+	var rv []Status
+	for _, c := range components {
+		if h, ok := c.(HealthReporter); ok {
+			rv = append(rv, h.Health(d, deadlines))
+		}
+	}
+	return rv
+}
+
+// HealthHandler returns a handler serving the Health of the runtime as
+// JSON. It responds with 503 Service Unavailable if any component is
+// unhealthy. Advisories do not make a component unhealthy.
+func (r *Runtime) HealthHandler(d time.Duration, deadlines map[string]time.Time) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		statuses := r.Health(d, deadlines)
+		code := http.StatusOK
+		for _, s := range statuses {
+			if !s.Healthy {
+				code = http.StatusServiceUnavailable
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(struct {
+			Components []Status `json:"components"`
+		}{statuses})
+	})
+}
+
+// health returns the status of a component serving keys.
+func health(name string, keys []JWK, err error, d time.Duration, deadlines map[string]time.Time) Status {
+	s := Status{Name: name, Healthy: len(keys) > 0}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	advisories, err := (&KeySet{Keys: keys}).Expiring(time.Now(), d, deadlines)
+	if err != nil && s.Error == "" {
+		s.Error = err.Error()
+	}
+	s.Advisories = advisories
+	return s
+}
+
+// Health implements the HealthReporter interface. The key set is
+// unhealthy until a document with keys has been fetched.
+func (s *RemoteKeySet) Health(d time.Duration, deadlines map[string]time.Time) Status {
+	s.mu.Lock()
+	keys, err := s.keys, s.err
+	s.mu.Unlock()
+	return health(s.url, keys, err, d, deadlines)
+}
+
+// Health implements the HealthReporter interface. Error reports the
+// most recent failed reload, while the previous keys remain in use.
+func (k *FileKey) Health(d time.Duration, deadlines map[string]time.Time) Status {
+	k.mu.RLock()
+	keys, pem, err := k.keys, k.pem, k.err
+	k.mu.RUnlock()
+	s := health(k.name, keys, err, d, deadlines)
+	s.Healthy = s.Healthy || pem != nil
+	return s
+}
@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRuntimeHealth(t *testing.T) {
+	now := time.Now()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: now, NotAfter: now.Add(10 * 24 * time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewJWK(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Kid = "a"
+	k.X5c = []string{base64.StdEncoding.EncodeToString(der)}
+	b, err := json.Marshal(KeySet{Keys: []JWK{*k}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(b)
+	}))
+	defer srv.Close()
+	keys := NewRemoteKeySet(srv.URL)
+	rt := NewRuntime(keys)
+	err = rt.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+	var tests = []struct {
+		d          time.Duration
+		deadlines  map[string]time.Time
+		advisories []string
+	}{
+		{24 * time.Hour, nil, nil},
+		{30 * 24 * time.Hour, nil, []string{AdvisoryCertificate}},
+		{24 * time.Hour, map[string]time.Time{"a": now}, []string{AdvisoryRotation}},
+	}
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		rt.HealthHandler(tt.d, tt.deadlines).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var body struct {
+			Components []Status `json:"components"`
+		}
+		err = json.Unmarshal(w.Body.Bytes(), &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || len(body.Components) != 1 || !body.Components[0].Healthy {
+			t.Errorf("%d. unexpected health %d %+v", i, w.Code, body.Components)
+			continue
+		}
+		var reasons []string
+		for _, a := range body.Components[0].Advisories {
+			if a.Kid != "a" {
+				t.Errorf("%d. advisory kid\nhave %q\nwant %q", i, a.Kid, "a")
+			}
+			reasons = append(reasons, a.Reason)
+		}
+		if len(reasons) != len(tt.advisories) || (len(reasons) > 0 && reasons[0] != tt.advisories[0]) {
+			t.Errorf("%d. advisories\nhave %v\nwant %v", i, reasons, tt.advisories)
+		}
+	}
+	down := NewRemoteKeySet(srv.URL + "/down")
+	down.Key(context.Background(), New(ES256))
+	w := httptest.NewRecorder()
+	NewRuntime(keys, down).HealthHandler(0, nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status\nhave %d\nwant %d", w.Code, http.StatusServiceUnavailable)
+	}
+	s := down.Health(0, nil)
+	if s.Healthy || s.Error == "" {
+		t.Errorf("unexpected health %+v", s)
+	}
+}
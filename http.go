@@ -0,0 +1,23 @@
+package jwt
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultHTTPTimeout is the request timeout of HTTP clients created by
+// this package and its subpackages.
+const DefaultHTTPTimeout = 30 * time.Second
+
+// DefaultHTTPClient is the HTTP client used by remote fetchers, such as
+// RemoteKeySet, when no client is provided. Unlike http.DefaultClient
+// it has a timeout, so an unresponsive server cannot block verification
+// indefinitely.
+var DefaultHTTPClient = &http.Client{Timeout: DefaultHTTPTimeout}
+
+// NewHTTPClient returns an HTTP client using the transport rt with the
+// default timeout, adapting proxy, mTLS or tracing transports for use
+// with remote fetchers. A nil rt uses http.DefaultTransport.
+func NewHTTPClient(rt http.RoundTripper) *http.Client {
+	return &http.Client{Transport: rt, Timeout: DefaultHTTPTimeout}
+}
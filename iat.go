@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Issued at errors.
+var (
+	ErrClaimIssuedAt = errors.New("jwt: current time must be after iat")
+	ErrClaimMaxAge   = errors.New("jwt: token is older than the maximum age")
+)
+
+// WithMaxAge returns an option that rejects tokens issued more than d
+// ago with ErrClaimMaxAge, regardless of exp. Tokens without an iat
+// claim are rejected with ErrClaimRequired. The leeway applies.
+func WithMaxAge(d time.Duration) Option {
+	return func(v *Verifier) {
+		v.maxAge = d
+	}
+}
+
+// checkIssuedAt rejects tokens issued in the future, beyond the leeway,
+// and tokens older than the maximum age.
+func (v *Verifier) checkIssuedAt(ctx context.Context, s *verification) error {
+	iat, ok, err := v.timestamp(s, ClaimIssuedAt)
+	if err != nil {
+		return err
+	}
+	if !ok && v.maxAge > 0 {
+		return ErrClaimRequired
+	}
+	if !ok {
+		return skipped("iat claim is not present")
+	}
+	if v.notYetValid(iat) {
+		return ErrClaimIssuedAt
+	}
+	if v.maxAge > 0 && v.expired(iat.Add(v.maxAge)) {
+		return ErrClaimMaxAge
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssuedAt(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	now := time.Unix(1700000000, 0)
+	var tests = []struct {
+		payload string
+		opts    []Option
+		err     error
+	}{
+		{`{}`, nil, nil},
+		{`{"iat":1700000000}`, nil, nil},
+		{`{"iat":1699990000}`, nil, nil},
+		{`{"iat":1700000001}`, nil, ErrClaimIssuedAt},
+		{`{"iat":1700000030}`, []Option{WithLeeway(30 * time.Second)}, nil},
+		{`{"iat":1700000031}`, []Option{WithLeeway(30 * time.Second)}, ErrClaimIssuedAt},
+		{`{"iat":"1700000000"}`, nil, ErrClaimType},
+		{`{}`, []Option{WithMaxAge(time.Hour)}, ErrClaimRequired},
+		{`{"iat":1699996400,"exp":9999999999}`, []Option{WithMaxAge(time.Hour)}, nil},
+		{`{"iat":1699996399,"exp":9999999999}`, []Option{WithMaxAge(time.Hour)}, ErrClaimMaxAge},
+		{`{"iat":1699996370}`, []Option{WithMaxAge(time.Hour), WithLeeway(30 * time.Second)}, nil},
+		{`{"iat":1699996369}`, []Option{WithMaxAge(time.Hour), WithLeeway(30 * time.Second)}, ErrClaimMaxAge},
+		{`{"iat":1699999999.5}`, []Option{WithMaxAge(500 * time.Millisecond), WithSubsecondPrecision()}, nil},
+		{`{"iat":1699999999.4}`, []Option{WithMaxAge(500 * time.Millisecond), WithSubsecondPrecision()}, ErrClaimMaxAge},
+	}
+	for i, tt := range tests {
+		opts := append([]Option{WithEvaluationTime(now)}, tt.opts...)
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package jwt
+
+import "unique"
+
+// WithInterning returns an option that replaces the iss and aud claims
+// of verified tokens, and the accepted issuers and audiences, with
+// canonical copies. Tokens retained after verification, such as in a
+// session cache, then share a single copy of each issuer and audience,
+// and equal values compare without inspecting their bytes.
+//
+// Interning reduces retained memory rather than allocations, costing
+// an allocation per interned claim. The decoder of Go releases built
+// with the jsonv2 experiment already shares repeated strings through
+// a small cache; interning guarantees sharing regardless of decoder.
+func WithInterning() Option {
+	return func(v *Verifier) {
+		v.intern = true
+	}
+}
+
+// intern returns the canonical copy of s.
+func intern(s string) string {
+	return unique.Make(s).Value()
+}
+
+// internStrings replaces each value of s with its canonical copy.
+func internStrings(s []string) {
+	for i := range s {
+		s[i] = intern(s[i])
+	}
+}
+
+// internClaims replaces the iss and aud claims with canonical copies.
+func internClaims(c map[string]interface{}) {
+	if iss, ok := c[ClaimIssuer].(string); ok {
+		c[ClaimIssuer] = intern(iss)
+	}
+	switch aud := c[ClaimAudience].(type) {
+	case string:
+		c[ClaimAudience] = intern(aud)
+	case []interface{}:
+		for i, v := range aud {
+			if s, ok := v.(string); ok {
+				aud[i] = intern(s)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"unsafe"
+)
+
+func TestWithInterning(t *testing.T) {
+	v := NewVerifier([]Signer{HS256}, StaticKey([]byte("secret")), WithInterning(), WithIssuer("https://issuer.example"), WithAudience("api"))
+	claims := []map[string]interface{}{
+		{"iss": "https://issuer.example", "aud": "api"},
+		{"iss": "https://issuer.example", "aud": []string{"other", "api"}},
+	}
+	var tokens []*Token
+	for i, c := range claims {
+		token := New(HS256)
+		token.Claims = c
+		jwt, err := token.Sign([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := v.Verify(context.Background(), jwt)
+		if err != nil {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		tokens = append(tokens, parsed)
+	}
+	if len(tokens) != 2 {
+		t.Fatal("should verify tokens")
+	}
+	data := func(v interface{}) *byte {
+		return unsafe.StringData(v.(string))
+	}
+	if data(tokens[0].Claims["iss"]) != data(tokens[1].Claims["iss"]) {
+		t.Errorf("should share iss")
+	}
+	if data(tokens[0].Claims["aud"]) != data(tokens[1].Claims["aud"].([]interface{})[1]) {
+		t.Errorf("should share aud")
+	}
+	if data(tokens[0].Claims["iss"]) != data(v.issuers[0]) {
+		t.Errorf("should share accepted issuer")
+	}
+}
@@ -0,0 +1,26 @@
+package jwt
+
+import (
+	"strings"
+)
+
+// ParseInto validates the provided jwt like Parse and unmarshals its
+// claims into a new T, such as a struct with NumericDate and Audience
+// fields. The claims are decoded from the verified claims segment, so
+// T sees the claims exactly as the issuer encoded them.
+func ParseInto[T any](s Signer, jwt string, key []byte, opts ...Option) (*T, error) {
+	_, err := Parse(s, jwt, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decode(strings.Split(jwt, sep)[1])
+	if err != nil {
+		return nil, err
+	}
+	var v T
+	err = unmarshalSegment("claims", b, &v)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
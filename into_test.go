@@ -0,0 +1,45 @@
+package jwt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseInto(t *testing.T) {
+	type claims struct {
+		Subject   string      `json:"sub"`
+		Audience  Audience    `json:"aud"`
+		ExpiresAt NumericDate `json:"exp"`
+		Roles     []string    `json:"roles"`
+	}
+	key := []byte("secret")
+	exp := time.Now().Add(time.Hour).Unix()
+	token := New(HS256)
+	token.Claims = map[string]interface{}{"sub": "a", "aud": "api", "exp": exp, "roles": []string{"admin"}}
+	jwt, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := ParseInto[claims](HS256, jwt, key, WithAudience("api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &claims{"a", Audience{"api"}, NumericDate(exp), []string{"admin"}}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %+v\nwant %+v", have, want)
+	}
+	_, err = ParseInto[claims](HS256, jwt, []byte("other"))
+	if err != ErrInvalidSignature {
+		t.Fatalf("ParseInto err\nhave %v\nwant %v", err, ErrInvalidSignature)
+	}
+	token.Claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	jwt, err = token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseInto[claims](HS256, jwt, key)
+	if err != ErrClaimExpired {
+		t.Fatalf("ParseInto err\nhave %v\nwant %v", err, ErrClaimExpired)
+	}
+}
@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClaimJTI is returned when the jti claim does not have the
+// required format.
+var ErrClaimJTI = errors.New("jwt: jti does not have the required format")
+
+// JTIFormat reports whether a jti claim value has a required format.
+type JTIFormat func(jti string) bool
+
+// JTIUUID requires the jti claim to be a UUID in its canonical textual
+// form, such as "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+//
+// See RFC 9562 Section 4.
+func JTIUUID(jti string) bool {
+	if len(jti) != 36 {
+		return false
+	}
+	for i := 0; i < len(jti); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if jti[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(jti[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// JTIMinLength requires the jti claim to be at least n bytes long,
+// ensuring identifiers have enough entropy to be unique.
+func JTIMinLength(n int) JTIFormat {
+	return func(jti string) bool {
+		return len(jti) >= n
+	}
+}
+
+// WithJTI returns an option that requires a non-empty jti claim having
+// every format, for use with replay prevention. Tokens without a jti
+// claim are rejected with ErrClaimRequired and tokens with a malformed
+// jti claim with ErrClaimJTI.
+func WithJTI(formats ...JTIFormat) Option {
+	return func(v *Verifier) {
+		v.jtiRequired = true
+		v.jtiFormats = append(v.jtiFormats, formats...)
+	}
+}
+
+func (v *Verifier) checkJTI(ctx context.Context, s *verification) error {
+	if !v.jtiRequired {
+		return skipped("jti claim is not required")
+	}
+	c, ok := s.token.Claims[ClaimJWTID]
+	if !ok {
+		return ErrClaimRequired
+	}
+	jti, ok := c.(string)
+	if !ok {
+		return ErrClaimType
+	}
+	if jti == "" {
+		return ErrClaimJTI
+	}
+	for _, valid := range v.jtiFormats {
+		if !valid(jti) {
+			return ErrClaimJTI
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package jwt
+
+import (
+	"testing"
+)
+
+func TestWithJTI(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		payload string
+		opts    []Option
+		err     error
+	}{
+		{`{}`, nil, nil},
+		{`{"jti":"a"}`, []Option{WithJTI()}, nil},
+		{`{}`, []Option{WithJTI()}, ErrClaimRequired},
+		{`{"jti":""}`, []Option{WithJTI()}, ErrClaimJTI},
+		{`{"jti":1}`, []Option{WithJTI()}, ErrClaimType},
+		{`{"jti":"f81d4fae-7dec-11d0-a765-00a0c91e6bf6"}`, []Option{WithJTI(JTIUUID)}, nil},
+		{`{"jti":"F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6"}`, []Option{WithJTI(JTIUUID)}, nil},
+		{`{"jti":"f81d4fae7dec11d0a76500a0c91e6bf6"}`, []Option{WithJTI(JTIUUID)}, ErrClaimJTI},
+		{`{"jti":"g81d4fae-7dec-11d0-a765-00a0c91e6bf6"}`, []Option{WithJTI(JTIUUID)}, ErrClaimJTI},
+		{`{"jti":"0123456789abcdef"}`, []Option{WithJTI(JTIMinLength(16))}, nil},
+		{`{"jti":"0123456789abcde"}`, []Option{WithJTI(JTIMinLength(16))}, ErrClaimJTI},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), tt.opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK errors.
+var (
+	ErrInvalidJWK = errors.New("jwt: invalid jwk")
+	ErrKeyUsage   = errors.New("jwt: key is not permitted for this operation")
+)
+
+// JWK represents a JSON Web Key. The private key parameters are only
+// present in private keys.
+//
+// See RFC 7517 and RFC 7518 Section 6.
+type JWK struct {
+	Kty    string   `json:"kty"`
+	Kid    string   `json:"kid,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+
+	// X5c is the X.509 certificate chain of the key, each certificate
+	// base64-encoded DER with the key certificate first.
+	X5c []string `json:"x5c,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// RSA, EC and OKP private keys
+	D string `json:"d,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// ParseJWK parses a single JSON Web Key.
+func ParseJWK(b []byte) (*JWK, error) {
+	var k JWK
+	err := json.Unmarshal(b, &k)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	_, err = k.NativeKey()
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// NewJWK returns the JSON Web Key representation of the native key,
+// which may be an RSA, ECDSA or Ed25519 public or private key or a
+// []byte symmetric key. Use Public to strip private key parameters
+// before publishing the key.
+func NewJWK(key interface{}) (*JWK, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return &JWK{Kty: "RSA", N: encode(key.N.Bytes()), E: encode(big.NewInt(int64(key.E)).Bytes())}, nil
+	case *rsa.PrivateKey:
+		if len(key.Primes) != 2 {
+			return nil, ErrKeyMultiPrime
+		}
+		k, _ := NewJWK(&key.PublicKey)
+		key.Precompute()
+		k.D = encode(key.D.Bytes())
+		k.P = encode(key.Primes[0].Bytes())
+		k.Q = encode(key.Primes[1].Bytes())
+		k.DP = encode(key.Precomputed.Dp.Bytes())
+		k.DQ = encode(key.Precomputed.Dq.Bytes())
+		k.QI = encode(key.Precomputed.Qinv.Bytes())
+		return k, nil
+	case *ecdsa.PublicKey:
+		crv, size, err := curveName(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{Kty: "EC", Crv: crv, X: encode(key.X.FillBytes(make([]byte, size))), Y: encode(key.Y.FillBytes(make([]byte, size)))}, nil
+	case *ecdsa.PrivateKey:
+		k, err := NewJWK(&key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		_, size, _ := curveName(key.Curve)
+		k.D = encode(key.D.FillBytes(make([]byte, size)))
+		return k, nil
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, keyTypeError(key)
+		}
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: encode(key)}, nil
+	case ed25519.PrivateKey:
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, keyTypeError(key)
+		}
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: encode(key.Public().(ed25519.PublicKey)), D: encode(key.Seed())}, nil
+	case []byte:
+		return &JWK{Kty: "oct", K: encode(key)}, nil
+	}
+	return nil, keyTypeError(key)
+}
+
+// MarshalJWK returns the JSON encoding of the native key as a JSON Web
+// Key with the kid, alg and use parameters, which are omitted if empty.
+//
+// See NewJWK.
+func MarshalJWK(key interface{}, kid, alg, use string) ([]byte, error) {
+	k, err := NewJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	k.Kid = kid
+	k.Alg = alg
+	k.Use = use
+	return json.Marshal(k)
+}
+
+// IsPrivate returns true if the key contains private key parameters.
+func (k JWK) IsPrivate() bool {
+	return k.D != ""
+}
+
+// Public returns the key without its private key parameters.
+func (k JWK) Public() JWK {
+	k.D, k.P, k.Q, k.DP, k.DQ, k.QI = "", "", "", "", "", ""
+	return k
+}
+
+// Key returns the key material in the form expected by the signers.
+// Asymmetric keys are returned PEM-encoded and symmetric keys are
+// returned raw. Private keys are encoded as PKCS #1 for RSA, SEC 1
+// for EC and PKCS #8 for OKP keys.
+func (k JWK) Key() ([]byte, error) {
+	key, err := k.NativeKey()
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case []byte:
+		return key, nil
+	case *rsa.PrivateKey:
+		der := x509.MarshalPKCS1PrivateKey(key)
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der}), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+	return encodePublicKey(key)
+}
+
+// NativeKey returns the key as a native crypto type suitable for
+// SignKey and ParseWithKey: *rsa.PublicKey, *rsa.PrivateKey,
+// *ecdsa.PublicKey, *ecdsa.PrivateKey, ed25519.PublicKey,
+// ed25519.PrivateKey or []byte for symmetric keys.
+func (k JWK) NativeKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		pub, err := k.rsaPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if !k.IsPrivate() {
+			return pub, nil
+		}
+		return k.rsaPrivateKey(pub)
+	case "EC":
+		pub, err := k.ecdsaPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if !k.IsPrivate() {
+			return pub, nil
+		}
+		return k.ecdsaPrivateKey(pub)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, ErrInvalidJWK
+		}
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, ErrInvalidJWK
+		}
+		if !k.IsPrivate() {
+			return ed25519.PublicKey(x), nil
+		}
+		d, err := decode(k.D)
+		if err != nil || len(d) != ed25519.SeedSize {
+			return nil, ErrInvalidJWK
+		}
+		priv := ed25519.NewKeyFromSeed(d)
+		if !bytes.Equal(priv.Public().(ed25519.PublicKey), x) {
+			return nil, ErrInvalidJWK
+		}
+		return priv, nil
+	case "oct":
+		if k.K == "" {
+			return nil, ErrInvalidJWK
+		}
+		b, err := decode(k.K)
+		if err != nil {
+			return nil, ErrInvalidJWK
+		}
+		return b, nil
+	}
+	return nil, ErrInvalidJWK
+}
+
+// permits returns true if the use and key_ops parameters allow the
+// key to be used for the operation, such as "verify" or "encrypt".
+// Keys without these parameters are permitted for any operation.
+//
+// See RFC 7517 Sections 4.2 and 4.3.
+func (k JWK) permits(op string) bool {
+	use := "sig"
+	if op == "encrypt" || op == "decrypt" || op == "wrapKey" || op == "unwrapKey" {
+		use = "enc"
+	}
+	if k.Use != "" && k.Use != use {
+		return false
+	}
+	if len(k.KeyOps) > 0 && !contains(k.KeyOps, op) {
+		return false
+	}
+	return true
+}
+
+// rsaPublicKey decodes the RSA public key parameters.
+func (k JWK) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, ErrInvalidJWK
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// rsaPrivateKey decodes the RSA private key parameters of pub.
+// Multi-prime keys are not supported.
+func (k JWK) rsaPrivateKey(pub *rsa.PublicKey) (*rsa.PrivateKey, error) {
+	d, err := decodeInt(k.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeInt(k.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeInt(k.Q)
+	if err != nil {
+		return nil, err
+	}
+	priv := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	err = priv.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	priv.Precompute()
+	return priv, nil
+}
+
+// ecdsaPublicKey decodes the ECDSA public key parameters.
+func (k JWK) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	curve, err := curveByName(k.Crv)
+	if err != nil {
+		return nil, err
+	}
+	x, err := decodeInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	pub := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	_, err = pub.ECDH()
+	if err != nil {
+		return nil, ErrInvalidJWK
+	}
+	return pub, nil
+}
+
+// ecdsaPrivateKey decodes the ECDSA private key parameters of pub.
+func (k JWK) ecdsaPrivateKey(pub *ecdsa.PublicKey) (*ecdsa.PrivateKey, error) {
+	d, err := decodeInt(k.D)
+	if err != nil {
+		return nil, err
+	}
+	priv := &ecdsa.PrivateKey{PublicKey: *pub, D: d}
+	ecdhPriv, err := priv.ECDH()
+	if err != nil {
+		return nil, ErrInvalidJWK
+	}
+	ecdhPub, err := pub.ECDH()
+	if err != nil || !ecdhPriv.PublicKey().Equal(ecdhPub) {
+		return nil, ErrInvalidJWK
+	}
+	return priv, nil
+}
+
+// curveByName returns the elliptic curve for the JWK crv parameter.
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	}
+	return nil, ErrInvalidJWK
+}
+
+// curveName returns the JWK crv parameter and coordinate size in bytes
+// of the elliptic curve.
+func curveName(c elliptic.Curve) (string, int, error) {
+	switch c {
+	case elliptic.P256():
+		return "P-256", 32, nil
+	case elliptic.P384():
+		return "P-384", 48, nil
+	case elliptic.P521():
+		return "P-521", 66, nil
+	}
+	return "", 0, ErrInvalidJWK
+}
+
+// decodeInt decodes a base64url-encoded big-endian unsigned integer.
+func decodeInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, ErrInvalidJWK
+	}
+	b, err := decode(s)
+	if err != nil {
+		return nil, ErrInvalidJWK
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encodePublicKey encodes a RSA or ECDSA public key to PEM format.
+func encodePublicKey(pub interface{}) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	block := &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	return pem.EncodeToMemory(block), nil
+}
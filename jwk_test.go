@@ -0,0 +1,160 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseJWK(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := func(i *big.Int) string { return encode(i.Bytes()) }
+	rsaJWK := JWK{
+		Kty: "RSA",
+		N:   n(rsaKey.N),
+		E:   n(big.NewInt(int64(rsaKey.E))),
+		D:   n(rsaKey.D),
+		P:   n(rsaKey.Primes[0]),
+		Q:   n(rsaKey.Primes[1]),
+	}
+	ecJWK := JWK{Kty: "EC", Crv: "P-256", X: n(ecKey.X), Y: n(ecKey.Y), D: n(ecKey.D)}
+	edJWK := JWK{Kty: "OKP", Crv: "Ed25519", X: encode(edPub), D: encode(edKey.Seed())}
+	mismatched := ecJWK
+	mismatched.D = n(otherKey.D)
+	var tests = []struct {
+		jwk    JWK
+		signer Signer
+		err    error
+	}{
+		{rsaJWK, RS256, nil},
+		{ecJWK, ES256, nil},
+		{edJWK, EdDSA, nil},
+		{JWK{Kty: "oct", K: encode([]byte("secret"))}, HS256, nil},
+		{mismatched, nil, ErrInvalidJWK},
+		{JWK{Kty: "RSA", N: rsaJWK.N, E: rsaJWK.E, D: rsaJWK.D}, nil, ErrInvalidJWK},
+		{JWK{Kty: "unknown"}, nil, ErrInvalidJWK},
+	}
+	for i, tt := range tests {
+		b, err := json.Marshal(tt.jwk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, err := ParseJWK(b)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. ParseJWK err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		priv, err := k.Key()
+		if err != nil {
+			t.Errorf("%d. Key err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		pub, err := k.Public().Key()
+		if err != nil {
+			t.Errorf("%d. Public Key err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		sig, err := tt.signer.Sign([]byte("foo"), priv)
+		if err != nil {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		err = tt.signer.Verify([]byte("foo"), sig, pub)
+		if err != nil {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	_, err = ParseJWK([]byte("{"))
+	if !errors.Is(err, ErrInvalidJWK) {
+		t.Errorf("ParseJWK err\nhave %v\nwant %v", err, ErrInvalidJWK)
+	}
+}
+
+func TestMarshalJWK(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		priv   interface{}
+		pub    interface{}
+		signer Signer
+	}{
+		{rsaKey, &rsaKey.PublicKey, PS256},
+		{ecKey, &ecKey.PublicKey, ES512},
+		{edKey, edKey.Public(), EdDSA},
+	}
+	for i, tt := range tests {
+		b, err := MarshalJWK(tt.priv, "kid", tt.signer.String(), "sig")
+		if err != nil {
+			t.Errorf("%d. MarshalJWK err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		k, err := ParseJWK(b)
+		if err != nil {
+			t.Errorf("%d. ParseJWK err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if k.Kid != "kid" || k.Alg != tt.signer.String() || k.Use != "sig" || !k.IsPrivate() {
+			t.Errorf("%d. ParseJWK unexpected parameters %+v", i, k)
+		}
+		pub, err := NewJWK(tt.pub)
+		if err != nil {
+			t.Errorf("%d. NewJWK err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		want := k.Public()
+		if pub.N != want.N || pub.E != want.E || pub.Crv != want.Crv || pub.X != want.X || pub.Y != want.Y || pub.IsPrivate() {
+			t.Errorf("%d. NewJWK\nhave %+v\nwant %+v", i, *pub, want)
+		}
+		priv, err := k.NativeKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := signKey(tt.signer, []byte("foo"), priv)
+		if err != nil {
+			t.Errorf("%d. SignKey err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		err = verifyKey(tt.signer, []byte("foo"), sig, tt.pub)
+		if err != nil {
+			t.Errorf("%d. VerifyKey err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	_, err = MarshalJWK("key", "", "", "")
+	if !errors.Is(err, ErrKeyType) {
+		t.Errorf("MarshalJWK err\nhave %v\nwant %v", err, ErrKeyType)
+	}
+}
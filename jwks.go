@@ -0,0 +1,353 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval is the minimum time between fetches of a remote
+// key set, preventing tokens with unknown kid values from causing a
+// fetch per request.
+const minRefreshInterval = 30 * time.Second
+
+// maxKeySetSize is the maximum size of a fetched JWKS document.
+const maxKeySetSize = 1 << 20
+
+// defaultRefreshInterval is the time between background fetches of a
+// started remote key set.
+const defaultRefreshInterval = 15 * time.Minute
+
+// KeySet is a JSON Web Key Set.
+//
+// See RFC 7517 Section 5.
+type KeySet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseKeySet parses a JWKS document. Keys of an unknown type are
+// ignored as required by RFC 7517 Section 5.
+func ParseKeySet(b []byte) (*KeySet, error) {
+	var doc KeySet
+	err := json.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	s := &KeySet{}
+	for _, k := range doc.Keys {
+		_, err = k.NativeKey()
+		if err != nil {
+			continue
+		}
+		s.Keys = append(s.Keys, k)
+	}
+	return s, nil
+}
+
+// Lookup returns the key matching the kid, alg and use parameters.
+// Empty parameters match any key and keys without an alg or use
+// parameter match any alg or use. It returns false unless exactly
+// one key matches.
+func (s *KeySet) Lookup(kid, alg, use string) (JWK, bool) {
+	var match []JWK
+	for _, k := range s.Keys {
+		if kid != "" && k.Kid != kid {
+			continue
+		}
+		if alg != "" && k.Alg != "" && k.Alg != alg {
+			continue
+		}
+		if use != "" && k.Use != "" && k.Use != use {
+			continue
+		}
+		match = append(match, k)
+	}
+	if len(match) != 1 {
+		return JWK{}, false
+	}
+	return match[0], true
+}
+
+// Key implements the KeyProvider interface. The key is selected by the
+// kid and alg headers of the token and must permit verification.
+func (s *KeySet) Key(ctx context.Context, t *Token) ([]byte, error) {
+	k, ok := lookup(s.Keys, t, false)
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if !k.permits("verify") {
+		return nil, ErrKeyUsage
+	}
+	return k.Public().Key()
+}
+
+// KeyFunc returns the key set as a KeyFunc for use with ParseWithKeyFunc.
+func (s *KeySet) KeyFunc() KeyFunc {
+	return func(t *Token) ([]byte, error) {
+		return s.Key(context.Background(), t)
+	}
+}
+
+// RemoteKeySet is a KeyProvider backed by a JSON Web Key Set
+// document fetched over HTTP. The document is fetched on first use
+// and again whenever a token references an unknown key. Once started,
+// the document is also refreshed periodically in the background.
+type RemoteKeySet struct {
+	url      string
+	client   *http.Client
+	anyUsage bool
+	logger   *slog.Logger
+	interval time.Duration
+	mu       sync.Mutex
+	keys     []JWK
+	fetched  time.Time
+	err      error
+	inflight *keySetFetch
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// keySetFetch is a fetch of a remote key set shared by concurrent
+// callers. The err field is set before done is closed.
+type keySetFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// KeySetOption configures a RemoteKeySet.
+type KeySetOption func(*RemoteKeySet)
+
+// WithAnyKeyUsage returns an option that ignores the use and key_ops
+// parameters of keys. By default keys must permit signature verification.
+func WithAnyKeyUsage() KeySetOption {
+	return func(s *RemoteKeySet) {
+		s.anyUsage = true
+	}
+}
+
+// WithHTTPClient returns an option that fetches the key set with c.
+// The default is DefaultHTTPClient.
+func WithHTTPClient(c *http.Client) KeySetOption {
+	return func(s *RemoteKeySet) {
+		s.client = c
+	}
+}
+
+// WithKeySetLogger returns an option that logs key lookups and fetches
+// to l at debug level.
+func WithKeySetLogger(l *slog.Logger) KeySetOption {
+	return func(s *RemoteKeySet) {
+		s.logger = l
+	}
+}
+
+// WithRefreshInterval returns an option that sets the time between
+// background fetches once the key set is started. The default is 15
+// minutes. A non-positive interval disables background fetches.
+func WithRefreshInterval(d time.Duration) KeySetOption {
+	return func(s *RemoteKeySet) {
+		s.interval = d
+	}
+}
+
+// NewRemoteKeySet returns a new RemoteKeySet for the JWKS document at url.
+func NewRemoteKeySet(url string, opts ...KeySetOption) *RemoteKeySet {
+	s := &RemoteKeySet{url: url, client: DefaultHTTPClient, interval: defaultRefreshInterval}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Key implements the KeyProvider interface.
+func (s *RemoteKeySet) Key(ctx context.Context, t *Token) ([]byte, error) {
+	k, ok := s.lookup(t)
+	if !ok {
+		err := s.update(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		k, ok = s.lookup(t)
+	}
+	if !ok {
+		kid, _ := t.Header[HeaderKeyID].(string)
+		s.log(ctx, "jwt: key not found", slog.String("kid", kid))
+		return nil, ErrKeyNotFound
+	}
+	if !s.anyUsage && !k.permits("verify") {
+		return nil, ErrKeyUsage
+	}
+	return k.Public().Key()
+}
+
+// lookup returns the key of the current document matching the token.
+func (s *RemoteKeySet) lookup(t *Token) (JWK, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return lookup(s.keys, t, s.anyUsage)
+}
+
+// Start fetches the document and refreshes it in the background until
+// ctx is done or the key set is closed. It implements the Component
+// interface.
+func (s *RemoteKeySet) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.mu.Lock()
+	if s.done != nil {
+		s.mu.Unlock()
+		cancel()
+		return ErrStarted
+	}
+	s.cancel, s.done = cancel, done
+	s.mu.Unlock()
+	err := s.update(ctx, true)
+	if err != nil {
+		cancel()
+		s.mu.Lock()
+		if s.done == done {
+			s.cancel, s.done = nil, nil
+		}
+		s.mu.Unlock()
+		close(done)
+		return err
+	}
+	go s.refresh(ctx, done)
+	return nil
+}
+
+// Close stops the background refresh and waits for it to terminate.
+// It implements the Component interface.
+func (s *RemoteKeySet) Close() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// refresh fetches the document every interval until ctx is done.
+func (s *RemoteKeySet) refresh(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	if s.interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		s.update(ctx, true)
+	}
+}
+
+// update fetches the document unless it was fetched, or a fetch was
+// attempted, within minRefreshInterval. Concurrent callers share a
+// single fetch, which is made without holding the lock so that lookups
+// of known keys are not blocked by a slow endpoint.
+func (s *RemoteKeySet) update(ctx context.Context, force bool) error {
+	s.mu.Lock()
+	f := s.inflight
+	if f == nil && !force && time.Since(s.fetched) < minRefreshInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	if f != nil {
+		s.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	f = &keySetFetch{done: make(chan struct{})}
+	s.inflight = f
+	s.fetched = time.Now()
+	s.mu.Unlock()
+	keys, err := s.fetch(ctx)
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+	}
+	s.err = err
+	s.inflight = nil
+	s.mu.Unlock()
+	f.err = err
+	close(f.done)
+	if err != nil {
+		s.log(ctx, "jwt: key set fetch failed", slog.Any("error", err))
+		return err
+	}
+	s.log(ctx, "jwt: key set refreshed", slog.Int("keys", len(keys)))
+	return nil
+}
+
+// log logs msg at debug level if the key set has a logger.
+func (s *RemoteKeySet) log(ctx context.Context, msg string, attrs ...slog.Attr) {
+	if s.logger == nil {
+		return
+	}
+	attrs = append(attrs, slog.String("url", s.url))
+	s.logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+}
+
+// fetch retrieves and decodes the JWKS document.
+func (s *RemoteKeySet) fetch(ctx context.Context) ([]JWK, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwt: fetching %s: unexpected status %s", s.url, resp.Status)
+	}
+	var doc KeySet
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxKeySetSize)).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Keys, nil
+}
+
+// lookup returns the key matching the kid and alg headers of the token.
+// Tokens without a kid header match only if a single key is eligible,
+// and keys not permitted for verification are ineligible unless anyUsage.
+func lookup(keys []JWK, t *Token, anyUsage bool) (JWK, bool) {
+	kid, _ := t.Header[HeaderKeyID].(string)
+	alg, _ := t.Header[HeaderAlgorithm].(string)
+	var match []JWK
+	for _, k := range keys {
+		if k.Alg != "" && k.Alg != alg {
+			continue
+		}
+		if kid != "" && k.Kid != kid {
+			continue
+		}
+		if kid == "" && !anyUsage && !k.permits("verify") {
+			continue
+		}
+		match = append(match, k)
+	}
+	if len(match) != 1 {
+		return JWK{}, false
+	}
+	return match[0], true
+}
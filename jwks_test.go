@@ -0,0 +1,313 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeySet(t *testing.T) {
+	k := encode([]byte("secret"))
+	s, err := ParseKeySet([]byte(`{"keys":[
+		{"kty":"oct","kid":"a","alg":"HS256","use":"sig","k":"` + k + `"},
+		{"kty":"oct","kid":"b","alg":"HS512","k":"` + k + `"},
+		{"kty":"oct","kid":"b","use":"enc","k":"` + k + `"},
+		{"kty":"unknown","kid":"c"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		kid string
+		alg string
+		use string
+		ok  bool
+	}{
+		{"a", "", "", true},
+		{"a", "HS256", "sig", true},
+		{"a", "HS512", "", false},
+		{"a", "", "enc", false},
+		{"b", "", "", false},
+		{"b", "HS512", "sig", true},
+		{"b", "", "enc", false},
+		{"b", "HS256", "enc", true},
+		{"c", "", "", false},
+	}
+	for i, tt := range tests {
+		_, ok := s.Lookup(tt.kid, tt.alg, tt.use)
+		if ok != tt.ok {
+			t.Errorf("%d. Lookup(%q, %q, %q)\nhave %v\nwant %v", i, tt.kid, tt.alg, tt.use, ok, tt.ok)
+		}
+	}
+	token := New(HS256)
+	token.Header["kid"] = "a"
+	jwt, err := token.Sign([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseWithKeyFunc(HS256, jwt, s.KeyFunc())
+	if err != nil {
+		t.Errorf("ParseWithKeyFunc err\nhave %v\nwant %v", err, nil)
+	}
+	_, err = ParseKeySet([]byte(`{"keys":`))
+	if !errors.Is(err, ErrInvalidJWK) {
+		t.Errorf("ParseKeySet err\nhave %v\nwant %v", err, ErrInvalidJWK)
+	}
+}
+
+func TestRemoteKeySetKeyUsage(t *testing.T) {
+	k := encode([]byte("secret"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"keys":[
+			{"kty":"oct","kid":"sig","use":"sig","k":"` + k + `"},
+			{"kty":"oct","kid":"enc","use":"enc","k":"` + k + `"},
+			{"kty":"oct","kid":"ops","key_ops":["sign","verify"],"k":"` + k + `"},
+			{"kty":"oct","kid":"wrap","key_ops":["wrapKey"],"k":"` + k + `"}
+		]}`))
+	}))
+	defer srv.Close()
+	var tests = []struct {
+		kid  string
+		opts []KeySetOption
+		err  error
+	}{
+		{"sig", nil, nil},
+		{"ops", nil, nil},
+		{"enc", nil, ErrKeyUsage},
+		{"wrap", nil, ErrKeyUsage},
+		{"enc", []KeySetOption{WithAnyKeyUsage()}, nil},
+		{"", nil, ErrKeyNotFound},
+	}
+	for i, tt := range tests {
+		keys := NewRemoteKeySet(srv.URL, tt.opts...)
+		token := New(HS256)
+		if tt.kid != "" {
+			token.Header["kid"] = tt.kid
+		}
+		jwt, err := token.Sign([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := NewVerifier([]Signer{HS256}, keys)
+		_, err = v.Verify(context.Background(), jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestRemoteKeySetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"keys":[{"kty":"oct","kid":"a","k":"` + encode([]byte("secret")) + `"}]}`))
+	}))
+	defer srv.Close()
+	keys := NewRemoteKeySet(srv.URL, WithKeySetLogger(l))
+	token := New(HS256)
+	token.Header["kid"] = "b"
+	_, err := keys.Key(context.Background(), token)
+	if err != ErrKeyNotFound {
+		t.Fatalf("Key err\nhave %v\nwant %v", err, ErrKeyNotFound)
+	}
+	out := buf.String()
+	for _, msg := range []string{"key set refreshed", "keys=1", "key not found", "kid=b"} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("should log %q, have %q", msg, out)
+		}
+	}
+}
+
+func TestRemoteKeySetStart(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer srv.Close()
+	keys := NewRemoteKeySet(srv.URL, WithRefreshInterval(time.Millisecond))
+	err := keys.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = keys.Start(context.Background())
+	if err != ErrStarted {
+		t.Fatalf("Start err\nhave %v\nwant %v", err, ErrStarted)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for hits.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if hits.Load() < 3 {
+		t.Fatalf("should refresh in the background, have %d fetches", hits.Load())
+	}
+	err = keys.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A fetch cancelled by Close may still reach the server.
+	time.Sleep(20 * time.Millisecond)
+	n := hits.Load()
+	time.Sleep(20 * time.Millisecond)
+	if hits.Load() != n {
+		t.Errorf("should stop refreshing after Close")
+	}
+	err = keys.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type countingTransport struct {
+	n int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.n, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestRemoteKeySetHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"keys":[{"kty":"oct","k":"` + encode([]byte("secret")) + `"}]}`))
+	}))
+	defer srv.Close()
+	rt := &countingTransport{}
+	c := NewHTTPClient(rt)
+	if c.Timeout != DefaultHTTPTimeout {
+		t.Errorf("NewHTTPClient timeout\nhave %v\nwant %v", c.Timeout, DefaultHTTPTimeout)
+	}
+	keys := NewRemoteKeySet(srv.URL, WithHTTPClient(c))
+	_, err := keys.Key(context.Background(), New(HS256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&rt.n) != 1 {
+		t.Errorf("should fetch with the provided client")
+	}
+}
+
+func TestRemoteKeySetFetch(t *testing.T) {
+	var hits atomic.Int32
+	var down atomic.Bool
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/slow" {
+			<-block
+		}
+		if r.URL.Path == "/large" {
+			w.Write([]byte(`{"keys":[` + strings.Repeat(" ", maxKeySetSize) + `]}`))
+			return
+		}
+		w.Write([]byte(`{"keys":[{"kty":"oct","kid":"a","k":"` + encode([]byte("secret")) + `"}]}`))
+	}))
+	defer srv.Close()
+	unknown := New(HS256)
+	unknown.Header["kid"] = "b"
+
+	// A failed fetch is rate limited like a successful one.
+	down.Store(true)
+	keys := NewRemoteKeySet(srv.URL)
+	for range 3 {
+		_, err := keys.Key(context.Background(), unknown)
+		if err == nil {
+			t.Fatalf("Key should fail while the endpoint is down")
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("failed fetches\nhave %d\nwant %d", hits.Load(), 1)
+	}
+	down.Store(false)
+
+	// Known keys are not blocked by a slow fetch for an unknown key.
+	keys = NewRemoteKeySet(srv.URL + "/slow")
+	close(block)
+	_, err := keys.Key(context.Background(), New(HS256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block = make(chan struct{})
+	keys.mu.Lock()
+	keys.fetched = time.Time{}
+	keys.mu.Unlock()
+	go keys.Key(context.Background(), unknown)
+	for {
+		keys.mu.Lock()
+		inflight := keys.inflight != nil
+		keys.mu.Unlock()
+		if inflight {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	known := New(HS256)
+	known.Header["kid"] = "a"
+	_, err = keys.Key(context.Background(), known)
+	if err != nil {
+		t.Errorf("Key err\nhave %v\nwant %v", err, nil)
+	}
+	close(block)
+
+	keys = NewRemoteKeySet(srv.URL + "/large")
+	_, err = keys.Key(context.Background(), New(HS256))
+	if err == nil {
+		t.Errorf("Key should fail for an oversized document")
+	}
+}
+
+func TestRemoteKeySetClose(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	// A non-positive interval disables background fetches.
+	keys := NewRemoteKeySet(srv.URL, WithRefreshInterval(0))
+	err := keys.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = keys.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Close cancels a fetch in progress rather than waiting for it.
+	keys = NewRemoteKeySet(srv.URL + "/slow")
+	errc := make(chan error, 1)
+	go func() {
+		errc <- keys.Start(context.Background())
+	}()
+	<-started
+	err = keys.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Start err\nhave %v\nwant %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close should cancel the fetch in progress")
+	}
+}
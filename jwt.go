@@ -3,22 +3,27 @@
 package jwt
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"strings"
-	"time"
 )
 
 var sep = "."
 
 // Token errors.
 var (
-	ErrSigner         = errors.New("jwt: invalid signer")
-	ErrMalformed      = errors.New("jwt: incorrect token string format")
-	ErrHeaderTyp      = errors.New("jwt: header does not contain valid typ")
-	ErrHeaderAlg      = errors.New("jwt: header does not contain valid alg")
-	ErrClaimExpired   = errors.New("jwt: current time must be before exp")
-	ErrClaimNotBefore = errors.New("jwt: current time must be after nbf")
+	ErrSigner          = errors.New("jwt: invalid signer")
+	ErrMalformed       = errors.New("jwt: incorrect token string format")
+	ErrHeaderTyp       = errors.New("jwt: header does not contain valid typ")
+	ErrHeaderAlg       = errors.New("jwt: header does not contain valid alg")
+	ErrClaimExpired    = errors.New("jwt: current time must be before exp")
+	ErrClaimNotBefore  = errors.New("jwt: current time must be after nbf")
+	ErrClaimIssuer     = errors.New("jwt: iss is not an accepted issuer")
+	ErrClaimAudience   = errors.New("jwt: aud does not contain an accepted audience")
+	ErrClaimRequired   = errors.New("jwt: required claim is missing")
+	ErrClaimType       = errors.New("jwt: registered claim has invalid type")
+	ErrClaimRange      = errors.New("jwt: registered date claim is out of range")
+	ErrClaimReplicated = errors.New("jwt: header parameter does not match replicated claim")
 )
 
 // Token represents a JWT token.
@@ -39,16 +44,39 @@ func New(s Signer) *Token {
 
 // Sign returns the signed token by serializing the token
 // header and claims to JSON and using the configured signer
-// to calculate the signature.
+// to calculate the signature. The typ header defaults to "JWT".
 func (t *Token) Sign(key []byte) (string, error) {
+	jwt, err := t.SigningInput()
+	if err != nil {
+		return "", err
+	}
+	sig, err := t.signer.Sign([]byte(jwt), key)
+	if err != nil {
+		return "", err
+	}
+	jwt += sep + encode(sig)
+	return jwt, nil
+}
+
+// SigningInput returns the encoded header and claims joined by a
+// period, exactly as passed to the signer by Sign.
+func (t *Token) SigningInput() (string, error) {
+	return t.signingInput(encode)
+}
+
+// signingInput returns the header and claims encoded with enc and
+// joined by a period.
+func (t *Token) signingInput(enc func([]byte) string) (string, error) {
 	if t.signer == nil {
 		return "", ErrSigner
 	}
 	if t.Header == nil {
 		t.Header = make(map[string]interface{})
 	}
-	t.Header["typ"] = "JWT"
-	t.Header["alg"] = t.signer.String()
+	if _, ok := t.Header[HeaderType]; !ok {
+		t.Header[HeaderType] = "JWT"
+	}
+	t.Header[HeaderAlgorithm] = t.signer.String()
 	h, err := json.Marshal(t.Header)
 	if err != nil {
 		return "", err
@@ -60,80 +88,21 @@ func (t *Token) Sign(key []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	jwt := encode(h) + sep + encode(c)
-	sig, err := t.signer.Sign([]byte(jwt), key)
-	if err != nil {
-		return "", err
-	}
-	jwt += sep + encode(sig)
-	return jwt, nil
+	return enc(h) + sep + enc(c), nil
 }
 
 // Parse validates jwt with key.
 // Signer s is explicitly passed as attackers could otherwise control the
 // choice of algorithm with the alg header that has not yet been verified.
-func Parse(s Signer, jwt string, key []byte) (*Token, error) {
-	return ParseWithKeyFunc(s, jwt, func(t *Token) ([]byte, error) {
-		return key, nil
-	})
+func Parse(s Signer, jwt string, key []byte, opts ...Option) (*Token, error) {
+	v := NewVerifier([]Signer{s}, StaticKey(key), opts...)
+	return v.Verify(context.Background(), jwt)
 }
 
 // ParseWithKeyFunc validates the provided jwt using the provided keyFn.
 // This can be used in cases where the token header needs to be parsed
 // to determine the full key.
-func ParseWithKeyFunc(s Signer, jwt string, keyFn func(*Token) ([]byte, error)) (*Token, error) {
-	t := &Token{signer: s}
-	parts := strings.Split(jwt, sep)
-	if len(parts) != 3 {
-		return nil, ErrMalformed
-	}
-	h, err := decode(parts[0])
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(h, &t.Header)
-	if err != nil {
-		return nil, err
-	}
-	typ, ok := t.Header["typ"].(string)
-	if !ok || typ != "JWT" {
-		return nil, ErrHeaderTyp
-	}
-	alg, ok := t.Header["alg"].(string)
-	if !ok || alg != s.String() {
-		return nil, ErrHeaderAlg
-	}
-	key, err := keyFn(t)
-	if err != nil {
-		return nil, err
-	}
-	b := strings.Join(parts[:2], sep)
-	sig, err := decode(parts[2])
-	if err != nil {
-		return nil, err
-	}
-	err = s.Verify([]byte(b), sig, key)
-	if err != nil {
-		return nil, err
-	}
-	c, err := decode(parts[1])
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(c, &t.Claims)
-	if err != nil {
-		return nil, err
-	}
-	now := time.Now().Unix()
-	if exp, ok := t.Claims["exp"].(float64); ok {
-		if now > int64(exp) {
-			return nil, ErrClaimExpired
-		}
-	}
-	if nbf, ok := t.Claims["nbf"].(float64); ok {
-		if now < int64(nbf) {
-			return nil, ErrClaimNotBefore
-		}
-	}
-	return t, nil
+func ParseWithKeyFunc(s Signer, jwt string, keyFn KeyFunc, opts ...Option) (*Token, error) {
+	v := NewVerifier([]Signer{s}, keyFn, opts...)
+	return v.Verify(context.Background(), jwt)
 }
@@ -99,6 +99,30 @@ MIICWwIBAAKBgQDdlatRjRjogo3WojgGHFHYLugdUWAY9iR3fy4arWNA1KoS8kVw33cJibXr8bvwUAUp
 	}
 }
 
+func TestSignUnsecured(t *testing.T) {
+	token := New(UnsecuredSigner{})
+	token.Claims["foo"] = "bar"
+	have, err := token.Sign(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJmb28iOiJiYXIifQ."
+	if have != want {
+		t.Fatalf("have %s\nwant %s", have, want)
+	}
+	parsed, err := Parse(UnsecuredSigner{}, have, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Claims, token.Claims) {
+		t.Fatalf("have %v\nwant %v", parsed.Claims, token.Claims)
+	}
+	_, err = Parse(HS256, have, []byte("secret"))
+	if err != ErrHeaderAlg {
+		t.Fatalf("should reject unsecured token, have %v", err)
+	}
+}
+
 func TestSignNone(t *testing.T) {
 	token := New(nil)
 	_, err := token.Sign([]byte("secret"))
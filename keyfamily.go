@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+)
+
+// ErrKeyMismatch is wrapped by a *KeyMismatchError.
+var ErrKeyMismatch = errors.New("jwt: key does not match the algorithm family")
+
+// Key families.
+const (
+	KeyFamilySymmetric  = "symmetric"
+	KeyFamilyAsymmetric = "asymmetric"
+	KeyFamilyUnknown    = "unknown"
+)
+
+// KeyMismatchError is returned when a key provider returns a key of a
+// different family than the alg header of the token, such as a public
+// key for an HMAC algorithm. Verifying with the key anyway would allow
+// algorithm confusion, where a public key is used as an HMAC secret.
+type KeyMismatchError struct {
+	// Algorithm is the alg header of the token.
+	Algorithm string
+
+	// Want is the key family of the algorithm.
+	Want string
+
+	// Have is the key family of the key.
+	Have string
+}
+
+// Error implements the error interface.
+func (e *KeyMismatchError) Error() string {
+	return fmt.Sprintf("jwt: %s algorithm %s given %s key", e.Want, e.Algorithm, e.Have)
+}
+
+// Unwrap returns ErrKeyMismatch.
+func (e *KeyMismatchError) Unwrap() error {
+	return ErrKeyMismatch
+}
+
+// signerFamily returns the key family of the signer, or the empty
+// string if it is unknown, as for custom signers.
+func signerFamily(s Signer) string {
+	switch s.(type) {
+	case HMACSigner, PepperedSigner:
+		return KeyFamilySymmetric
+	case RSASigner, RSAPSSSigner, ECDSASigner, EdDSASigner, SchemeSigner:
+		return KeyFamilyAsymmetric
+	}
+	return ""
+}
+
+// rawKeys returns true if any of the signers may accept a byte key
+// that is not an encoded key as a public key, such as the raw public
+// keys of SchemeSigner or the keys of custom signers.
+func (v *Verifier) rawKeys() bool {
+	for _, s := range v.signers {
+		switch s.(type) {
+		case HMACSigner, PepperedSigner, RSASigner, RSAPSSSigner, ECDSASigner, EdDSASigner:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// keyFamily returns the key family of the key, or the empty string if
+// it cannot be determined. Byte keys are asymmetric if they are encoded
+// keys accepted by the asymmetric signers and symmetric secrets
+// otherwise, unless a configured signer may accept raw public keys.
+func (v *Verifier) keyFamily(key interface{}) string {
+	switch key := key.(type) {
+	case []byte:
+		if decodeKeyBlock(key) != nil {
+			return KeyFamilyAsymmetric
+		}
+		if v.rawKeys() {
+			return ""
+		}
+		return KeyFamilySymmetric
+	case crypto.Signer:
+		return KeyFamilyAsymmetric
+	}
+	if _, ok := key.(interface{ Equal(crypto.PublicKey) bool }); ok {
+		return KeyFamilyAsymmetric
+	}
+	return ""
+}
+
+// checkKeyFamily returns a *KeyMismatchError if the key is of a
+// different family than the signer. A key of undetermined family is
+// refused for a symmetric signer if any signer is not symmetric, as it
+// may be a public key.
+func (v *Verifier) checkKeyFamily(s Signer, key interface{}) error {
+	want := signerFamily(s)
+	if want == "" {
+		return nil
+	}
+	have := v.keyFamily(key)
+	if have == "" && want == KeyFamilySymmetric && !v.symmetric() {
+		have = KeyFamilyUnknown
+	}
+	if have == "" || have == want {
+		return nil
+	}
+	return &KeyMismatchError{Algorithm: s.String(), Want: want, Have: have}
+}
+
+// symmetric returns true if every signer is symmetric.
+func (v *Verifier) symmetric() bool {
+	for _, s := range v.signers {
+		if signerFamily(s) != KeyFamilySymmetric {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"context"
+	"crypto/mldsa"
+	"errors"
+	"testing"
+)
+
+func TestKeyFamily(t *testing.T) {
+	k, err := GenerateKey("ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A token signed with the public key as an HMAC secret.
+	confused, err := New(HS256).Sign(k.PublicPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := New(HS256).Sign([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		token string
+		keys  KeyProvider
+		want  *KeyMismatchError
+	}{
+		{confused, StaticKey(k.PublicPEM), &KeyMismatchError{"HS256", KeyFamilySymmetric, KeyFamilyAsymmetric}},
+		{confused, StaticNativeKey(k.Public), &KeyMismatchError{"HS256", KeyFamilySymmetric, KeyFamilyAsymmetric}},
+		{secret, StaticKey([]byte("secret")), nil},
+	}
+	v := func(keys KeyProvider) *Verifier {
+		return NewVerifier([]Signer{HS256, ES256}, keys)
+	}
+	for i, tt := range tests {
+		_, err := v(tt.keys).Verify(context.Background(), tt.token)
+		var e *KeyMismatchError
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, nil)
+			}
+			continue
+		}
+		if !errors.As(err, &e) || *e != *tt.want || !errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.want)
+		}
+	}
+	token, err := New(ES256).Sign(k.PrivatePEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v(StaticKey([]byte("secret"))).Verify(context.Background(), token)
+	if !errors.Is(err, ErrKeyMismatch) {
+		t.Fatalf("Verify err\nhave %v\nwant %v", err, ErrKeyMismatch)
+	}
+}
+
+func TestKeyFamilyRawPublicKey(t *testing.T) {
+	priv, err := mldsa.GenerateKey(mldsa.MLDSA44())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.PublicKey().Bytes()
+	v := NewVerifier([]Signer{MLDSA44, HS256}, StaticKey(pub))
+	// A token signed with the raw public key as an HMAC secret.
+	confused, err := New(HS256).Sign(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v.Verify(context.Background(), confused)
+	want := &KeyMismatchError{"HS256", KeyFamilySymmetric, KeyFamilyUnknown}
+	var e *KeyMismatchError
+	if !errors.As(err, &e) || *e != *want {
+		t.Fatalf("Verify err\nhave %v\nwant %v", err, want)
+	}
+	token, err := New(MLDSA44).Sign(priv.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify err\nhave %v\nwant %v", err, nil)
+	}
+}
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+)
+
+// Keyring is a set of keys identified by kid. It selects verification
+// keys by the kid header of the token and sets the kid header of tokens
+// it signs. A Keyring is safe for concurrent use.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]keyringEntry
+	current string
+}
+
+// keyringEntry is the key material of a single kid.
+type keyringEntry struct {
+	sign   []byte
+	verify []byte
+}
+
+// NewKeyring returns a new empty Keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]keyringEntry)}
+}
+
+// Add adds or replaces the keys identified by kid. HMAC secrets are
+// passed as both signKey and verifyKey. The signKey may be nil for keys
+// that are only used to verify, such as retired keys. The first key
+// added with a signKey is used for signing until Use is called.
+func (r *Keyring) Add(kid string, signKey, verifyKey []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[kid] = keyringEntry{sign: signKey, verify: verifyKey}
+	if r.current == "" && signKey != nil {
+		r.current = kid
+	}
+}
+
+// Remove removes the keys identified by kid. Tokens signed with the
+// key no longer verify.
+func (r *Keyring) Remove(kid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, kid)
+	if r.current == kid {
+		r.current = ""
+	}
+}
+
+// Use sets the key identified by kid as the signing key. It returns
+// ErrKeyNotFound if there is no signing key for kid.
+func (r *Keyring) Use(kid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.keys[kid].sign == nil {
+		return ErrKeyNotFound
+	}
+	r.current = kid
+	return nil
+}
+
+// Sign sets the kid header of the token to the signing key and returns
+// the signed token. It returns ErrKeyNotFound if there is no signing key.
+func (r *Keyring) Sign(t *Token) (string, error) {
+	r.mu.RLock()
+	kid, key := r.current, r.keys[r.current].sign
+	r.mu.RUnlock()
+	if key == nil {
+		return "", ErrKeyNotFound
+	}
+	t.Header[HeaderKeyID] = kid
+	return t.Sign(key)
+}
+
+// Key implements the KeyProvider interface. The key is selected by the
+// kid header of the token.
+func (r *Keyring) Key(ctx context.Context, t *Token) ([]byte, error) {
+	kid, _ := t.Header[HeaderKeyID].(string)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.keys[kid]
+	if !ok || e.verify == nil {
+		return nil, ErrKeyNotFound
+	}
+	return e.verify, nil
+}
+
+// KeyFunc returns the keyring as a KeyFunc for use with ParseWithKeyFunc.
+func (r *Keyring) KeyFunc() KeyFunc {
+	return func(t *Token) ([]byte, error) {
+		return r.Key(context.Background(), t)
+	}
+}
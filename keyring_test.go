@@ -0,0 +1,58 @@
+package jwt
+
+import "testing"
+
+func TestKeyring(t *testing.T) {
+	r := NewKeyring()
+	_, err := r.Sign(New(HS256))
+	if err != ErrKeyNotFound {
+		t.Errorf("Sign err\nhave %v\nwant %v", err, ErrKeyNotFound)
+	}
+	r.Add("old", nil, []byte("old"))
+	r.Add("a", []byte("a"), []byte("a"))
+	r.Add("b", []byte("b"), []byte("b"))
+	sign := func() string {
+		jwt, err := r.Sign(New(HS256))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jwt
+	}
+	a := sign()
+	err = r.Use("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := sign()
+	err = r.Use("old")
+	if err != ErrKeyNotFound {
+		t.Errorf("Use err\nhave %v\nwant %v", err, ErrKeyNotFound)
+	}
+	old := New(HS256)
+	old.Header["kid"] = "old"
+	oldJWT, err := old.Sign([]byte("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Remove("a")
+	var tests = []struct {
+		jwt string
+		kid string
+		err error
+	}{
+		{a, "a", ErrKeyNotFound},
+		{b, "b", nil},
+		{oldJWT, "old", nil},
+		{sign(), "b", nil},
+	}
+	for i, tt := range tests {
+		token, err := ParseWithKeyFunc(HS256, tt.jwt, r.KeyFunc())
+		if err != tt.err {
+			t.Errorf("%d. ParseWithKeyFunc err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && token.Header["kid"] != tt.kid {
+			t.Errorf("%d. kid\nhave %v\nwant %v", i, token.Header["kid"], tt.kid)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package jwt
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// Key errors.
+var (
+	ErrKeyNotFound     = errors.New("jwt: key not found")
+	ErrKeyTooLarge     = errors.New("jwt: key exceeds maximum size")
+	ErrKeyNotPEM       = errors.New("jwt: key is not pem encoded")
+	ErrKeyType         = errors.New("jwt: wrong key type")
+	ErrKeyCorrupt      = errors.New("jwt: corrupted key")
+	ErrKeyInconsistent = errors.New("jwt: inconsistent key")
+	ErrKeyMultiPrime   = errors.New("jwt: multi-prime rsa keys are not supported")
+)
+
+// maxKeySize is the maximum size of key material read by ReadKey.
+const maxKeySize = 1 << 20
+
+// KeyProvider is the interface that provides verification keys.
+type KeyProvider interface {
+	// Key returns the key used to verify the signature of the token.
+	// Only the token header is available at this point.
+	Key(ctx context.Context, t *Token) ([]byte, error)
+}
+
+// KeyFunc is an adapter to allow the use of ordinary functions
+// as a KeyProvider.
+type KeyFunc func(*Token) ([]byte, error)
+
+// Key implements the KeyProvider interface.
+func (fn KeyFunc) Key(ctx context.Context, t *Token) ([]byte, error) {
+	return fn(t)
+}
+
+// KeyProviders is a KeyProvider that consults each provider in order
+// and returns the first key found.
+type KeyProviders []KeyProvider
+
+// Key implements the KeyProvider interface.
+func (p KeyProviders) Key(ctx context.Context, t *Token) ([]byte, error) {
+	for _, provider := range p {
+		key, err := provider.Key(ctx, t)
+		if err == ErrKeyNotFound {
+			continue
+		}
+		return key, err
+	}
+	return nil, ErrKeyNotFound
+}
+
+// StaticKey returns a KeyProvider that always returns key.
+func StaticKey(key []byte) KeyProvider {
+	return KeyFunc(func(*Token) ([]byte, error) {
+		return key, nil
+	})
+}
+
+// ReadKey reads key material, such as a PEM-encoded key, from r.
+func ReadKey(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxKeySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxKeySize {
+		return nil, ErrKeyTooLarge
+	}
+	return b, nil
+}
+
+// LoadKey reads key material from the named file in fsys.
+// This allows keys to be loaded from the operating system with
+// os.DirFS or bundled with the binary using embed.FS.
+func LoadKey(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadKey(f)
+}
+
+// decodeKeyBlock returns the PEM block of b. Raw DER-encoded keys, as
+// stored by some secret stores, are returned as a block of the type
+// detected from their ASN.1 structure. OpenSSH private keys and
+// authorized keys are returned as equivalent PKCS #8 and PKIX blocks.
+// It returns nil if b is none of these.
+func decodeKeyBlock(b []byte) *pem.Block {
+	block, _ := pem.Decode(b)
+	if block != nil && block.Type == "OPENSSH PRIVATE KEY" {
+		if converted := decodeOpenSSHBlock(b); converted != nil {
+			return converted
+		}
+	}
+	if block != nil {
+		return block
+	}
+	if typ := derKeyType(b); typ != "" {
+		return &pem.Block{Type: typ, Bytes: b}
+	}
+	return decodeOpenSSHBlock(b)
+}
+
+// derKeyType returns the PEM block type of the DER-encoded key, or the
+// empty string if der is not a PKIX public key, PKCS #8 private key,
+// PKCS #1 RSA private key or SEC 1 EC private key.
+func derKeyType(der []byte) string {
+	if len(der) == 0 || der[0] != 0x30 {
+		return ""
+	}
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if rest, err := asn1.Unmarshal(der, &spki); err == nil && len(rest) == 0 {
+		return "PUBLIC KEY"
+	}
+	var pkcs8 struct {
+		Version    int
+		Algorithm  pkix.AlgorithmIdentifier
+		PrivateKey []byte
+		Attributes asn1.RawValue `asn1:"optional,tag:0"`
+		PublicKey  asn1.RawValue `asn1:"optional,tag:1"`
+	}
+	if rest, err := asn1.Unmarshal(der, &pkcs8); err == nil && len(rest) == 0 {
+		return "PRIVATE KEY"
+	}
+	var pkcs1 pkcs1PrivateKey
+	if rest, err := asn1.Unmarshal(der, &pkcs1); err == nil && len(rest) == 0 {
+		return "RSA PRIVATE KEY"
+	}
+	var sec1 ecPrivateKey
+	if rest, err := asn1.Unmarshal(der, &sec1); err == nil && len(rest) == 0 && sec1.Version == 1 {
+		return "EC PRIVATE KEY"
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+// Package keys parses key material in any encoding accepted by package
+// jwt into a normalized key with metadata, so load paths need no
+// format-specific branches.
+//
+//	k, err := keys.Parse(b)
+//	fmt.Println(k.Format, k.Type, k.Size, k.Curve, k.Fingerprint)
+//	token, err := jwt.New(jwt.ES256).SignKey(k.Key)
+package keys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/pnelson/jwt"
+)
+
+// ErrFormat is returned when key material is not in a known encoding.
+var ErrFormat = errors.New("keys: unknown key encoding")
+
+// Key encodings.
+const (
+	FormatPEM           = "PEM"
+	FormatDER           = "DER"
+	FormatJWK           = "JWK"
+	FormatOpenSSH       = "OpenSSH"
+	FormatAuthorizedKey = "authorized_key"
+)
+
+// Key is parsed key material.
+type Key struct {
+	// Key is the native key: *rsa.PublicKey, *rsa.PrivateKey,
+	// *ecdsa.PublicKey, *ecdsa.PrivateKey, ed25519.PublicKey,
+	// ed25519.PrivateKey or []byte for symmetric JWKs.
+	Key interface{}
+
+	// Private is true if Key is a private or symmetric key.
+	Private bool
+
+	// Format is the encoding the key was parsed from.
+	Format string
+
+	// Type is the JWK key type, "RSA", "EC", "OKP" or "oct".
+	Type string
+
+	// Size is the key size in bits.
+	Size int
+
+	// Curve is the JWK curve name, such as "P-256" or "Ed25519", of
+	// elliptic curve keys.
+	Curve string
+
+	// Fingerprint is the RFC 7638 JWK thumbprint of the key, which is
+	// the same for a private key and its public key.
+	Fingerprint string
+
+	// KeyID and Algorithm are the kid and alg parameters of a JWK.
+	KeyID     string
+	Algorithm string
+}
+
+// Parse detects the encoding of b and parses the key it holds. Keys may
+// be PEM or DER encoded PKIX, PKCS #8, PKCS #1 or SEC 1 keys or X.509
+// certificates, JWKs, OpenSSH private keys or authorized keys.
+// Passphrase-protected keys return jwt.ErrKeyEncryption and must be
+// decrypted with jwt.DecryptKey first.
+func Parse(b []byte) (*Key, error) {
+	trimmed := bytes.TrimSpace(b)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		jwk, err := jwt.ParseJWK(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwk.NativeKey()
+		if err != nil {
+			return nil, err
+		}
+		k, err := newKey(key, FormatJWK)
+		if err != nil {
+			return nil, err
+		}
+		k.KeyID, k.Algorithm = jwk.Kid, jwk.Alg
+		return k, nil
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN")):
+		block, _ := pem.Decode(trimmed)
+		if block == nil {
+			return nil, jwt.ErrKeyNotPEM
+		}
+		if block.Type == "OPENSSH PRIVATE KEY" {
+			key, err := jwt.ParseOpenSSHPrivateKey(trimmed)
+			if err != nil {
+				return nil, err
+			}
+			return newKey(key, FormatOpenSSH)
+		}
+		if block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) {
+			return nil, jwt.ErrKeyEncryption
+		}
+		key, err := parseBlock(block.Type, block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newKey(key, FormatPEM)
+	case bytes.HasPrefix(trimmed, []byte("ssh-")), bytes.HasPrefix(trimmed, []byte("ecdsa-")):
+		key, err := jwt.ParseAuthorizedKey(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		return newKey(key, FormatAuthorizedKey)
+	}
+	for _, typ := range []string{"PUBLIC KEY", "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY", "CERTIFICATE"} {
+		key, err := parseBlock(typ, b)
+		if err == nil {
+			return newKey(key, FormatDER)
+		}
+	}
+	return nil, ErrFormat
+}
+
+// parseBlock parses the DER key of the PEM block type.
+func parseBlock(typ string, der []byte) (interface{}, error) {
+	switch typ {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(der)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(der)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(der)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(der)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(der)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFormat, typ)
+}
+
+// newKey returns the key with its metadata.
+func newKey(key interface{}, format string) (*Key, error) {
+	jwk, err := jwt.NewJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	k := &Key{
+		Key:         key,
+		Private:     jwk.IsPrivate() || jwk.Kty == "oct",
+		Format:      format,
+		Type:        jwk.Kty,
+		Curve:       jwk.Crv,
+		Fingerprint: fingerprint,
+	}
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		k.Size = key.N.BitLen()
+	case *rsa.PrivateKey:
+		k.Size = key.N.BitLen()
+	case *ecdsa.PublicKey:
+		k.Size = key.Curve.Params().BitSize
+	case *ecdsa.PrivateKey:
+		k.Size = key.Curve.Params().BitSize
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		k.Size = 256
+	case []byte:
+		k.Size = len(key) * 8
+	}
+	return k, nil
+}
+
+// Public returns the public key of an asymmetric key, or nil for
+// symmetric keys.
+func (k *Key) Public() interface{} {
+	switch key := k.Key.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey
+	case ed25519.PrivateKey:
+		return key.Public()
+	case []byte:
+		return nil
+	}
+	return k.Key
+}
@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadKey(t *testing.T) {
+	fsys := fstest.MapFS{
+		"keys/hmac.key":  {Data: []byte("secret")},
+		"keys/large.key": {Data: bytes.Repeat([]byte("a"), maxKeySize+1)},
+	}
+	var tests = []struct {
+		name string
+		key  string
+		err  bool
+	}{
+		{"keys/hmac.key", "secret", false},
+		{"keys/large.key", "", true},
+		{"keys/missing.key", "", true},
+	}
+	for i, tt := range tests {
+		key, err := LoadKey(fsys, tt.name)
+		if (err != nil) != tt.err {
+			t.Errorf("%d. LoadKey err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if string(key) != tt.key {
+			t.Errorf("%d. LoadKey\nhave %s\nwant %s", i, key, tt.key)
+		}
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	key, err := ReadKey(strings.NewReader("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := New(HS256)
+	jwt, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier([]Signer{HS256}, StaticKey(key))
+	_, err = v.Verify(context.Background(), jwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDERKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	must := func(b []byte, err error) []byte {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	var tests = []struct {
+		signer Signer
+		priv   []byte
+		pub    []byte
+	}{
+		{RS256, x509.MarshalPKCS1PrivateKey(rsaKey), must(x509.MarshalPKIXPublicKey(&rsaKey.PublicKey))},
+		{PS256, must(x509.MarshalPKCS8PrivateKey(rsaKey)), must(x509.MarshalPKIXPublicKey(&rsaKey.PublicKey))},
+		{ES256, must(x509.MarshalECPrivateKey(ecKey)), must(x509.MarshalPKIXPublicKey(&ecKey.PublicKey))},
+		{ES256, must(x509.MarshalPKCS8PrivateKey(ecKey)), must(x509.MarshalPKIXPublicKey(&ecKey.PublicKey))},
+		{EdDSA, must(x509.MarshalPKCS8PrivateKey(edKey)), must(x509.MarshalPKIXPublicKey(edPub))},
+	}
+	for i, tt := range tests {
+		jwt, err := New(tt.signer).Sign(tt.priv)
+		if err != nil {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		_, err = Parse(tt.signer, jwt, tt.pub)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	_, err = New(RS256).Sign([]byte{0x30, 0x03, 0x02, 0x01, 0x00})
+	if err != ErrKeyNotPEM {
+		t.Errorf("Sign err\nhave %v\nwant %v", err, ErrKeyNotPEM)
+	}
+}
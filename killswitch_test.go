@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithKillSwitch(t *testing.T) {
+	key := []byte("secret")
+	var k KillSwitch
+	v := NewVerifier([]Signer{HS256, HS384}, StaticKey(key), WithKillSwitch(&k))
+	sign := func(s Signer, kid, iss string) string {
+		token := New(s)
+		if kid != "" {
+			token.Header[HeaderKeyID] = kid
+		}
+		if iss != "" {
+			token.Claims[ClaimIssuer] = iss
+		}
+		jwt, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jwt
+	}
+	deny := DenyList{
+		Algorithms: []string{"HS384"},
+		KeyIDs:     []string{"2024-01"},
+		Issuers:    []string{"https://compromised.example"},
+	}
+	var tests = []struct {
+		jwt  string
+		deny bool
+	}{
+		{sign(HS256, "2025-01", ""), false},
+		{sign(HS384, "2025-01", ""), true},
+		{sign(HS256, "2024-01", ""), true},
+		{sign(HS256, "", ""), true},
+		{sign(HS256, "2025-01", "https://compromised.example"), true},
+		{sign(HS256, "2025-01", "https://issuer.example"), false},
+	}
+	for i, tt := range tests {
+		_, err := v.Verify(context.Background(), tt.jwt)
+		if err != nil {
+			t.Errorf("%d. Verify before Set err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	k.Set(deny)
+	for i, tt := range tests {
+		var want error
+		if tt.deny {
+			want = ErrDenied
+		}
+		_, err := v.Verify(context.Background(), tt.jwt)
+		if err != want {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, want)
+		}
+	}
+	k.Set(DenyList{})
+	for i, tt := range tests {
+		_, err := v.Verify(context.Background(), tt.jwt)
+		if err != nil {
+			t.Errorf("%d. Verify after reset err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+}
+
+func TestKillSwitchOmittedKeyID(t *testing.T) {
+	key := []byte("secret")
+	k, err := NewJWK(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k.Kid = "k1"
+	var ks KillSwitch
+	ks.Set(DenyList{KeyIDs: []string{"k1"}})
+	v := NewVerifier([]Signer{HS256}, &KeySet{Keys: []JWK{*k}}, WithKillSwitch(&ks))
+	// The key set selects its sole key for a token without a kid.
+	jwt, err := New(HS256).Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v.Verify(context.Background(), jwt)
+	if err != ErrDenied {
+		t.Fatalf("Verify err\nhave %v\nwant %v", err, ErrDenied)
+	}
+}
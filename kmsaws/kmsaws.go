@@ -0,0 +1,165 @@
+// Package kmsaws implements token signing with asymmetric keys held in
+// AWS KMS, so tokens can be minted without local private keys.
+package kmsaws
+
+import (
+	"context"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/pnelson/jwt"
+)
+
+// ErrAlgorithm is returned for an alg without a KMS signing algorithm.
+var ErrAlgorithm = errors.New("kmsaws: unsupported algorithm")
+
+// ErrSignerMismatch is returned when signing a token whose alg header
+// is not the algorithm of the KMS key.
+var ErrSignerMismatch = errors.New("kmsaws: token signer does not match the key algorithm")
+
+// Client is the subset of the AWS KMS API used by Signer. It is
+// satisfied by a small wrapper around the Sign, Verify and GetPublicKey
+// operations of the AWS SDK client. Digests are passed with the DIGEST
+// message type and signing algorithms are KMS names such as
+// "ECDSA_SHA_256".
+type Client interface {
+	Sign(ctx context.Context, keyID string, digest []byte, algorithm string) ([]byte, error)
+	Verify(ctx context.Context, keyID string, digest, sig []byte, algorithm string) (bool, error)
+
+	// GetPublicKey returns the DER-encoded SubjectPublicKeyInfo.
+	GetPublicKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// algorithm is the KMS signing algorithm of a JWS alg.
+type algorithm struct {
+	name  string
+	hash  crypto.Hash
+	curve elliptic.Curve
+}
+
+// algorithms maps JWS alg values to KMS signing algorithms.
+var algorithms = map[string]algorithm{
+	"RS256": {"RSASSA_PKCS1_V1_5_SHA_256", crypto.SHA256, nil},
+	"RS384": {"RSASSA_PKCS1_V1_5_SHA_384", crypto.SHA384, nil},
+	"RS512": {"RSASSA_PKCS1_V1_5_SHA_512", crypto.SHA512, nil},
+	"PS256": {"RSASSA_PSS_SHA_256", crypto.SHA256, nil},
+	"PS384": {"RSASSA_PSS_SHA_384", crypto.SHA384, nil},
+	"PS512": {"RSASSA_PSS_SHA_512", crypto.SHA512, nil},
+	"ES256": {"ECDSA_SHA_256", crypto.SHA256, elliptic.P256()},
+	"ES384": {"ECDSA_SHA_384", crypto.SHA384, elliptic.P384()},
+	"ES512": {"ECDSA_SHA_512", crypto.SHA512, elliptic.P521()},
+}
+
+// Signer is a jwt.Signer that signs and verifies with a KMS key.
+// The key arguments of Sign and Verify are ignored.
+type Signer struct {
+	client Client
+	keyID  string
+	alg    string
+	algo   algorithm
+}
+
+// New returns a new Signer for the KMS key ID or ARN using the JWS
+// alg, such as "ES256", which must match the key spec.
+func New(client Client, keyID, alg string) (*Signer, error) {
+	algo, ok := algorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, alg)
+	}
+	return &Signer{client: client, keyID: keyID, alg: alg, algo: algo}, nil
+}
+
+// Sign implements the jwt.Signer interface.
+func (s *Signer) Sign(b, key []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), b)
+}
+
+// SignContext returns the signature of the data.
+func (s *Signer) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	digest, err := s.digest(b)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.client.Sign(ctx, s.keyID, digest, s.algo.name)
+	if err != nil {
+		return nil, err
+	}
+	if s.algo.curve != nil {
+		return jwt.ECDSASignatureToRaw(sig, s.algo.curve)
+	}
+	return sig, nil
+}
+
+// Verify implements the jwt.Signer interface.
+func (s *Signer) Verify(b, sig, key []byte) error {
+	return s.VerifyContext(context.Background(), b, sig)
+}
+
+// VerifyContext returns an error if the signature is invalid.
+// Verification is performed by KMS. Use PublicKey to verify locally.
+func (s *Signer) VerifyContext(ctx context.Context, b, sig []byte) error {
+	digest, err := s.digest(b)
+	if err != nil {
+		return err
+	}
+	if s.algo.curve != nil {
+		sig, err = jwt.ECDSASignatureToDER(sig)
+		if err != nil {
+			return jwt.ErrInvalidSignature
+		}
+	}
+	ok, err := s.client.Verify(ctx, s.keyID, digest, sig, s.algo.name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return jwt.ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignToken returns the signed token like t.Sign, passing ctx to KMS.
+// The token must have been created with jwt.New(s), otherwise
+// ErrSignerMismatch is returned.
+func (s *Signer) SignToken(ctx context.Context, t *jwt.Token) (string, error) {
+	input, err := t.SigningInput()
+	if err != nil {
+		return "", err
+	}
+	if t.Header[jwt.HeaderAlgorithm] != s.String() {
+		return "", ErrSignerMismatch
+	}
+	sig, err := s.SignContext(ctx, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// PublicKey returns the public key of the KMS key, suitable for
+// publishing with jwt.NewJWK or verifying with jwt.ParseWithKey.
+func (s *Signer) PublicKey(ctx context.Context) (crypto.PublicKey, error) {
+	der, err := s.client.GetPublicKey(ctx, s.keyID)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// String implements the fmt.Stringer interface.
+func (s *Signer) String() string {
+	return s.alg
+}
+
+func (s *Signer) digest(b []byte) ([]byte, error) {
+	if !s.algo.hash.Available() {
+		return nil, jwt.ErrHashUnavailable
+	}
+	h := s.algo.hash.New()
+	h.Write(b)
+	return h.Sum(nil), nil
+}
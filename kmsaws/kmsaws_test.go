@@ -0,0 +1,116 @@
+package kmsaws
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+// fakeKMS is a Client backed by local keys.
+type fakeKMS map[string]crypto.Signer
+
+func (f fakeKMS) Sign(ctx context.Context, keyID string, digest []byte, algorithm string) ([]byte, error) {
+	key, ok := f[keyID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	var opts crypto.SignerOpts = hashOf(algorithm)
+	if strings.HasPrefix(algorithm, "RSASSA_PSS") {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hashOf(algorithm)}
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+func (f fakeKMS) Verify(ctx context.Context, keyID string, digest, sig []byte, algorithm string) (bool, error) {
+	switch pub := f[keyID].Public().(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(pub, digest, sig), nil
+	case *rsa.PublicKey:
+		if strings.HasPrefix(algorithm, "RSASSA_PSS") {
+			return rsa.VerifyPSS(pub, hashOf(algorithm), digest, sig, nil) == nil, nil
+		}
+		return rsa.VerifyPKCS1v15(pub, hashOf(algorithm), digest, sig) == nil, nil
+	}
+	return false, nil
+}
+
+func (f fakeKMS) GetPublicKey(ctx context.Context, keyID string) ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(f[keyID].Public())
+}
+
+func hashOf(algorithm string) crypto.Hash {
+	switch algorithm[len(algorithm)-3:] {
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	}
+	return crypto.SHA256
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fakeKMS{"rsa": rsaKey, "ec": ecKey}
+	var tests = []struct {
+		keyID  string
+		alg    string
+		signer jwt.Signer
+	}{
+		{"rsa", "RS256", jwt.RS256},
+		{"rsa", "PS384", jwt.PS384},
+		{"ec", "ES256", jwt.ES256},
+	}
+	for i, tt := range tests {
+		s, err := New(client, tt.keyID, tt.alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := jwt.New(s)
+		token.Claims["sub"] = "user"
+		raw, err := s.SignToken(context.Background(), token)
+		if err != nil {
+			t.Errorf("%d. SignToken err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		pub, err := s.PublicKey(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = jwt.ParseWithKey(tt.signer, raw, pub)
+		if err != nil {
+			t.Errorf("%d. ParseWithKey err\nhave %v\nwant %v", i, err, nil)
+		}
+		_, err = jwt.Parse(s, raw, nil)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	s, err := New(client, "ec", "ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.SignToken(context.Background(), jwt.New(jwt.HS256))
+	if err != ErrSignerMismatch {
+		t.Errorf("SignToken err\nhave %v\nwant %v", err, ErrSignerMismatch)
+	}
+	_, err = New(client, "rsa", "HS256")
+	if !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("New err\nhave %v\nwant %v", err, ErrAlgorithm)
+	}
+}
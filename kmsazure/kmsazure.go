@@ -0,0 +1,198 @@
+// Package kmsazure implements token signing with RSA and EC keys held
+// in Azure Key Vault, so tokens can be minted without local private keys.
+package kmsazure
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// ErrAlgorithm is returned for an alg the key cannot sign with.
+var ErrAlgorithm = errors.New("kmsazure: unsupported algorithm")
+
+// ErrSignerMismatch is returned when signing a token whose alg header
+// is not the algorithm of the Key Vault key.
+var ErrSignerMismatch = errors.New("kmsazure: token signer does not match the key algorithm")
+
+// Client is the subset of the Azure Key Vault keys API used by Signer.
+// It is satisfied by a small wrapper around the Sign and GetKey
+// operations of the Azure SDK client. Key Vault uses JOSE alg names
+// for signing algorithms and returns EC signatures in JOSE format.
+type Client interface {
+	Sign(ctx context.Context, keyID, algorithm string, digest []byte) ([]byte, error)
+
+	// GetKey returns the public key as a JSON Web Key whose kid is the
+	// versioned key identifier.
+	GetKey(ctx context.Context, keyID string) (jwt.JWK, error)
+}
+
+// algorithm is the digest hash and key type of a JOSE alg.
+type algorithm struct {
+	hash crypto.Hash
+	kty  string
+}
+
+// algorithms maps the supported JOSE alg values to Key Vault key types.
+var algorithms = map[string]algorithm{
+	"RS256": {crypto.SHA256, "RSA"},
+	"RS384": {crypto.SHA384, "RSA"},
+	"RS512": {crypto.SHA512, "RSA"},
+	"PS256": {crypto.SHA256, "RSA"},
+	"PS384": {crypto.SHA384, "RSA"},
+	"PS512": {crypto.SHA512, "RSA"},
+	"ES256": {crypto.SHA256, "EC"},
+	"ES384": {crypto.SHA384, "EC"},
+	"ES512": {crypto.SHA512, "EC"},
+}
+
+// Option configures a Signer.
+type Option func(*Signer)
+
+// WithRetry returns an option that makes up to attempts attempts to
+// sign, waiting backoff after the first failure and doubling the wait
+// after each subsequent failure. The default is 3 attempts with a
+// backoff of 100 milliseconds.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(s *Signer) {
+		s.attempts = attempts
+		s.backoff = backoff
+	}
+}
+
+// Signer is a jwt.Signer that signs with a Key Vault key. Signatures
+// are verified locally with the public key. The key arguments of Sign
+// and Verify are ignored.
+type Signer struct {
+	client   Client
+	kid      string
+	alg      string
+	hash     crypto.Hash
+	pub      crypto.PublicKey
+	local    jwt.KeySigner
+	attempts int
+	backoff  time.Duration
+}
+
+// New returns a new Signer for the Key Vault key identifier, such as
+// "https://v.vault.azure.net/keys/k/version", using the JOSE alg. The
+// kid is the versioned key identifier returned by Key Vault, so tokens
+// continue to verify after the key is rotated.
+func New(ctx context.Context, client Client, keyID, alg string, opts ...Option) (*Signer, error) {
+	algo, ok := algorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, alg)
+	}
+	s := &Signer{client: client, alg: alg, hash: algo.hash, attempts: 3, backoff: 100 * time.Millisecond}
+	for _, opt := range opts {
+		opt(s)
+	}
+	k, err := client.GetKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	// Key Vault reports HSM-protected keys as RSA-HSM and EC-HSM.
+	k.Kty = strings.TrimSuffix(k.Kty, "-HSM")
+	if k.Kty != algo.kty {
+		return nil, fmt.Errorf("%w: %s with %s key", ErrAlgorithm, alg, k.Kty)
+	}
+	s.pub, err = k.Public().NativeKey()
+	if err != nil {
+		return nil, err
+	}
+	local, _ := jwt.LookupSigner(alg)
+	s.local, ok = local.(jwt.KeySigner)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, alg)
+	}
+	s.kid = k.Kid
+	if s.kid == "" {
+		s.kid = keyID
+	}
+	return s, nil
+}
+
+// Sign implements the jwt.Signer interface.
+func (s *Signer) Sign(b, key []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), b)
+}
+
+// SignContext returns the signature of the data, retrying failed
+// requests with exponential backoff.
+func (s *Signer) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	if !s.hash.Available() {
+		return nil, jwt.ErrHashUnavailable
+	}
+	h := s.hash.New()
+	h.Write(b)
+	digest := h.Sum(nil)
+	backoff := s.backoff
+	var err error
+	for i := 0; i < s.attempts || i == 0; i++ {
+		if i > 0 {
+			t := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			case <-t.C:
+			}
+			backoff *= 2
+		}
+		var sig []byte
+		sig, err = s.client.Sign(ctx, s.kid, s.alg, digest)
+		if err == nil {
+			return sig, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// Verify implements the jwt.Signer interface.
+func (s *Signer) Verify(b, sig, key []byte) error {
+	return s.local.VerifyKey(b, sig, s.pub)
+}
+
+// SignToken returns the signed token like t.Sign, passing ctx to Key
+// Vault. The kid header is set to the key identifier. The token must
+// have been created with jwt.New(s), otherwise ErrSignerMismatch is
+// returned.
+func (s *Signer) SignToken(ctx context.Context, t *jwt.Token) (string, error) {
+	t.Header[jwt.HeaderKeyID] = s.kid
+	input, err := t.SigningInput()
+	if err != nil {
+		return "", err
+	}
+	if t.Header[jwt.HeaderAlgorithm] != s.String() {
+		return "", ErrSignerMismatch
+	}
+	sig, err := s.SignContext(ctx, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// KeyID returns the versioned key identifier used as the kid header.
+func (s *Signer) KeyID() string {
+	return s.kid
+}
+
+// PublicKey returns the public key.
+func (s *Signer) PublicKey() crypto.PublicKey {
+	return s.pub
+}
+
+// String implements the fmt.Stringer interface.
+func (s *Signer) String() string {
+	return s.alg
+}
@@ -0,0 +1,156 @@
+package kmsazure
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+const vault = "https://v.vault.azure.net/keys/"
+
+// fakeVault is a Client backed by local keys that fails the first
+// failures sign requests.
+type fakeVault struct {
+	keys     map[string]crypto.Signer
+	failures int
+	calls    int
+}
+
+func (f *fakeVault) Sign(ctx context.Context, keyID, algorithm string, digest []byte) ([]byte, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("throttled")
+	}
+	key, ok := f.keys[strings.TrimSuffix(strings.TrimPrefix(keyID, vault), "/v1")]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	hash := algorithms[algorithm].hash
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...), nil
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(algorithm, "PS") {
+			return rsa.SignPSS(rand.Reader, k, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+	}
+	return nil, errors.New("unsupported key")
+}
+
+func (f *fakeVault) GetKey(ctx context.Context, keyID string) (jwt.JWK, error) {
+	name := strings.TrimPrefix(keyID, vault)
+	key, ok := f.keys[name]
+	if !ok {
+		return jwt.JWK{}, errors.New("not found")
+	}
+	k, err := jwt.NewJWK(key.Public())
+	if err != nil {
+		return jwt.JWK{}, err
+	}
+	k.Kty += "-HSM"
+	k.Kid = vault + name + "/v1"
+	return *k, nil
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		name     string
+		alg      string
+		failures int
+		err      error
+	}{
+		{"rsa", "RS256", 0, nil},
+		{"rsa", "PS384", 2, nil},
+		{"ec", "ES256", 1, nil},
+		{"ec", "ES256", 3, errors.New("throttled")},
+	}
+	for i, tt := range tests {
+		client := &fakeVault{keys: map[string]crypto.Signer{"rsa": rsaKey, "ec": ecKey}, failures: tt.failures}
+		s, err := New(context.Background(), client, vault+tt.name, tt.alg, WithRetry(3, time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.KeyID() != vault+tt.name+"/v1" {
+			t.Errorf("%d. KeyID\nhave %v\nwant %v", i, s.KeyID(), vault+tt.name+"/v1")
+		}
+		token := jwt.New(s)
+		token.Claims["sub"] = "user"
+		raw, err := s.SignToken(context.Background(), token)
+		if tt.err != nil {
+			if err == nil || err.Error() != tt.err.Error() {
+				t.Errorf("%d. SignToken err\nhave %v\nwant %v", i, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d. SignToken err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		parsed, err := jwt.Parse(s, raw, nil)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if parsed.Header["kid"] != s.KeyID() {
+			t.Errorf("%d. kid\nhave %v\nwant %v", i, parsed.Header["kid"], s.KeyID())
+		}
+	}
+	client := &fakeVault{keys: map[string]crypto.Signer{"rsa": rsaKey}}
+	s, err := New(context.Background(), client, vault+"rsa", "RS256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.SignToken(context.Background(), jwt.New(jwt.HS256))
+	if err != ErrSignerMismatch {
+		t.Errorf("SignToken err\nhave %v\nwant %v", err, ErrSignerMismatch)
+	}
+	_, err = New(context.Background(), client, vault+"rsa", "ES256")
+	if !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("New err\nhave %v\nwant %v", err, ErrAlgorithm)
+	}
+	_, err = New(context.Background(), client, vault+"rsa", "HS256")
+	if !errors.Is(err, ErrAlgorithm) {
+		t.Errorf("New err\nhave %v\nwant %v", err, ErrAlgorithm)
+	}
+}
+
+func TestSignContextCanceled(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeVault{keys: map[string]crypto.Signer{"ec": key}, failures: 1}
+	s, err := New(context.Background(), client, vault+"ec", "ES256", WithRetry(3, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.SignContext(ctx, []byte("data"))
+	if err != context.DeadlineExceeded {
+		t.Errorf("SignContext err\nhave %v\nwant %v", err, context.DeadlineExceeded)
+	}
+}
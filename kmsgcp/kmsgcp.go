@@ -0,0 +1,163 @@
+// Package kmsgcp implements token signing with asymmetric keys held in
+// Google Cloud KMS, so tokens can be minted without local private keys.
+package kmsgcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/pnelson/jwt"
+)
+
+// Signer errors.
+var (
+	ErrAlgorithm = errors.New("kmsgcp: unsupported key algorithm")
+	ErrPublicKey = errors.New("kmsgcp: invalid public key")
+
+	// ErrSignerMismatch is returned when signing a token whose alg
+	// header is not the algorithm of the crypto key version.
+	ErrSignerMismatch = errors.New("kmsgcp: token signer does not match the key algorithm")
+)
+
+// Client is the subset of the Cloud KMS API used by Signer. It is
+// satisfied by a small wrapper around the AsymmetricSign and
+// GetPublicKey operations of the Google Cloud client.
+type Client interface {
+	// AsymmetricSign returns the signature of the digest made with the
+	// crypto key version name.
+	AsymmetricSign(ctx context.Context, name string, digest []byte) ([]byte, error)
+
+	// GetPublicKey returns the public key of the crypto key version.
+	GetPublicKey(ctx context.Context, name string) (PublicKey, error)
+}
+
+// PublicKey is the result of a GetPublicKey operation.
+type PublicKey struct {
+	// PEM is the PEM-encoded public key.
+	PEM string
+
+	// Algorithm is the KMS key algorithm, such as "EC_SIGN_P256_SHA256".
+	Algorithm string
+}
+
+// algorithm is the JOSE alg of a KMS key algorithm.
+type algorithm struct {
+	alg   string
+	hash  crypto.Hash
+	curve elliptic.Curve
+}
+
+// algorithms maps KMS key algorithms to JOSE alg values.
+var algorithms = map[string]algorithm{
+	"EC_SIGN_P256_SHA256":        {"ES256", crypto.SHA256, elliptic.P256()},
+	"EC_SIGN_P384_SHA384":        {"ES384", crypto.SHA384, elliptic.P384()},
+	"RSA_SIGN_PKCS1_2048_SHA256": {"RS256", crypto.SHA256, nil},
+	"RSA_SIGN_PKCS1_3072_SHA256": {"RS256", crypto.SHA256, nil},
+	"RSA_SIGN_PKCS1_4096_SHA256": {"RS256", crypto.SHA256, nil},
+	"RSA_SIGN_PKCS1_4096_SHA512": {"RS512", crypto.SHA512, nil},
+	"RSA_SIGN_PSS_2048_SHA256":   {"PS256", crypto.SHA256, nil},
+	"RSA_SIGN_PSS_3072_SHA256":   {"PS256", crypto.SHA256, nil},
+	"RSA_SIGN_PSS_4096_SHA256":   {"PS256", crypto.SHA256, nil},
+	"RSA_SIGN_PSS_4096_SHA512":   {"PS512", crypto.SHA512, nil},
+}
+
+// Signer is a jwt.Signer that signs with a Cloud KMS crypto key
+// version. Cloud KMS has no verification operation, so signatures are
+// verified locally with the public key. The key arguments of Sign and
+// Verify are ignored.
+type Signer struct {
+	client Client
+	name   string
+	algo   algorithm
+	pub    crypto.PublicKey
+	local  jwt.KeySigner
+}
+
+// New returns a new Signer for the crypto key version resource name,
+// such as "projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1".
+// The alg is determined by the key algorithm.
+func New(ctx context.Context, client Client, name string) (*Signer, error) {
+	pk, err := client.GetPublicKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	algo, ok := algorithms[pk.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, pk.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(pk.PEM))
+	if block == nil {
+		return nil, ErrPublicKey
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPublicKey, err)
+	}
+	s, _ := jwt.LookupSigner(algo.alg)
+	local, ok := s.(jwt.KeySigner)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, pk.Algorithm)
+	}
+	return &Signer{client: client, name: name, algo: algo, pub: pub, local: local}, nil
+}
+
+// Sign implements the jwt.Signer interface.
+func (s *Signer) Sign(b, key []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), b)
+}
+
+// SignContext returns the signature of the data.
+func (s *Signer) SignContext(ctx context.Context, b []byte) ([]byte, error) {
+	if !s.algo.hash.Available() {
+		return nil, jwt.ErrHashUnavailable
+	}
+	h := s.algo.hash.New()
+	h.Write(b)
+	sig, err := s.client.AsymmetricSign(ctx, s.name, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	if s.algo.curve != nil {
+		return jwt.ECDSASignatureToRaw(sig, s.algo.curve)
+	}
+	return sig, nil
+}
+
+// Verify implements the jwt.Signer interface.
+func (s *Signer) Verify(b, sig, key []byte) error {
+	return s.local.VerifyKey(b, sig, s.pub)
+}
+
+// SignToken returns the signed token like t.Sign, passing ctx to KMS.
+// The token must have been created with jwt.New(s), otherwise
+// ErrSignerMismatch is returned.
+func (s *Signer) SignToken(ctx context.Context, t *jwt.Token) (string, error) {
+	input, err := t.SigningInput()
+	if err != nil {
+		return "", err
+	}
+	if t.Header[jwt.HeaderAlgorithm] != s.String() {
+		return "", ErrSignerMismatch
+	}
+	sig, err := s.SignContext(ctx, []byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// PublicKey returns the public key of the crypto key version.
+func (s *Signer) PublicKey() crypto.PublicKey {
+	return s.pub
+}
+
+// String implements the fmt.Stringer interface.
+func (s *Signer) String() string {
+	return s.algo.alg
+}
@@ -0,0 +1,101 @@
+package kmsgcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+// fakeKey is a crypto key version backed by a local key.
+type fakeKey struct {
+	priv      crypto.Signer
+	algorithm string
+	opts      crypto.SignerOpts
+}
+
+// fakeKMS is a Client backed by local keys.
+type fakeKMS map[string]fakeKey
+
+func (f fakeKMS) AsymmetricSign(ctx context.Context, name string, digest []byte) ([]byte, error) {
+	k := f[name]
+	return k.priv.Sign(rand.Reader, digest, k.opts)
+}
+
+func (f fakeKMS) GetPublicKey(ctx context.Context, name string) (PublicKey, error) {
+	k, ok := f[name]
+	if !ok {
+		return PublicKey{}, errors.New("not found")
+	}
+	der, err := x509.MarshalPKIXPublicKey(k.priv.Public())
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKey{PEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), Algorithm: k.algorithm}, nil
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fakeKMS{
+		"rsa":     {rsaKey, "RSA_SIGN_PKCS1_2048_SHA256", crypto.SHA256},
+		"pss":     {rsaKey, "RSA_SIGN_PSS_2048_SHA256", &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}},
+		"ec":      {ecKey, "EC_SIGN_P256_SHA256", crypto.SHA256},
+		"unknown": {ecKey, "EC_SIGN_SECP256K1_SHA256", crypto.SHA256},
+	}
+	var tests = []struct {
+		name string
+		alg  string
+		err  error
+	}{
+		{"rsa", "RS256", nil},
+		{"pss", "PS256", nil},
+		{"ec", "ES256", nil},
+		{"unknown", "", ErrAlgorithm},
+	}
+	for i, tt := range tests {
+		s, err := New(context.Background(), client, tt.name)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. New err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if s.String() != tt.alg {
+			t.Errorf("%d. String\nhave %s\nwant %s", i, s.String(), tt.alg)
+		}
+		raw, err := s.SignToken(context.Background(), jwt.New(s))
+		if err != nil {
+			t.Errorf("%d. SignToken err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		_, err = jwt.Parse(s, raw, nil)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+		}
+		signer, _ := jwt.LookupSigner(tt.alg)
+		_, err = jwt.ParseWithKey(signer, raw, s.PublicKey())
+		if err != nil {
+			t.Errorf("%d. ParseWithKey err\nhave %v\nwant %v", i, err, nil)
+		}
+		_, err = s.SignToken(context.Background(), jwt.New(jwt.HS256))
+		if err != ErrSignerMismatch {
+			t.Errorf("%d. SignToken err\nhave %v\nwant %v", i, err, ErrSignerMismatch)
+		}
+	}
+}
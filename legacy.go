@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"encoding/base64"
+)
+
+// legacyEncoding is the padded standard base64 encoding of legacy tokens.
+var legacyEncoding = base64.StdEncoding
+
+// WithLegacyBase64 returns an option that also accepts tokens whose
+// segments are padded standard base64, as emitted by some legacy
+// issuers, rather than the unpadded URL-safe base64 required by
+// RFC 7515. It is intended for migration windows; strict decoding is
+// the default. The signature is verified over the segments as received.
+func WithLegacyBase64() Option {
+	return func(v *Verifier) {
+		v.legacyBase64 = true
+	}
+}
+
+// SignLegacyBase64 returns the signed token like Sign with segments
+// encoded as padded standard base64, for legacy consumers that do not
+// accept RFC 7515 tokens. Such tokens are only accepted by verifiers
+// configured with WithLegacyBase64.
+func (t *Token) SignLegacyBase64(key []byte) (string, error) {
+	jwt, err := t.signingInput(legacyEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	sig, err := t.signer.Sign([]byte(jwt), key)
+	if err != nil {
+		return "", err
+	}
+	return jwt + sep + legacyEncoding.EncodeToString(sig), nil
+}
+
+// decode returns the decoded segment s, falling back to the legacy
+// encoding if the verifier accepts it.
+func (v *Verifier) decode(s string) ([]byte, error) {
+	b, err := decode(s)
+	if err != nil && v.legacyBase64 {
+		if legacy, lerr := legacyEncoding.DecodeString(s); lerr == nil {
+			return legacy, nil
+		}
+	}
+	return b, err
+}
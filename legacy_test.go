@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLegacyBase64(t *testing.T) {
+	key := []byte("secret")
+	token := New(HS256)
+	token.Claims["sub"] = "subject?>"
+	legacy, err := token.SignLegacyBase64(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.ContainsAny(legacy, "+/=") {
+		t.Fatalf("token should use padded standard base64: %s", legacy)
+	}
+	_, err = Parse(HS256, legacy, key)
+	if err == nil {
+		t.Fatalf("legacy tokens must be rejected by default")
+	}
+	parsed, err := Parse(HS256, legacy, key, WithLegacyBase64())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed.Claims, token.Claims) {
+		t.Fatalf("have %v\nwant %v", parsed.Claims, token.Claims)
+	}
+	strict, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(HS256, strict, key, WithLegacyBase64())
+	if err != nil {
+		t.Fatalf("strict tokens must still be accepted: %v", err)
+	}
+	_, err = Parse(HS256, legacy, []byte("other"), WithLegacyBase64())
+	if err != ErrInvalidSignature {
+		t.Fatalf("Parse err\nhave %v\nwant %v", err, ErrInvalidSignature)
+	}
+}
@@ -0,0 +1,116 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimit is wrapped by a *LimitError.
+var ErrLimit = errors.New("jwt: limit exceeded")
+
+// Default parse limits.
+const (
+	DefaultMaxHeaderParams = 32
+	DefaultMaxClaims       = 256
+	DefaultMaxDepth        = 16
+)
+
+// Limits bounds the work done parsing hostile header and claims
+// segments. Zero fields use the default limits.
+type Limits struct {
+	// HeaderParams is the maximum number of header parameters.
+	HeaderParams int
+
+	// Claims is the maximum number of top-level claims.
+	Claims int
+
+	// Depth is the maximum nesting depth of JSON objects and arrays,
+	// where the header and claims objects themselves have depth 1.
+	Depth int
+}
+
+// WithLimits returns an option that sets the parse limits.
+func WithLimits(l Limits) Option {
+	return func(v *Verifier) {
+		v.limits = l
+	}
+}
+
+// LimitError is returned when the header or claims segment of a token
+// exceeds a parse limit.
+type LimitError struct {
+	// Segment is the token segment, "header" or "claims".
+	Segment string
+
+	// Limit is the exceeded limit, "members" or "depth".
+	Limit string
+
+	// Max is the value of the exceeded limit.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("jwt: %s exceeds maximum %s of %d", e.Segment, e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimit.
+func (e *LimitError) Unwrap() error {
+	return ErrLimit
+}
+
+// members returns the maximum number of top-level members of segment.
+func (l Limits) members(segment string) int {
+	if segment == "header" {
+		return orDefault(l.HeaderParams, DefaultMaxHeaderParams)
+	}
+	return orDefault(l.Claims, DefaultMaxClaims)
+}
+
+func orDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// check scans the JSON segment b and returns a *LimitError if it has
+// too many top-level members or is nested too deeply. It does not
+// validate the JSON, which is left to the decoder.
+func (l Limits) check(segment string, b []byte) error {
+	maxMembers := l.members(segment)
+	maxDepth := orDefault(l.Depth, DefaultMaxDepth)
+	depth, members := 0, 0
+	inString, escaped := false, false
+	for _, c := range b {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return &LimitError{Segment: segment, Limit: "depth", Max: maxDepth}
+			}
+		case '}', ']':
+			depth--
+		case ':':
+			if depth == 1 {
+				members++
+				if members > maxMembers {
+					return &LimitError{Segment: segment, Limit: "members", Max: maxMembers}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	var tests = []struct {
+		header  string
+		payload string
+		limits  Limits
+		err     *LimitError
+	}{
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":{"b":[1,2]}}`, Limits{}, nil},
+		{`{"alg":"HS256","typ":"JWT","x":1}`, `{}`, Limits{HeaderParams: 2}, &LimitError{"header", "members", 2}},
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":1,"b":2,"c":3}`, Limits{Claims: 2}, &LimitError{"claims", "members", 2}},
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":{"b":{"c":1},"d":2}}`, Limits{Claims: 1}, nil},
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":"{{{:::"}`, Limits{Claims: 1, Depth: 1}, nil},
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":"\"{"}`, Limits{Depth: 1}, nil},
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":[[1]]}`, Limits{Depth: 2}, &LimitError{"claims", "depth", 2}},
+		{`{"alg":"HS256","typ":"JWT"}`, `{"a":` + strings.Repeat("[", 1000) + strings.Repeat("]", 1000) + `}`, Limits{}, &LimitError{"claims", "depth", DefaultMaxDepth}},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, tt.header, tt.payload), []byte("secret"), WithLimits(tt.limits))
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+			}
+			continue
+		}
+		var e *LimitError
+		if !errors.As(err, &e) || *e != *tt.err || !errors.Is(err, ErrLimit) {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// TokenManager keeps a token obtained from an issuer fresh for use by
+// a client, fetching a replacement before the exp claim of the current
+// token. Once started, replacements are fetched in the background. It
+// implements the Component interface.
+type TokenManager struct {
+	fetch    func(ctx context.Context) (string, error)
+	margin   time.Duration
+	minDelay time.Duration
+	refresh  sync.Mutex
+	mu       sync.RWMutex
+	token    string
+	exp      time.Time
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewTokenManager returns a new TokenManager obtaining tokens from
+// fetch and replacing them margin before they expire. The tokens are
+// not verified. Tokens without an exp claim are never replaced.
+func NewTokenManager(fetch func(ctx context.Context) (string, error), margin time.Duration) *TokenManager {
+	return &TokenManager{fetch: fetch, margin: margin, minDelay: time.Second}
+}
+
+// Token returns the current token, fetching a replacement first if
+// there is no token or it is due to be replaced.
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.RLock()
+	token, due := m.token, m.due()
+	m.mu.RUnlock()
+	if token != "" && !due {
+		return token, nil
+	}
+	return m.update(ctx)
+}
+
+// due returns true if the current token is due to be replaced.
+func (m *TokenManager) due() bool {
+	return !m.exp.IsZero() && !time.Now().Before(m.exp.Add(-m.margin))
+}
+
+// update fetches a replacement token unless a concurrent caller just
+// did. The fetch is made without holding the lock on the token.
+func (m *TokenManager) update(ctx context.Context) (string, error) {
+	m.refresh.Lock()
+	defer m.refresh.Unlock()
+	m.mu.RLock()
+	token, due := m.token, m.due()
+	m.mu.RUnlock()
+	if token != "" && !due {
+		return token, nil
+	}
+	token, err := m.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	exp, err := expiration(token)
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	m.token, m.exp = token, exp
+	m.mu.Unlock()
+	return token, nil
+}
+
+// expiration returns the unverified exp claim of the token, or the zero
+// time if there is none.
+func expiration(token string) (time.Time, error) {
+	i, err := Inspect(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var c Claims
+	err = json.Unmarshal(i.Payload, &c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	exp, _ := c.Time(ClaimExpiration)
+	return exp, nil
+}
+
+// Start fetches a token and replaces it in the background until ctx is
+// done or the manager is closed. It implements the Component interface.
+func (m *TokenManager) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	m.mu.Lock()
+	if m.done != nil {
+		m.mu.Unlock()
+		cancel()
+		return ErrStarted
+	}
+	m.cancel, m.done = cancel, done
+	m.mu.Unlock()
+	_, err := m.update(ctx)
+	if err != nil {
+		cancel()
+		m.mu.Lock()
+		if m.done == done {
+			m.cancel, m.done = nil, nil
+		}
+		m.mu.Unlock()
+		close(done)
+		return err
+	}
+	go m.run(ctx, done)
+	return nil
+}
+
+// Close stops the background refresh and waits for it to terminate.
+// It implements the Component interface.
+func (m *TokenManager) Close() error {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// run replaces the token when it is due until ctx is done. Failed
+// fetches are retried after minRefreshInterval.
+func (m *TokenManager) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	var delay time.Duration
+	for {
+		m.mu.RLock()
+		exp := m.exp
+		m.mu.RUnlock()
+		if exp.IsZero() {
+			<-ctx.Done()
+			return
+		}
+		if delay == 0 {
+			delay = max(time.Until(exp.Add(-m.margin)), m.minDelay)
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		delay = 0
+		_, err := m.update(ctx)
+		if err != nil && ctx.Err() == nil {
+			delay = minRefreshInterval
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenManager(t *testing.T) {
+	var fetches atomic.Int32
+	ttl := time.Hour
+	fetch := func(ctx context.Context) (string, error) {
+		fetches.Add(1)
+		token := New(HS256)
+		token.Claims[ClaimExpiration] = time.Now().Add(ttl).Unix()
+		return token.Sign([]byte("secret"))
+	}
+	m := NewTokenManager(fetch, time.Minute)
+	a, err := m.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b || fetches.Load() != 1 {
+		t.Errorf("should reuse a token that is not due, have %d fetches", fetches.Load())
+	}
+
+	// Tokens expiring within the margin are always due.
+	ttl = 0
+	fetches.Store(0)
+	m = NewTokenManager(fetch, time.Minute)
+	m.minDelay = time.Millisecond
+	err = m.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Start(context.Background()) != ErrStarted {
+		t.Fatalf("Start err\nhave %v\nwant %v", err, ErrStarted)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for fetches.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if fetches.Load() < 3 {
+		t.Fatalf("should refresh in the background, have %d fetches", fetches.Load())
+	}
+	err = m.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := fetches.Load()
+	time.Sleep(20 * time.Millisecond)
+	if fetches.Load() != n {
+		t.Errorf("should stop refreshing after Close")
+	}
+
+	errFetch := errors.New("fetch failed")
+	m = NewTokenManager(func(ctx context.Context) (string, error) {
+		return "", errFetch
+	}, time.Minute)
+	err = m.Start(context.Background())
+	if err != errFetch {
+		t.Fatalf("Start err\nhave %v\nwant %v", err, errFetch)
+	}
+	err = m.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
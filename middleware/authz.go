@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// ErrForbidden is returned when a verified token does not grant a
+// required scope or role.
+var ErrForbidden = errors.New("middleware: insufficient scope")
+
+// RolesClaim is the claim holding the roles granted to the subject, as
+// an array of strings or a space-delimited string.
+const RolesClaim = "roles"
+
+// WithDecisionCache returns an option that caches the authorization
+// decisions of RequireScope and RequireRole in c.
+func WithDecisionCache(c *DecisionCache) Option {
+	return func(m *Middleware) {
+		m.decisions = c
+	}
+}
+
+// RequireScope returns a handler that calls next only if the verified
+// token in the request context grants every scope in its scope claim.
+// It must be wrapped by Handler or Optional.
+func (m *Middleware) RequireScope(next http.Handler, scopes ...string) http.Handler {
+	return m.require(next, "scope\x00"+strings.Join(scopes, "\x00"), func(t *jwt.Token) bool {
+		return grants(t.Claims[jwt.ClaimScope], scopes)
+	})
+}
+
+// RequireRole returns a handler that calls next only if the verified
+// token in the request context grants every role in its roles claim.
+// It must be wrapped by Handler or Optional.
+func (m *Middleware) RequireRole(next http.Handler, roles ...string) http.Handler {
+	return m.require(next, "role\x00"+strings.Join(roles, "\x00"), func(t *jwt.Token) bool {
+		return grants(t.Claims[RolesClaim], roles)
+	})
+}
+
+func (m *Middleware) require(next http.Handler, requirement string, allow func(*jwt.Token) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, ok := FromContext(r.Context())
+		if !ok {
+			m.onError(w, r, ErrNoToken)
+			return
+		}
+		var allowed bool
+		raw, _ := r.Context().Value(rawKey).(string)
+		if m.decisions != nil && raw != "" {
+			allowed = m.decisions.decide(raw, t, requirement, allow)
+		} else {
+			allowed = allow(t)
+		}
+		if !allowed {
+			m.onError(w, r, ErrForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// grants returns true if the claim value grants every required value.
+func grants(claim interface{}, required []string) bool {
+	var granted []string
+	switch v := claim.(type) {
+	case string:
+		granted = strings.Fields(v)
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				granted = append(granted, s)
+			}
+		}
+	case []string:
+		granted = v
+	}
+	for _, want := range required {
+		found := false
+		for _, have := range granted {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// DecisionCache is a bounded cache of authorization decisions keyed by
+// token and requirement. Entries expire at the earlier of the exp claim
+// of the token and the configured ttl, so a decision never outlives the
+// token. When the cache is full the oldest entry is evicted. Tokens are
+// identified by their SHA-256 hash and are never stored.
+type DecisionCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[decisionKey]decision
+	order   []decisionKey
+	next    int
+}
+
+type decisionKey struct {
+	token       [sha256.Size]byte
+	requirement string
+}
+
+type decision struct {
+	allowed bool
+	expires time.Time
+}
+
+// NewDecisionCache returns a new DecisionCache holding up to size
+// decisions for at most ttl. It returns nil, disabling the cache, if
+// size or ttl is not positive.
+func NewDecisionCache(size int, ttl time.Duration) *DecisionCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &DecisionCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[decisionKey]decision, size),
+		order:   make([]decisionKey, 0, size),
+	}
+}
+
+// decide returns the cached decision of the requirement for the token,
+// evaluating and caching it with allow if it is not cached.
+func (c *DecisionCache) decide(raw string, t *jwt.Token, requirement string, allow func(*jwt.Token) bool) bool {
+	k := decisionKey{token: sha256.Sum256([]byte(raw)), requirement: requirement}
+	now := c.now()
+	c.mu.Lock()
+	d, ok := c.entries[k]
+	c.mu.Unlock()
+	if ok && now.Before(d.expires) {
+		return d.allowed
+	}
+	d = decision{allowed: allow(t), expires: now.Add(c.ttl)}
+	if exp, ok := jwt.Claims(t.Claims).Time(jwt.ClaimExpiration); ok && exp.Before(d.expires) {
+		d.expires = exp
+	}
+	if !now.Before(d.expires) || cap(c.order) == 0 {
+		return d.allowed
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[k]; !ok {
+		if len(c.order) < cap(c.order) {
+			c.order = append(c.order, k)
+		} else {
+			delete(c.entries, c.order[c.next])
+			c.order[c.next] = k
+			c.next = (c.next + 1) % len(c.order)
+		}
+	}
+	c.entries[k] = d
+	return d.allowed
+}
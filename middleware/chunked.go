@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Chunked token headers. A token too large for a single header is sent
+// in the headers X-Token-1 through X-Token-N, with the number of chunks
+// and the digest of the whole token for reassembly.
+const (
+	ChunkHeader       = "X-Token"
+	ChunkCountHeader  = "X-Token-Chunks"
+	ChunkDigestHeader = "X-Token-Digest"
+
+	// ChunkSizeHeader advertises the largest chunk a server accepts.
+	ChunkSizeHeader = "X-Token-Chunk-Size"
+)
+
+// MaxChunks is the maximum number of chunks of a token.
+const MaxChunks = 32
+
+// minChunkSize is the smallest chunk size negotiated by Transport.
+const minChunkSize = 256
+
+// maxNegotiations is the maximum number of times Transport retries a
+// request with a smaller chunk size.
+const maxNegotiations = 4
+
+// ErrChunkedToken is returned when chunked token headers are malformed,
+// incomplete or do not match their digest.
+var ErrChunkedToken = errors.New("middleware: invalid chunked token")
+
+// WithChunkedTokens returns an option that accepts tokens sent in
+// chunked headers by requests without an Authorization header. Requests
+// failing authentication are answered with size advertised in the
+// ChunkSizeHeader, allowing clients such as Transport to negotiate a
+// chunk size that intermediate proxies accept.
+func WithChunkedTokens(size int) Option {
+	return func(m *Middleware) {
+		m.chunked = true
+		m.chunkSize = size
+	}
+}
+
+// SetChunkedToken sets the chunked headers of raw in h, splitting raw
+// into chunks of at most size bytes, and removes any Authorization
+// header.
+func SetChunkedToken(h http.Header, raw string, size int) error {
+	if size <= 0 || raw == "" {
+		return ErrChunkedToken
+	}
+	n := (len(raw) + size - 1) / size
+	if n > MaxChunks {
+		return ErrChunkedToken
+	}
+	h.Del("Authorization")
+	for i := 0; i < n; i++ {
+		h.Set(chunkHeader(i+1), raw[i*size:min((i+1)*size, len(raw))])
+	}
+	h.Set(ChunkCountHeader, strconv.Itoa(n))
+	h.Set(ChunkDigestHeader, chunkDigest(raw))
+	return nil
+}
+
+// ChunkedToken returns the token reassembled from the chunked headers
+// of h. It returns ErrNoToken if h has no chunked token. The digest
+// detects chunks lost or altered in transit; the token signature must
+// still be verified.
+func ChunkedToken(h http.Header) (string, error) {
+	count := h.Get(ChunkCountHeader)
+	if count == "" {
+		return "", ErrNoToken
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 || n > MaxChunks {
+		return "", ErrChunkedToken
+	}
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		v := h.Values(chunkHeader(i))
+		if len(v) != 1 || v[0] == "" {
+			return "", ErrChunkedToken
+		}
+		b.WriteString(v[0])
+	}
+	if h.Get(chunkHeader(n+1)) != "" {
+		return "", ErrChunkedToken
+	}
+	raw := b.String()
+	digest := h.Get(ChunkDigestHeader)
+	if subtle.ConstantTimeCompare([]byte(digest), []byte(chunkDigest(raw))) != 1 {
+		return "", ErrChunkedToken
+	}
+	return raw, nil
+}
+
+// chunkHeader returns the name of the header of chunk i.
+func chunkHeader(i int) string {
+	return ChunkHeader + "-" + strconv.Itoa(i)
+}
+
+// chunkDigest returns the digest of the token for ChunkDigestHeader.
+func chunkDigest(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// negotiate returns the chunk size to retry a request with after resp,
+// given the token length and the chunk size used, or false if the
+// request should not be retried. A server advertising a smaller chunk
+// size is retried with that size, and a request rejected for oversized
+// headers without an advertised size is retried with half the size.
+func negotiate(resp *http.Response, n, size int) (int, bool) {
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		return 0, false
+	}
+	used := n
+	if size > 0 && size < n {
+		used = size
+	}
+	next, err := strconv.Atoi(resp.Header.Get(ChunkSizeHeader))
+	if err != nil || next <= 0 {
+		if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+			return 0, false
+		}
+		next = used / 2
+	}
+	next = max(next, minChunkSize)
+	if next >= used {
+		return 0, false
+	}
+	return next, true
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// Minter mints short-lived on-behalf-of tokens for downstream calls
+// from a verified inbound token.
+type Minter struct {
+	// Signer and Key sign the downstream tokens.
+	Signer jwt.Signer
+	Key    []byte
+
+	// Issuer is the iss claim, identifying this service.
+	Issuer string
+
+	// Actor is the sub of the act claim, identifying this service as
+	// acting on behalf of the inbound subject.
+	Actor string
+
+	// Audience is the aud claim of the downstream tokens.
+	Audience string
+
+	// Scopes limits the downstream scope to the intersection with the
+	// inbound scope claim. A nil slice propagates the inbound scope.
+	// Minting fails with ErrForbidden if the intersection is empty.
+	Scopes []string
+
+	// TTL is the lifetime of the downstream tokens. Defaults to one minute.
+	TTL time.Duration
+}
+
+// WithDownstream returns an option that mints a downstream token with m
+// for each authenticated request. The token is available to handlers
+// with DownstreamToken and is attached to outgoing requests by Transport.
+func WithDownstream(m *Minter) Option {
+	return func(mw *Middleware) {
+		mw.minter = m
+	}
+}
+
+// Mint returns a downstream token acting on behalf of the subject of t.
+// An existing act claim is nested to preserve the delegation chain.
+//
+// See RFC 8693 Section 4.1.
+func (m *Minter) Mint(t *jwt.Token) (string, error) {
+	ttl := m.TTL
+	if ttl == 0 {
+		ttl = time.Minute
+	}
+	now := time.Now()
+	act := map[string]interface{}{"sub": m.Actor}
+	if prev, ok := t.Claims[jwt.ClaimActor]; ok {
+		act["act"] = prev
+	}
+	d := jwt.New(m.Signer)
+	d.Claims[jwt.ClaimSubject] = t.Claims[jwt.ClaimSubject]
+	d.Claims[jwt.ClaimActor] = act
+	d.Claims[jwt.ClaimIssuedAt] = now.Unix()
+	d.Claims[jwt.ClaimExpiration] = now.Add(ttl).Unix()
+	if m.Issuer != "" {
+		d.Claims[jwt.ClaimIssuer] = m.Issuer
+	}
+	if m.Audience != "" {
+		d.Claims[jwt.ClaimAudience] = m.Audience
+	}
+	scope, _ := t.Claims[jwt.ClaimScope].(string)
+	if m.Scopes != nil {
+		scope = intersect(scope, m.Scopes)
+		if scope == "" {
+			return "", ErrForbidden
+		}
+	}
+	if scope != "" {
+		d.Claims[jwt.ClaimScope] = scope
+	}
+	return d.Sign(m.Key)
+}
+
+// intersect returns the space-delimited scopes also in allowed.
+func intersect(scope string, allowed []string) string {
+	var rv []string
+	for _, s := range strings.Fields(scope) {
+		for _, a := range allowed {
+			if s == a {
+				rv = append(rv, s)
+				break
+			}
+		}
+	}
+	return strings.Join(rv, " ")
+}
+
+// DownstreamToken returns the downstream token minted for the request.
+func DownstreamToken(ctx context.Context) (string, bool) {
+	raw, ok := ctx.Value(downstreamKey).(string)
+	return raw, ok
+}
+
+// Transport is an http.RoundTripper that sets the Authorization header
+// of outgoing requests to the downstream token in the request context.
+//
+// Tokens longer than ChunkSize are sent in chunked headers instead. If
+// the response advertises a smaller chunk size or rejects the headers
+// as too large, the request is retried with smaller chunk sizes and the
+// accepted size is used for later requests.
+type Transport struct {
+	// Base is the underlying transport. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// ChunkSize is the largest token sent in a single header. Zero
+	// sends tokens in the Authorization header until a server
+	// advertises a chunk size.
+	ChunkSize int
+
+	negotiated atomic.Int64
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	raw, ok := DownstreamToken(r.Context())
+	if !ok {
+		return base.RoundTrip(r)
+	}
+	size := t.ChunkSize
+	if n := t.negotiated.Load(); n > 0 {
+		size = int(n)
+	}
+	req, err := withToken(r, raw, size)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := base.RoundTrip(req)
+	for i := 0; err == nil && i < maxNegotiations; i++ {
+		next, ok := negotiate(resp, len(raw), size)
+		if !ok || (r.Body != nil && r.Body != http.NoBody && r.GetBody == nil) {
+			break
+		}
+		resp.Body.Close()
+		size = next
+		t.negotiated.Store(int64(size))
+		req, err = withToken(r, raw, size)
+		if err != nil {
+			return nil, err
+		}
+		if r.GetBody != nil {
+			req.Body, err = r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+		resp, err = base.RoundTrip(req)
+	}
+	return resp, err
+}
+
+// withToken returns a copy of r carrying raw in the Authorization
+// header, or in chunked headers if raw is longer than size.
+func withToken(r *http.Request, raw string, size int) (*http.Request, error) {
+	r = r.Clone(r.Context())
+	if size <= 0 || len(raw) <= size {
+		r.Header.Set("Authorization", "Bearer "+raw)
+		return r, nil
+	}
+	err := SetChunkedToken(r.Header, raw, size)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
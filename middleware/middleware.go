@@ -0,0 +1,189 @@
+// Package middleware implements HTTP middleware that authenticates
+// requests with bearer tokens.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pnelson/jwt"
+)
+
+// ErrNoToken is returned when a request does not carry a bearer token.
+var ErrNoToken = errors.New("middleware: no bearer token")
+
+// ErrMint is returned when a downstream token cannot be minted for an
+// authenticated request. It is a server error rather than an
+// authentication failure.
+var ErrMint = errors.New("middleware: unable to mint downstream token")
+
+type contextKey int
+
+const (
+	tokenKey contextKey = iota
+	downstreamKey
+	anonymousKey
+	rawKey
+)
+
+// Middleware authenticates requests with bearer tokens.
+type Middleware struct {
+	verifier  jwt.TokenVerifier
+	minter    *Minter
+	onError   func(w http.ResponseWriter, r *http.Request, err error)
+	tenant    func(r *http.Request) (string, bool)
+	decisions *DecisionCache
+	chunked   bool
+	chunkSize int
+}
+
+// Option configures a Middleware.
+type Option func(*Middleware)
+
+// WithErrorHandler returns an option that sets the handler called when
+// authentication fails. The default responds with 401 Unauthorized,
+// 403 Forbidden for ErrForbidden, or 500 Internal Server Error for ErrMint.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(m *Middleware) {
+		m.onError = fn
+	}
+}
+
+// WithTenant returns an option that stores the expected tenant of each
+// request returned by fn, such as the organization in the request path
+// or host, in the request context before verifying the token. It is
+// used with a verifier configured with jwt.WithTenant.
+func WithTenant(fn func(r *http.Request) (string, bool)) Option {
+	return func(m *Middleware) {
+		m.tenant = fn
+	}
+}
+
+// New returns a new Middleware verifying tokens with v.
+func New(v jwt.TokenVerifier, opts ...Option) *Middleware {
+	m := &Middleware{verifier: v, onError: unauthorized}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Handler returns a handler that verifies the bearer token of each
+// request before calling next with the token in the request context.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return m.handler(next, false)
+}
+
+// Optional returns a handler like Handler for routes that also serve
+// anonymous traffic. Requests without an Authorization header are passed
+// to next as anonymous; see Anonymous. Requests with invalid credentials
+// still fail.
+func (m *Middleware) Optional(next http.Handler) http.Handler {
+	return m.handler(next, true)
+}
+
+func (m *Middleware) handler(next http.Handler, optional bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := Bearer(r)
+		if !ok && m.chunked && r.Header.Get("Authorization") == "" {
+			var err error
+			raw, err = ChunkedToken(r.Header)
+			if err != ErrNoToken {
+				if err != nil {
+					m.fail(w, r, err)
+					return
+				}
+				ok = true
+			}
+		}
+		if !ok && optional && r.Header.Get("Authorization") == "" {
+			ctx := context.WithValue(r.Context(), anonymousKey, true)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		if !ok {
+			m.fail(w, r, ErrNoToken)
+			return
+		}
+		if m.tenant != nil {
+			if tenant, ok := m.tenant(r); ok {
+				r = r.WithContext(jwt.ContextWithTenant(r.Context(), tenant))
+			}
+		}
+		t, err := m.verifier.Verify(r.Context(), raw)
+		if err != nil {
+			m.fail(w, r, err)
+			return
+		}
+		ctx := context.WithValue(r.Context(), tokenKey, t)
+		if m.decisions != nil {
+			ctx = context.WithValue(ctx, rawKey, raw)
+		}
+		if m.minter != nil {
+			downstream, err := m.minter.Mint(t)
+			if err != nil && err != ErrForbidden {
+				err = fmt.Errorf("%w: %v", ErrMint, err)
+			}
+			if err != nil {
+				m.onError(w, r, err)
+				return
+			}
+			ctx = context.WithValue(ctx, downstreamKey, downstream)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// fail calls the error handler for a request failing authentication,
+// advertising the accepted chunk size if chunked tokens are accepted.
+func (m *Middleware) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if m.chunked && m.chunkSize > 0 {
+		w.Header().Set(ChunkSizeHeader, strconv.Itoa(m.chunkSize))
+	}
+	m.onError(w, r, err)
+}
+
+// FromContext returns the verified token stored in ctx by the middleware.
+func FromContext(ctx context.Context) (*jwt.Token, bool) {
+	t, ok := ctx.Value(tokenKey).(*jwt.Token)
+	return t, ok
+}
+
+// Anonymous returns true if the request carried no credentials and was
+// allowed through by an Optional handler. FromContext returns false for
+// anonymous requests.
+func Anonymous(ctx context.Context) bool {
+	anonymous, _ := ctx.Value(anonymousKey).(bool)
+	return anonymous
+}
+
+// Bearer returns the bearer token of the request Authorization header.
+func Bearer(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "bearer ") {
+		return "", false
+	}
+	raw := strings.TrimSpace(h[7:])
+	return raw, raw != ""
+}
+
+// unauthorized is the default error handler. Tokens lacking a required
+// scope or role are forbidden as described by RFC 6750 Section 3.1,
+// and failures to mint a downstream token are internal server errors.
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrMint) {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if errors.Is(err, ErrForbidden) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
@@ -0,0 +1,459 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+var key = []byte("secret")
+
+func sign(t *testing.T, claims map[string]interface{}) string {
+	token := jwt.New(jwt.HS256)
+	token.Claims = claims
+	raw, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestMiddleware(t *testing.T) {
+	v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	h := New(v).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := FromContext(r.Context())
+		if !ok {
+			t.Fatal("should store token in context")
+		}
+		w.Write([]byte(token.Claims["sub"].(string)))
+	}))
+	var tests = []struct {
+		auth   string
+		status int
+		body   string
+	}{
+		{"Bearer " + sign(t, map[string]interface{}{"sub": "user"}), http.StatusOK, "user"},
+		{"bearer " + sign(t, map[string]interface{}{"sub": "user"}), http.StatusOK, "user"},
+		{"", http.StatusUnauthorized, ""},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		{"Bearer invalid", http.StatusUnauthorized, ""},
+	}
+	for i, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%d. status\nhave %d\nwant %d", i, w.Code, tt.status)
+			continue
+		}
+		if tt.status == http.StatusOK && w.Body.String() != tt.body {
+			t.Errorf("%d. body\nhave %s\nwant %s", i, w.Body.String(), tt.body)
+		}
+	}
+}
+
+type fakeVerifier map[string]*jwt.Token
+
+func (f fakeVerifier) Verify(ctx context.Context, raw string) (*jwt.Token, error) {
+	t, ok := f[raw]
+	if !ok {
+		return nil, jwt.ErrInvalidSignature
+	}
+	return t, nil
+}
+
+func TestMiddlewareFakeVerifier(t *testing.T) {
+	token := &jwt.Token{Claims: map[string]interface{}{"sub": "fake"}}
+	h := New(fakeVerifier{"valid": token}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		have, _ := FromContext(r.Context())
+		if have != token {
+			t.Errorf("should store fake token in context")
+		}
+	}))
+	var tests = []struct {
+		raw    string
+		status int
+	}{
+		{"valid", http.StatusOK},
+		{"invalid", http.StatusUnauthorized},
+	}
+	for i, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.raw)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%d. status\nhave %d\nwant %d", i, w.Code, tt.status)
+		}
+	}
+}
+
+func TestMiddlewareOptional(t *testing.T) {
+	v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	h := New(v).Optional(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Anonymous(r.Context()) {
+			w.Write([]byte("anonymous"))
+			return
+		}
+		token, ok := FromContext(r.Context())
+		if !ok {
+			t.Fatal("should store token in context")
+		}
+		w.Write([]byte(token.Claims["sub"].(string)))
+	}))
+	var tests = []struct {
+		auth   string
+		status int
+		body   string
+	}{
+		{"Bearer " + sign(t, map[string]interface{}{"sub": "user"}), http.StatusOK, "user"},
+		{"", http.StatusOK, "anonymous"},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		{"Bearer invalid", http.StatusUnauthorized, ""},
+	}
+	for i, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%d. status\nhave %d\nwant %d", i, w.Code, tt.status)
+			continue
+		}
+		if tt.status == http.StatusOK && w.Body.String() != tt.body {
+			t.Errorf("%d. body\nhave %s\nwant %s", i, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestMiddlewareTenant(t *testing.T) {
+	v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key), jwt.WithTenant("org_id", nil))
+	tenant := WithTenant(func(r *http.Request) (string, bool) {
+		org := r.URL.Query().Get("org")
+		return org, org != ""
+	})
+	h := New(v, tenant).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var tests = []struct {
+		url    string
+		org    string
+		status int
+	}{
+		{"/?org=a", "a", http.StatusOK},
+		{"/?org=b", "a", http.StatusUnauthorized},
+		{"/", "a", http.StatusUnauthorized},
+	}
+	for i, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		r.Header.Set("Authorization", "Bearer "+sign(t, map[string]interface{}{"org_id": tt.org}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%d. status\nhave %d\nwant %d", i, w.Code, tt.status)
+		}
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	var tests = []struct {
+		claims map[string]interface{}
+		names  []string
+		key    string
+		ok     bool
+	}{
+		{map[string]interface{}{"sub": "user", "client_id": "app", "jti": "1"}, nil, "sub:user", true},
+		{map[string]interface{}{"client_id": "app", "jti": "1"}, nil, "client_id:app", true},
+		{map[string]interface{}{"sub": "", "jti": "1"}, nil, "jti:1", true},
+		{map[string]interface{}{"sub": 1.0}, nil, "", false},
+		{map[string]interface{}{"sub": "user", "client_id": "app"}, []string{"client_id", "sub"}, "client_id:app", true},
+		{map[string]interface{}{}, nil, "", false},
+	}
+	for i, tt := range tests {
+		token := &jwt.Token{Claims: tt.claims}
+		key, ok := RateLimitKey(token, tt.names...)
+		if key != tt.key || ok != tt.ok {
+			t.Errorf("%d. RateLimitKey\nhave %q %v\nwant %q %v", i, key, ok, tt.key, tt.ok)
+		}
+	}
+	_, ok := RateLimitKeyFromContext(context.Background())
+	if ok {
+		t.Errorf("should not find key without token")
+	}
+}
+
+func TestDownstream(t *testing.T) {
+	downstreamKey := []byte("downstream")
+	var outbound string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outbound, _ = Bearer(r)
+	}))
+	defer backend.Close()
+	client := &http.Client{Transport: &Transport{}}
+	v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	m := &Minter{
+		Signer:   jwt.HS256,
+		Key:      downstreamKey,
+		Issuer:   "gateway",
+		Actor:    "gateway",
+		Audience: "orders",
+		Scopes:   []string{"orders:read"},
+	}
+	h := New(v, WithDownstream(m)).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, backend.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+sign(t, map[string]interface{}{
+		"sub":   "user",
+		"scope": "orders:read orders:write",
+		"act":   map[string]interface{}{"sub": "frontend"},
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	d, err := jwt.Parse(jwt.HS256, outbound, downstreamKey, jwt.WithAudience("orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Claims["sub"] != "user" || d.Claims["scope"] != "orders:read" {
+		t.Errorf("unexpected claims %v", d.Claims)
+	}
+	act := map[string]interface{}{"sub": "gateway", "act": map[string]interface{}{"sub": "frontend"}}
+	if !reflect.DeepEqual(d.Claims["act"], act) {
+		t.Errorf("act\nhave %v\nwant %v", d.Claims["act"], act)
+	}
+	_, ok := DownstreamToken(context.Background())
+	if ok {
+		t.Errorf("should not find downstream token")
+	}
+	_, err = m.Mint(&jwt.Token{Claims: map[string]interface{}{"sub": "user", "scope": "profile"}})
+	if err != ErrForbidden {
+		t.Errorf("Mint err\nhave %v\nwant %v", err, ErrForbidden)
+	}
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+sign(t, map[string]interface{}{"sub": "user"}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status\nhave %d\nwant %d", w.Code, http.StatusForbidden)
+	}
+	broken := &Minter{Signer: jwt.ES256, Key: []byte("not a key")}
+	h = New(v, WithDownstream(broken)).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+sign(t, map[string]interface{}{"sub": "user"}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status\nhave %d\nwant %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	var tests = []struct {
+		role   bool
+		claims map[string]interface{}
+		status int
+	}{
+		{false, map[string]interface{}{"scope": "read write delete"}, http.StatusOK},
+		{false, map[string]interface{}{"scope": "read"}, http.StatusForbidden},
+		{false, map[string]interface{}{}, http.StatusForbidden},
+		{true, map[string]interface{}{"roles": []string{"user", "admin"}}, http.StatusOK},
+		{true, map[string]interface{}{"roles": "admin"}, http.StatusOK},
+		{true, map[string]interface{}{"roles": []string{"user"}}, http.StatusForbidden},
+	}
+	for _, m := range []*Middleware{
+		New(v),
+		New(v, WithDecisionCache(NewDecisionCache(16, time.Minute))),
+		New(v, WithDecisionCache(NewDecisionCache(-1, time.Minute))),
+	} {
+		scope := m.Handler(m.RequireScope(ok, "read", "write"))
+		role := m.Handler(m.RequireRole(ok, "admin"))
+		for i, tt := range tests {
+			h := scope
+			if tt.role {
+				h = role
+			}
+			for j := 0; j < 2; j++ {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("Authorization", "Bearer "+sign(t, tt.claims))
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				if w.Code != tt.status {
+					t.Errorf("%d. status\nhave %d\nwant %d", i, w.Code, tt.status)
+				}
+			}
+		}
+	}
+}
+
+func TestDecisionCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := NewDecisionCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+	evaluated := 0
+	allow := func(*jwt.Token) bool {
+		evaluated++
+		return true
+	}
+	short := &jwt.Token{Claims: map[string]interface{}{"exp": float64(now.Unix() + 10)}}
+	long := &jwt.Token{Claims: map[string]interface{}{"exp": float64(now.Unix() + 3600)}}
+	c.decide("short", short, "scope", allow)
+	c.decide("short", short, "scope", allow)
+	c.decide("long", long, "scope", allow)
+	if evaluated != 2 {
+		t.Fatalf("evaluated\nhave %d\nwant %d", evaluated, 2)
+	}
+	now = now.Add(30 * time.Second)
+	c.decide("short", short, "scope", allow)
+	c.decide("long", long, "scope", allow)
+	if evaluated != 3 {
+		t.Fatalf("decision must expire with the token\nhave %d\nwant %d", evaluated, 3)
+	}
+	now = now.Add(time.Minute)
+	c.decide("long", long, "scope", allow)
+	if evaluated != 4 {
+		t.Fatalf("decision must expire after the ttl\nhave %d\nwant %d", evaluated, 4)
+	}
+	c.decide("a", long, "scope", allow)
+	c.decide("b", long, "scope", allow)
+	if len(c.entries) != 2 {
+		t.Fatalf("entries\nhave %d\nwant %d", len(c.entries), 2)
+	}
+}
+
+func TestDecisionCacheDisabled(t *testing.T) {
+	var tests = []struct {
+		size int
+		ttl  time.Duration
+	}{
+		{0, time.Minute},
+		{-1, time.Minute},
+		{16, 0},
+	}
+	for i, tt := range tests {
+		c := NewDecisionCache(tt.size, tt.ttl)
+		if c != nil {
+			t.Errorf("%d. NewDecisionCache(%d, %v) should disable the cache", i, tt.size, tt.ttl)
+		}
+	}
+}
+
+func TestChunkedToken(t *testing.T) {
+	raw := sign(t, map[string]interface{}{"sub": strings.Repeat("a", 1000)})
+	h := make(http.Header)
+	h.Set("Authorization", "Bearer "+raw)
+	err := SetChunkedToken(h, raw, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Get("Authorization") != "" || h.Get(ChunkCountHeader) != "5" {
+		t.Fatalf("unexpected headers %v", h)
+	}
+	have, err := ChunkedToken(h)
+	if err != nil || have != raw {
+		t.Fatalf("ChunkedToken\nhave %q, %v\nwant %q", have, err, raw)
+	}
+	var tests = []func(h http.Header){
+		func(h http.Header) { h.Del("X-Token-3") },
+		func(h http.Header) { h.Set("X-Token-2", h.Get("X-Token-2")+"x") },
+		func(h http.Header) { h.Set("X-Token-6", "x") },
+		func(h http.Header) { h.Set(ChunkCountHeader, "4") },
+		func(h http.Header) { h.Set(ChunkCountHeader, "100") },
+		func(h http.Header) { h.Del(ChunkDigestHeader) },
+	}
+	for i, tamper := range tests {
+		c := h.Clone()
+		tamper(c)
+		_, err := ChunkedToken(c)
+		if err != ErrChunkedToken {
+			t.Errorf("%d. ChunkedToken err\nhave %v\nwant %v", i, err, ErrChunkedToken)
+		}
+	}
+	_, err = ChunkedToken(http.Header{})
+	if err != ErrNoToken {
+		t.Fatalf("ChunkedToken err\nhave %v\nwant %v", err, ErrNoToken)
+	}
+}
+
+func TestChunkedTransport(t *testing.T) {
+	const limit = 400
+	v := jwt.NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	var sub string
+	h := New(v, WithChunkedTokens(limit)).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := FromContext(r.Context())
+		sub, _ = token.Claims["sub"].(string)
+	}))
+	want := strings.Repeat("a", 1000)
+	raw := sign(t, map[string]interface{}{"sub": want})
+	var tests = []struct {
+		drop       bool
+		requests   int
+		negotiated int64
+	}{
+		// Rejected in the Authorization header and in halves, then
+		// accepted in quarters.
+		{false, 4, int64(len(raw) / 4)},
+		// Dropped from the Authorization header, then accepted at the
+		// size advertised by the server.
+		{true, 3, limit},
+	}
+	for i, tt := range tests {
+		requests := 0
+		// proxy rejects or drops headers larger than the limit.
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			for name, values := range r.Header {
+				for _, v := range values {
+					if len(v) <= limit {
+						continue
+					}
+					if !tt.drop {
+						w.WriteHeader(http.StatusRequestHeaderFieldsTooLarge)
+						return
+					}
+					r.Header.Del(name)
+				}
+			}
+			h.ServeHTTP(w, r)
+		}))
+		tr := &Transport{}
+		client := &http.Client{Transport: tr}
+		for j := 0; j < 2; j++ {
+			sub = ""
+			ctx := context.WithValue(context.Background(), downstreamKey, raw)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, proxy.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || sub != want {
+				t.Errorf("%d. status\nhave %d\nwant %d", i, resp.StatusCode, http.StatusOK)
+			}
+		}
+		proxy.Close()
+		if requests != tt.requests || tr.negotiated.Load() != tt.negotiated {
+			t.Errorf("%d. requests %d, negotiated %d\nwant %d, %d", i, requests, tr.negotiated.Load(), tt.requests, tt.negotiated)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/pnelson/jwt"
+)
+
+// RateLimitClaims is the default precedence of claims used to derive
+// rate limiting keys.
+var RateLimitClaims = []string{"sub", "client_id", "jti"}
+
+// RateLimitKey returns a stable rate limiting key for the token derived
+// from the first of the named claims that is a non-empty string. The
+// key is prefixed with the claim name so that values of different claims
+// do not collide. If no claims are named, RateLimitClaims is used.
+// It returns false if none of the claims are present.
+func RateLimitKey(t *jwt.Token, claims ...string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	if len(claims) == 0 {
+		claims = RateLimitClaims
+	}
+	for _, name := range claims {
+		v, _ := t.Claims[name].(string)
+		if v != "" {
+			return name + ":" + v, true
+		}
+	}
+	return "", false
+}
+
+// RateLimitKeyFromContext returns the rate limiting key of the verified
+// token stored in ctx by the middleware.
+func RateLimitKeyFromContext(ctx context.Context, claims ...string) (string, bool) {
+	t, _ := FromContext(ctx)
+	return RateLimitKey(t, claims...)
+}
@@ -0,0 +1,134 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SigningKey is a signer and the key it signs with, for signing the
+// same claims with several algorithms.
+type SigningKey struct {
+	Signer Signer
+	Key    []byte
+
+	// KeyID is the kid header of the signature, if not empty.
+	KeyID string
+}
+
+// GeneralJWS is the JWS General JSON Serialization of a payload with
+// one or more signatures.
+//
+// See RFC 7515 Section 7.2.1.
+type GeneralJWS struct {
+	Payload    string         `json:"payload"`
+	Signatures []JWSSignature `json:"signatures"`
+}
+
+// JWSSignature is a single signature of a GeneralJWS.
+type JWSSignature struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+// SignMulti returns the claims signed with each key as compact tokens,
+// in the order of keys. Every token has the same payload, so verifiers
+// that are being migrated to a new algorithm may be sent whichever
+// token they can verify.
+func SignMulti(claims map[string]interface{}, keys ...SigningKey) ([]string, error) {
+	g, err := SignGeneral(claims, keys...)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, len(g.Signatures))
+	for i := range g.Signatures {
+		tokens[i] = g.Compact(i)
+	}
+	return tokens, nil
+}
+
+// SignGeneral returns the claims signed with each key as a GeneralJWS.
+func SignGeneral(claims map[string]interface{}, keys ...SigningKey) (*GeneralJWS, error) {
+	if len(keys) == 0 {
+		return nil, ErrSigner
+	}
+	if claims == nil {
+		claims = make(map[string]interface{})
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	g := &GeneralJWS{Payload: encode(c)}
+	for _, k := range keys {
+		if k.Signer == nil {
+			return nil, ErrSigner
+		}
+		header := map[string]interface{}{
+			HeaderType:      "JWT",
+			HeaderAlgorithm: k.Signer.String(),
+		}
+		if k.KeyID != "" {
+			header[HeaderKeyID] = k.KeyID
+		}
+		h, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		protected := encode(h)
+		sig, err := k.Signer.Sign([]byte(protected+sep+g.Payload), k.Key)
+		if err != nil {
+			return nil, err
+		}
+		g.Signatures = append(g.Signatures, JWSSignature{Protected: protected, Signature: encode(sig)})
+	}
+	return g, nil
+}
+
+// Compact returns the compact token of the signature at index i.
+func (g *GeneralJWS) Compact(i int) string {
+	s := g.Signatures[i]
+	return s.Protected + sep + g.Payload + sep + s.Signature
+}
+
+// Select returns the compact token of the first signature made with
+// one of the algorithms, in the order of algs. Callers list the
+// algorithms they can verify in order of preference.
+func (g *GeneralJWS) Select(algs ...string) (string, bool) {
+	tokens := make([]string, len(g.Signatures))
+	for i := range g.Signatures {
+		tokens[i] = g.Compact(i)
+	}
+	return SelectToken(tokens, algs...)
+}
+
+// SelectToken returns the first token signed with one of the
+// algorithms, in the order of algs. The tokens are not verified.
+func SelectToken(tokens []string, algs ...string) (string, bool) {
+	for _, alg := range algs {
+		for _, jwt := range tokens {
+			if tokenAlgorithm(jwt) == alg {
+				return jwt, true
+			}
+		}
+	}
+	return "", false
+}
+
+// tokenAlgorithm returns the alg header of the unverified token.
+func tokenAlgorithm(jwt string) string {
+	i := strings.Index(jwt, sep)
+	if i < 0 {
+		return ""
+	}
+	b, err := decode(jwt[:i])
+	if err != nil {
+		return ""
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if json.Unmarshal(b, &header) != nil {
+		return ""
+	}
+	return header.Alg
+}
@@ -0,0 +1,86 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+)
+
+func TestSignMulti(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	pubPEM, err := encodePublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]interface{}{"sub": "user"}
+	keys := []SigningKey{
+		{Signer: HS256, Key: []byte("secret"), KeyID: "old"},
+		{Signer: ES256, Key: privPEM, KeyID: "new"},
+	}
+	g, err := SignGeneral(claims, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded GeneralJWS
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		algs   []string
+		signer Signer
+		key    []byte
+		kid    string
+		ok     bool
+	}{
+		{[]string{"ES256", "HS256"}, ES256, pubPEM, "new", true},
+		{[]string{"RS256", "HS256"}, HS256, []byte("secret"), "old", true},
+		{[]string{"RS256"}, nil, nil, "", false},
+	}
+	for i, tt := range tests {
+		jwt, ok := decoded.Select(tt.algs...)
+		if ok != tt.ok {
+			t.Errorf("%d. Select ok\nhave %v\nwant %v", i, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		token, err := Parse(tt.signer, jwt, tt.key)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if token.Header["kid"] != tt.kid || token.Claims["sub"] != "user" {
+			t.Errorf("%d. Parse token\nhave %v %v\nwant kid %v", i, token.Header, token.Claims, tt.kid)
+		}
+	}
+	tokens, err := SignMulti(claims, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwt, ok := SelectToken(tokens, "HS256")
+	if !ok || jwt != tokens[0] {
+		t.Errorf("SelectToken\nhave %v %v\nwant %v", jwt, ok, tokens[0])
+	}
+	_, err = SignMulti(claims)
+	if err != ErrSigner {
+		t.Errorf("SignMulti err\nhave %v\nwant %v", err, ErrSigner)
+	}
+}
@@ -0,0 +1,35 @@
+package jwt
+
+// Registered claim names.
+//
+// See RFC 7519 Section 4.1.
+const (
+	ClaimIssuer     = "iss"
+	ClaimSubject    = "sub"
+	ClaimAudience   = "aud"
+	ClaimExpiration = "exp"
+	ClaimNotBefore  = "nbf"
+	ClaimIssuedAt   = "iat"
+	ClaimJWTID      = "jti"
+)
+
+// Other claim names registered with IANA.
+const (
+	ClaimSessionID    = "sid"
+	ClaimConfirmation = "cnf"
+	ClaimScope        = "scope"
+	ClaimClientID     = "client_id"
+	ClaimActor        = "act"
+	ClaimNonce        = "nonce"
+)
+
+// Header parameter names.
+//
+// See RFC 7515 Section 4.1.
+const (
+	HeaderAlgorithm   = "alg"
+	HeaderType        = "typ"
+	HeaderContentType = "cty"
+	HeaderKeyID       = "kid"
+	HeaderCritical    = "crit"
+)
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+)
+
+// KeySigner is implemented by signers that accept native key types,
+// such as *rsa.PrivateKey and *ecdsa.PublicKey, in addition to the
+// encoded keys accepted by Sign and Verify. Native keys avoid decoding
+// PEM on every call.
+type KeySigner interface {
+	Signer
+
+	// SignKey returns the signature of the data.
+	SignKey(b []byte, key interface{}) ([]byte, error)
+
+	// VerifyKey returns an error if the signature is invalid.
+	VerifyKey(b, sig []byte, key interface{}) error
+}
+
+// NativeKeyProvider is implemented by key providers that return native
+// key types. The Verifier prefers NativeKey over Key when available.
+type NativeKeyProvider interface {
+	KeyProvider
+
+	// NativeKey returns the key used to verify the signature of the
+	// token as a native key type or []byte.
+	NativeKey(ctx context.Context, t *Token) (interface{}, error)
+}
+
+// nativeKey is a NativeKeyProvider that always returns the same key.
+type nativeKey struct {
+	key interface{}
+}
+
+// StaticNativeKey returns a NativeKeyProvider that always returns key,
+// such as an *rsa.PublicKey, ed25519.PublicKey or []byte HMAC secret.
+func StaticNativeKey(key interface{}) NativeKeyProvider {
+	return nativeKey{key: key}
+}
+
+// Key implements the KeyProvider interface. Native public keys and the
+// public part of native private keys are returned PEM-encoded.
+func (k nativeKey) Key(ctx context.Context, t *Token) ([]byte, error) {
+	switch key := k.key.(type) {
+	case []byte:
+		return key, nil
+	case crypto.Signer:
+		return encodePublicKey(key.Public())
+	}
+	return encodePublicKey(k.key)
+}
+
+// NativeKey implements the NativeKeyProvider interface.
+func (k nativeKey) NativeKey(ctx context.Context, t *Token) (interface{}, error) {
+	return k.key, nil
+}
+
+// SignKey returns the signed token like Sign, accepting a native key
+// type if the signer implements KeySigner.
+func (t *Token) SignKey(key interface{}) (string, error) {
+	jwt, err := t.SigningInput()
+	if err != nil {
+		return "", err
+	}
+	sig, err := signKey(t.signer, []byte(jwt), key)
+	if err != nil {
+		return "", err
+	}
+	return jwt + sep + encode(sig), nil
+}
+
+// ParseWithKey validates jwt with key like Parse, accepting a native key
+// type if the signer implements KeySigner.
+func ParseWithKey(s Signer, jwt string, key interface{}, opts ...Option) (*Token, error) {
+	v := NewVerifier([]Signer{s}, StaticNativeKey(key), opts...)
+	return v.Verify(context.Background(), jwt)
+}
+
+// signKey signs b with s using key.
+func signKey(s Signer, b []byte, key interface{}) ([]byte, error) {
+	if k, ok := key.([]byte); ok {
+		return s.Sign(b, k)
+	}
+	ks, ok := s.(KeySigner)
+	if !ok {
+		return nil, keyTypeError(key)
+	}
+	return ks.SignKey(b, key)
+}
+
+// verifyKey verifies sig over b with s using key.
+func verifyKey(s Signer, b, sig []byte, key interface{}) error {
+	if k, ok := key.([]byte); ok {
+		return s.Verify(b, sig, k)
+	}
+	ks, ok := s.(KeySigner)
+	if !ok {
+		return keyTypeError(key)
+	}
+	return ks.VerifyKey(b, sig, key)
+}
+
+// keyTypeError returns an ErrKeyType error describing key.
+func keyTypeError(key interface{}) error {
+	return fmt.Errorf("%w: %T", ErrKeyType, key)
+}
@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNativeKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("secret")
+	var tests = []struct {
+		signer Signer
+		priv   interface{}
+		pub    interface{}
+		err    error
+	}{
+		{HS256, secret, secret, nil},
+		{RS256, rsaKey, &rsaKey.PublicKey, nil},
+		{PS256, rsaKey, &rsaKey.PublicKey, nil},
+		{ES256, ecKey, &ecKey.PublicKey, nil},
+		{EdDSA, edKey, edPub, nil},
+		{NewPepperedSigner(HS256, "label", []byte("pepper")), secret, secret, nil},
+		{RS256, ecKey, &ecKey.PublicKey, ErrKeyType},
+		{HS256, rsaKey, &rsaKey.PublicKey, ErrKeyType},
+		{UnsecuredSigner{}, rsaKey, &rsaKey.PublicKey, ErrKeyType},
+	}
+	for i, tt := range tests {
+		token := New(tt.signer)
+		token.Claims["sub"] = "user"
+		jwt, err := token.SignKey(tt.priv)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. SignKey err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		parsed, err := ParseWithKey(tt.signer, jwt, tt.pub)
+		if err != nil {
+			t.Errorf("%d. ParseWithKey err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if !reflect.DeepEqual(parsed.Claims, token.Claims) {
+			t.Errorf("%d. ParseWithKey claims\nhave %v\nwant %v", i, parsed.Claims, token.Claims)
+		}
+	}
+}
+
+func TestNativeKeyEncoded(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := New(ES256)
+	jwt, err := token.SignKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := StaticNativeKey(priv).Key(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(ES256, jwt, key)
+	if err != nil {
+		t.Errorf("Parse err\nhave %v\nwant %v", err, nil)
+	}
+	s := NewPepperedSigner(HS256, "label", []byte("pepper"))
+	sig, err := s.SignKey([]byte("foo"), []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HS256.VerifyKey([]byte("foo"), sig, []byte("secret")) != ErrInvalidSignature {
+		t.Errorf("should not bypass pepper with native keys")
+	}
+}
+
+// opaqueSigner hides the concrete private key type, as an HSM or KMS
+// backed crypto.Signer would.
+type opaqueSigner struct {
+	crypto.Signer
+}
+
+func TestCryptoSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		signer Signer
+		priv   crypto.Signer
+		pub    crypto.PublicKey
+		err    error
+	}{
+		{RS256, opaqueSigner{rsaKey}, &rsaKey.PublicKey, nil},
+		{PS512, opaqueSigner{rsaKey}, &rsaKey.PublicKey, nil},
+		{ES384, opaqueSigner{ecKey}, &ecKey.PublicKey, nil},
+		{NewECDSASignerDER("ES384", crypto.SHA384), opaqueSigner{ecKey}, &ecKey.PublicKey, nil},
+		{EdDSA, opaqueSigner{edKey}, edKey.Public(), nil},
+		{ES256, opaqueSigner{rsaKey}, nil, ErrKeyType},
+		{HS256, opaqueSigner{rsaKey}, nil, ErrKeyType},
+	}
+	for i, tt := range tests {
+		jwt, err := New(tt.signer).SignKey(tt.priv)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. SignKey err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		_, err = ParseWithKey(tt.signer, jwt, tt.pub)
+		if err != nil {
+			t.Errorf("%d. ParseWithKey err\nhave %v\nwant %v", i, err, nil)
+		}
+		_, err = ParseWithKey(tt.signer, jwt, tt.priv)
+		if err != nil {
+			t.Errorf("%d. ParseWithKey signer err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+)
+
+// ErrClaimNonce is returned when the nonce claim of a token is missing
+// or does not match the expected nonce.
+var ErrClaimNonce = errors.New("jwt: nonce does not match the expected nonce")
+
+// nonceSize is the number of random bytes in a nonce.
+const nonceSize = 32
+
+// NewNonce returns a new random URL-safe nonce, such as for the nonce
+// parameter of an OpenID Connect authentication request.
+func NewNonce() (string, error) {
+	b := make([]byte, nonceSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+// WithNonce returns an option that requires the nonce claim to equal
+// expected, such as the nonce stored in the session of an OpenID
+// Connect login. The values are compared in constant time.
+//
+// See OpenID Connect Core 1.0 Section 3.1.3.7.
+func WithNonce(expected string) Option {
+	return func(v *Verifier) {
+		v.nonce = &expected
+	}
+}
+
+func (v *Verifier) checkNonce(ctx context.Context, s *verification) error {
+	if v.nonce == nil {
+		return skipped("no nonce is expected")
+	}
+	c, ok := s.token.Claims[ClaimNonce]
+	if !ok {
+		return ErrClaimNonce
+	}
+	nonce, ok := c.(string)
+	if !ok {
+		return ErrClaimType
+	}
+	if *v.nonce == "" || !compare([]byte(nonce), []byte(*v.nonce)) {
+		return ErrClaimNonce
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package jwt
+
+import (
+	"testing"
+)
+
+func TestNewNonce(t *testing.T) {
+	a, err := NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 43 || a == b {
+		t.Fatalf("nonces must be distinct 32 byte values\nhave %q and %q", a, b)
+	}
+}
+
+func TestWithNonce(t *testing.T) {
+	key := []byte("secret")
+	var tests = []struct {
+		expected string
+		claims   map[string]interface{}
+		err      error
+	}{
+		{"n-0S6_WzA2Mj", map[string]interface{}{"nonce": "n-0S6_WzA2Mj"}, nil},
+		{"n-0S6_WzA2Mj", map[string]interface{}{"nonce": "other"}, ErrClaimNonce},
+		{"n-0S6_WzA2Mj", map[string]interface{}{}, ErrClaimNonce},
+		{"n-0S6_WzA2Mj", map[string]interface{}{"nonce": 1}, ErrClaimType},
+		{"", map[string]interface{}{"nonce": ""}, ErrClaimNonce},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims = tt.claims
+		jwt, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Parse(HS256, jwt, key, WithNonce(tt.expected))
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
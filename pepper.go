@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"crypto/hkdf"
+	"errors"
+	stdhash "hash"
+)
+
+// ErrPepperRequired is returned when a PepperedSigner has no peppers.
+var ErrPepperRequired = errors.New("jwt: pepper required")
+
+// PepperedSigner is an HMAC signer that mixes a server-side secret,
+// the pepper, into the signing key. The key passed to Sign and Verify
+// is combined with the pepper and a context label using HKDF, so a
+// leak of the stored key alone is not enough to forge tokens, such as
+// long-lived remember-me tokens. Peppered tokens are not interoperable
+// with other implementations.
+//
+// See RFC 5869.
+type PepperedSigner struct {
+	HMACSigner
+	label   string
+	peppers [][]byte
+}
+
+// NewPepperedSigner returns a new PepperedSigner using the label as
+// the HKDF context info. Tokens are signed with the first pepper and
+// verified against each pepper in turn, allowing peppers to be rotated
+// by prepending the new pepper and retiring old peppers once tokens
+// signed with them have expired.
+func NewPepperedSigner(s HMACSigner, label string, peppers ...[]byte) PepperedSigner {
+	return PepperedSigner{HMACSigner: s, label: label, peppers: peppers}
+}
+
+// Sign returns the signature of the data using the current pepper.
+func (s PepperedSigner) Sign(b, key []byte) ([]byte, error) {
+	if len(s.peppers) == 0 {
+		return nil, ErrPepperRequired
+	}
+	k, err := s.derive(key, s.peppers[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.HMACSigner.Sign(b, k)
+}
+
+// Verify returns an error if the signature is invalid for every pepper.
+func (s PepperedSigner) Verify(b, sig, key []byte) error {
+	if len(s.peppers) == 0 {
+		return ErrPepperRequired
+	}
+	for _, pepper := range s.peppers {
+		k, err := s.derive(key, pepper)
+		if err != nil {
+			return err
+		}
+		err = s.HMACSigner.Verify(b, sig, k)
+		if err != ErrInvalidSignature {
+			return err
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// String implements the fmt.Stringer interface. The name is that of
+// the HMAC signer with a "+pepper" suffix, such as "HS256+pepper", so
+// peppered tokens are told apart from plain HMAC tokens by their alg
+// header, registries, policies and deny lists.
+func (s PepperedSigner) String() string {
+	return s.HMACSigner.String() + "+pepper"
+}
+
+// SignKey returns the signature of the data using the current pepper.
+// The key must be a []byte secret.
+func (s PepperedSigner) SignKey(b []byte, key interface{}) ([]byte, error) {
+	k, ok := key.([]byte)
+	if !ok {
+		return nil, keyTypeError(key)
+	}
+	return s.Sign(b, k)
+}
+
+// VerifyKey returns an error if the signature is invalid for every
+// pepper. The key must be a []byte secret.
+func (s PepperedSigner) VerifyKey(b, sig []byte, key interface{}) error {
+	k, ok := key.([]byte)
+	if !ok {
+		return keyTypeError(key)
+	}
+	return s.Verify(b, sig, k)
+}
+
+// derive returns the HMAC key derived from key and pepper.
+func (s PepperedSigner) derive(key, pepper []byte) ([]byte, error) {
+	if !s.hash.Available() {
+		return nil, ErrHashUnavailable
+	}
+	return hkdf.Key(func() stdhash.Hash { return s.hash.New() }, key, pepper, s.label, s.hash.Size())
+}
@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPepperedSigner(t *testing.T) {
+	b := []byte("foo")
+	key := []byte("secret")
+	old := []byte("old pepper")
+	cur := []byte("new pepper")
+	s := NewPepperedSigner(HS256, "remember-me", old)
+	sig, err := s.Sign(b, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HS256.Verify(b, sig, key) != ErrInvalidSignature {
+		t.Fatal("should not verify without pepper")
+	}
+	var tests = []struct {
+		s   Signer
+		err error
+	}{
+		{s, nil},
+		{NewPepperedSigner(HS256, "remember-me", cur, old), nil},
+		{NewPepperedSigner(HS256, "remember-me", cur), ErrInvalidSignature},
+		{NewPepperedSigner(HS256, "session", old), ErrInvalidSignature},
+		{NewPepperedSigner(HS256, "remember-me"), ErrPepperRequired},
+	}
+	for i, tt := range tests {
+		err := tt.s.Verify(b, sig, key)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+	rotated := NewPepperedSigner(HS256, "remember-me", cur, old)
+	sig, err = rotated.Sign(b, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Verify(b, sig, key) != ErrInvalidSignature {
+		t.Fatal("should sign with the current pepper")
+	}
+}
+
+func TestPepperedSignerName(t *testing.T) {
+	key := []byte("secret")
+	s := NewPepperedSigner(HS256, "remember-me", []byte("pepper"))
+	if s.String() != "HS256+pepper" {
+		t.Fatalf("String\nhave %q\nwant %q", s.String(), "HS256+pepper")
+	}
+	jwt, err := New(s).Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewVerifier([]Signer{s, HS256}, StaticKey(key)).Verify(context.Background(), jwt)
+	if err != nil {
+		t.Errorf("Verify err\nhave %v\nwant %v", err, nil)
+	}
+	_, err = Parse(HS256, jwt, key)
+	if err != ErrHeaderAlg {
+		t.Errorf("Parse err\nhave %v\nwant %v", err, ErrHeaderAlg)
+	}
+	var k KillSwitch
+	k.Set(DenyList{Algorithms: []string{"HS256"}})
+	_, err = NewVerifier([]Signer{s}, StaticKey(key), WithKillSwitch(&k)).Verify(context.Background(), jwt)
+	if err != nil {
+		t.Errorf("Verify with HS256 denied err\nhave %v\nwant %v", err, nil)
+	}
+}
@@ -0,0 +1,137 @@
+// Package pkcs11 implements token signing with private keys held on
+// smartcards and HSMs through a PKCS#11 module, so signing keys never
+// leave the hardware.
+//
+// A Key is a crypto.Signer and is used with the native key support of
+// the jwt package:
+//
+//	key, err := pkcs11.New(session, pub)
+//	token, err := jwt.New(jwt.ES256).SignKey(key)
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/pnelson/jwt"
+)
+
+// Key errors.
+var (
+	ErrKeyType   = errors.New("pkcs11: unsupported public key type")
+	ErrMechanism = errors.New("pkcs11: unsupported signing options")
+)
+
+// PKCS#11 signing mechanisms.
+const (
+	CKM_RSA_PKCS     = 0x00000001
+	CKM_RSA_PKCS_PSS = 0x0000000d
+	CKM_ECDSA        = 0x00001041
+	CKM_EDDSA        = 0x00001057
+)
+
+// Mechanism is a PKCS#11 signing mechanism. Hash and SaltLength are
+// the CK_RSA_PKCS_PSS_PARAMS of CKM_RSA_PKCS_PSS and are otherwise zero.
+type Mechanism struct {
+	Type       uint
+	Hash       crypto.Hash
+	SaltLength int
+}
+
+// Session is the subset of a PKCS#11 session used by Key. It is
+// satisfied by a small wrapper around C_SignInit and C_Sign of a
+// PKCS#11 binding, bound to a logged-in session and a private key
+// object.
+type Session interface {
+	// Sign returns the signature of data made with the mechanism.
+	// CKM_ECDSA signatures are the concatenated r and s values.
+	Sign(m Mechanism, data []byte) ([]byte, error)
+}
+
+// digestInfo is the DER-encoded DigestInfo prefix of each hash, which
+// CKM_RSA_PKCS expects the caller to prepend to the digest.
+var digestInfo = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// Key is a crypto.Signer backed by a private key object on a PKCS#11
+// token. PKCS#11 sessions are not safe for concurrent use, so calls to
+// Sign are serialized.
+type Key struct {
+	mu      sync.Mutex
+	session Session
+	pub     crypto.PublicKey
+}
+
+// New returns a new Key signing with the session. The pub argument is
+// the public key of the private key object, an *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey.
+func New(session Session, pub crypto.PublicKey) (*Key, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrKeyType, pub)
+	}
+	return &Key{session: session, pub: pub}, nil
+}
+
+// Public implements the crypto.Signer interface.
+func (k *Key) Public() crypto.PublicKey {
+	return k.pub
+}
+
+// Sign implements the crypto.Signer interface. The rand argument is
+// ignored as the module provides its own randomness. ECDSA signatures
+// are returned ASN.1 DER-encoded as crypto.Signer requires.
+func (k *Key) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	m, data, err := k.mechanism(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	sig, err := k.session.Sign(m, data)
+	k.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if m.Type == CKM_ECDSA {
+		return jwt.ECDSASignatureToDER(sig)
+	}
+	return sig, nil
+}
+
+// mechanism returns the mechanism and data to sign for the options.
+func (k *Key) mechanism(digest []byte, opts crypto.SignerOpts) (Mechanism, []byte, error) {
+	hash := opts.HashFunc()
+	switch k.pub.(type) {
+	case *rsa.PublicKey:
+		if pss, ok := opts.(*rsa.PSSOptions); ok {
+			salt := pss.SaltLength
+			if salt == rsa.PSSSaltLengthEqualsHash || salt == rsa.PSSSaltLengthAuto {
+				salt = hash.Size()
+			}
+			return Mechanism{Type: CKM_RSA_PKCS_PSS, Hash: hash, SaltLength: salt}, digest, nil
+		}
+		prefix, ok := digestInfo[hash]
+		if !ok || len(digest) != hash.Size() {
+			return Mechanism{}, nil, ErrMechanism
+		}
+		return Mechanism{Type: CKM_RSA_PKCS}, append(append([]byte{}, prefix...), digest...), nil
+	case *ecdsa.PublicKey:
+		return Mechanism{Type: CKM_ECDSA}, digest, nil
+	case ed25519.PublicKey:
+		if hash != crypto.Hash(0) {
+			return Mechanism{}, nil, ErrMechanism
+		}
+		return Mechanism{Type: CKM_EDDSA}, digest, nil
+	}
+	return Mechanism{}, nil, ErrKeyType
+}
@@ -0,0 +1,87 @@
+package pkcs11
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+// fakeSession is a Session backed by a local private key, implementing
+// the raw PKCS#11 mechanisms.
+type fakeSession struct {
+	key crypto.Signer
+}
+
+func (s fakeSession) Sign(m Mechanism, data []byte) ([]byte, error) {
+	switch m.Type {
+	case CKM_RSA_PKCS:
+		for hash, prefix := range digestInfo {
+			if bytes.HasPrefix(data, prefix) {
+				return rsa.SignPKCS1v15(rand.Reader, s.key.(*rsa.PrivateKey), hash, data[len(prefix):])
+			}
+		}
+	case CKM_RSA_PKCS_PSS:
+		return rsa.SignPSS(rand.Reader, s.key.(*rsa.PrivateKey), m.Hash, data, &rsa.PSSOptions{SaltLength: m.SaltLength})
+	case CKM_ECDSA:
+		der, err := s.key.Sign(rand.Reader, data, nil)
+		if err != nil {
+			return nil, err
+		}
+		return jwt.ECDSASignatureToRaw(der, s.key.Public().(*ecdsa.PublicKey).Curve)
+	case CKM_EDDSA:
+		return s.key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	return nil, errors.New("CKR_MECHANISM_INVALID")
+}
+
+func TestKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		signer jwt.Signer
+		key    crypto.Signer
+	}{
+		{jwt.RS256, rsaKey},
+		{jwt.RS512, rsaKey},
+		{jwt.PS256, rsaKey},
+		{jwt.ES384, ecKey},
+		{jwt.EdDSA, edKey},
+	}
+	for i, tt := range tests {
+		key, err := New(fakeSession{tt.key}, tt.key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.New(tt.signer).SignKey(key)
+		if err != nil {
+			t.Errorf("%d. SignKey err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		_, err = jwt.ParseWithKey(tt.signer, token, tt.key.Public())
+		if err != nil {
+			t.Errorf("%d. ParseWithKey err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+	_, err = New(fakeSession{rsaKey}, []byte("secret"))
+	if !errors.Is(err, ErrKeyType) {
+		t.Errorf("New err\nhave %v\nwant %v", err, ErrKeyType)
+	}
+}
@@ -0,0 +1,183 @@
+package jwt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Policy is a serializable description of the checks enforced by a
+// Verifier, for security reviews to compare the policy of deployments.
+// Lists are sorted so that equal policies encode identically.
+type Policy struct {
+	// Algorithms is the list of accepted alg header values.
+	Algorithms []string `json:"algorithms"`
+
+	// Types is the list of accepted typ header values.
+	Types []string `json:"types"`
+
+	// StrictType is true if typ values must match exactly.
+	StrictType bool `json:"strict_type"`
+
+	// Keys describes the key provider.
+	Keys string `json:"keys"`
+
+	// LegacyBase64 is true if padded standard base64 is accepted.
+	LegacyBase64 bool `json:"legacy_base64"`
+
+	// KillSwitch is the current deny list of the kill switch, if any.
+	KillSwitch *DenyList `json:"kill_switch,omitempty"`
+
+	// Issuers is the list of accepted iss claim values.
+	Issuers []string `json:"issuers,omitempty"`
+
+	// Audiences is the list of accepted aud claim values.
+	Audiences []string `json:"audiences,omitempty"`
+
+	// AudienceMatch is the comparison of aud claim values, "exact",
+	// "fold", "url" or "custom".
+	AudienceMatch string `json:"audience_match,omitempty"`
+
+	// Leeway is the allowed clock skew.
+	Leeway Duration `json:"leeway"`
+
+	// MaxAge is the maximum age of tokens by their iat claim, or zero.
+	MaxAge Duration `json:"max_age"`
+
+	// RequiredClaims is the list of claims that must be present.
+	RequiredClaims []string `json:"required_claims,omitempty"`
+
+	// ExpirationRequired is true if tokens must have an exp claim.
+	ExpirationRequired bool `json:"expiration_required"`
+
+	// Subsecond is true if dates are compared with subsecond precision.
+	Subsecond bool `json:"subsecond"`
+
+	// FixedClock is true if tokens are evaluated at a time other than
+	// the current system time.
+	FixedClock bool `json:"fixed_clock"`
+
+	// Milliseconds is the list of issuers whose dates may be in
+	// milliseconds, or "*" for all issuers.
+	Milliseconds []string `json:"milliseconds,omitempty"`
+
+	// Replicated is the policy for claims replicated in the header,
+	// "ignore", "require_match" or "prefer_payload".
+	Replicated string `json:"replicated"`
+
+	// TenantClaim is the claim that must equal the expected tenant.
+	TenantClaim string `json:"tenant_claim,omitempty"`
+
+	// JTI is true if tokens must have a jti claim.
+	JTI bool `json:"jti"`
+
+	// Nonce is true if the nonce claim must equal an expected nonce.
+	Nonce bool `json:"nonce"`
+
+	// StringOrURI is true if iss, sub and aud must be StringOrURI values.
+	StringOrURI bool `json:"string_or_uri"`
+
+	// Rules is the number of rules the claims must pass. See WithRules.
+	Rules int `json:"rules,omitempty"`
+
+	// Revocation is true if tokens are checked for revocation.
+	Revocation bool `json:"revocation"`
+
+	// MaxHeaderParams is the maximum number of header parameters.
+	MaxHeaderParams int `json:"max_header_params"`
+
+	// MaxClaims is the maximum number of top-level claims.
+	MaxClaims int `json:"max_claims"`
+
+	// MaxDepth is the maximum nesting depth of the header and claims.
+	MaxDepth int `json:"max_depth"`
+}
+
+// replicatedNames are the names of the replicated claims policies.
+var replicatedNames = map[ReplicatedClaims]string{
+	ReplicatedIgnore:        "ignore",
+	ReplicatedRequireMatch:  "require_match",
+	ReplicatedPreferPayload: "prefer_payload",
+}
+
+// Policy returns a description of the checks enforced by the verifier.
+func (v *Verifier) Policy() Policy {
+	p := Policy{
+		Algorithms:         make([]string, 0, len(v.signers)),
+		Types:              sorted(v.types),
+		StrictType:         v.strictTyp,
+		Keys:               describe(v.keys),
+		LegacyBase64:       v.legacyBase64,
+		Issuers:            sorted(v.issuers),
+		Audiences:          sorted(v.audiences),
+		Leeway:             Duration(v.leeway),
+		MaxAge:             Duration(v.maxAge),
+		RequiredClaims:     sorted(v.required),
+		ExpirationRequired: v.expRequired,
+		Subsecond:          v.subsecond,
+		FixedClock:         v.now != nil,
+		Milliseconds:       sorted(v.millis),
+		Replicated:         replicatedNames[v.replicated],
+		TenantClaim:        v.tenantClaim,
+		JTI:                v.jtiRequired,
+		Nonce:              v.nonce != nil,
+		StringOrURI:        v.stringOrURI,
+		Rules:              len(v.rules),
+		Revocation:         v.revocations != nil,
+		MaxHeaderParams:    v.limits.members("header"),
+		MaxClaims:          v.limits.members("claims"),
+		MaxDepth:           orDefault(v.limits.Depth, DefaultMaxDepth),
+	}
+	for name := range v.signers {
+		p.Algorithms = append(p.Algorithms, name)
+	}
+	sort.Strings(p.Algorithms)
+	if len(p.Types) == 0 {
+		p.Types = []string{"JWT"}
+	}
+	if len(p.Audiences) > 0 {
+		p.AudienceMatch = comparatorName(v.audienceEqual)
+	}
+	if v.killSwitch != nil {
+		deny := v.killSwitch.DenyList()
+		p.KillSwitch = &deny
+	}
+	if v.millisAll {
+		p.Milliseconds = []string{"*"}
+	}
+	return p
+}
+
+// sorted returns a sorted copy of s, or nil if s is empty.
+func sorted(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	rv := append([]string(nil), s...)
+	sort.Strings(rv)
+	return rv
+}
+
+// describe returns the key provider as a string, using its String
+// method if it has one.
+func describe(keys KeyProvider) string {
+	if s, ok := keys.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", keys)
+}
+
+// comparatorName returns the Config name of the audience comparator,
+// or "custom" if it is not one of the named comparators.
+func comparatorName(fn AudienceComparator) string {
+	if fn == nil {
+		return "exact"
+	}
+	p := reflect.ValueOf(fn).Pointer()
+	for _, name := range []string{"exact", "fold", "url"} {
+		if reflect.ValueOf(audienceComparators[name]).Pointer() == p {
+			return name
+		}
+	}
+	return "custom"
+}
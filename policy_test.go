@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVerifierPolicy(t *testing.T) {
+	v := NewVerifier([]Signer{RS256, ES256}, StaticKey([]byte("secret")),
+		WithIssuer("https://b.example", "https://a.example"),
+		WithAudience("api"),
+		WithAudienceComparator(AudienceFold),
+		WithLeeway(30*time.Second),
+		WithMaxAge(24*time.Hour),
+		WithRequired("sub"),
+		WithExpirationRequired(),
+		WithNonce("n"),
+		WithLimits(Limits{Claims: 64}),
+	)
+	want := Policy{
+		Algorithms:         []string{"ES256", "RS256"},
+		Types:              []string{"JWT"},
+		Keys:               "jwt.KeyFunc",
+		Issuers:            []string{"https://a.example", "https://b.example"},
+		Audiences:          []string{"api"},
+		AudienceMatch:      "fold",
+		Leeway:             Duration(30 * time.Second),
+		MaxAge:             Duration(24 * time.Hour),
+		RequiredClaims:     []string{"sub"},
+		ExpirationRequired: true,
+		Replicated:         "ignore",
+		Nonce:              true,
+		MaxHeaderParams:    DefaultMaxHeaderParams,
+		MaxClaims:          64,
+		MaxDepth:           DefaultMaxDepth,
+	}
+	have := v.Policy()
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %+v\nwant %+v", have, want)
+	}
+	b, err := json.Marshal(have)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Policy
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatalf("roundtrip\nhave %+v\nwant %+v", decoded, want)
+	}
+}
@@ -0,0 +1,124 @@
+// Package privacy supports data minimization of tokens carrying
+// personal data. Claims are declared as personal data when a token is
+// issued, and a Profile mints a minimized variant of the token for a
+// third-party processor with those claims pseudonymized or removed.
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pnelson/jwt"
+)
+
+// Header is the private header parameter listing the claims of a token
+// that hold personal data.
+const Header = "pii"
+
+// Declare declares the claims of t as personal data.
+func Declare(t *jwt.Token, claims ...string) {
+	declared := Declared(t)
+	for _, name := range claims {
+		if !contains(declared, name) {
+			declared = append(declared, name)
+		}
+	}
+	t.Header[Header] = declared
+}
+
+// Declared returns the claims of t declared as personal data.
+func Declared(t *jwt.Token) []string {
+	var declared []string
+	switch v := t.Header[Header].(type) {
+	case []string:
+		declared = append(declared, v...)
+	case []interface{}:
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				declared = append(declared, s)
+			}
+		}
+	}
+	return declared
+}
+
+// Profile is a data minimization policy for a processor.
+type Profile struct {
+	// Processor identifies the recipient of minimized tokens. Pseudonyms
+	// differ per processor so recipients can not correlate subjects.
+	Processor string
+
+	// Secret keys the pseudonyms. Pseudonyms are stable for a processor
+	// while the secret is unchanged and can not be reversed without it.
+	Secret []byte
+
+	// Keep lists personal claims the processor may receive unchanged.
+	Keep []string
+
+	// Remove lists personal claims removed rather than pseudonymized.
+	Remove []string
+}
+
+// Minimize returns a new token for s with the claims of t, replacing
+// each claim declared as personal data with a pseudonymous identifier
+// unless the profile keeps or removes it. Only the typ and cty header
+// parameters are copied, as a kid would name a key that does not sign
+// the new token and other parameters may carry personal data. Kept
+// claims are declared as personal data again. The returned token must
+// be signed again before it is forwarded.
+func (p Profile) Minimize(t *jwt.Token, s jwt.Signer) (*jwt.Token, error) {
+	declared := Declared(t)
+	m := jwt.New(s)
+	for _, k := range []string{"typ", "cty"} {
+		if v, ok := t.Header[k]; ok {
+			m.Header[k] = v
+		}
+	}
+	for k, v := range t.Claims {
+		if !contains(declared, k) {
+			m.Claims[k] = v
+			continue
+		}
+		if contains(p.Keep, k) {
+			m.Claims[k] = v
+			Declare(m, k)
+			continue
+		}
+		if contains(p.Remove, k) {
+			continue
+		}
+		pseudonym, err := p.Pseudonym(k, v)
+		if err != nil {
+			return nil, err
+		}
+		m.Claims[k] = pseudonym
+	}
+	return m, nil
+}
+
+// Pseudonym returns the pseudonymous identifier of the claim value.
+func (p Profile) Pseudonym(claim string, v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	h := hmac.New(sha256.New, p.Secret)
+	h.Write([]byte(p.Processor))
+	h.Write([]byte{0})
+	h.Write([]byte(claim))
+	h.Write([]byte{0})
+	h.Write(b)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// contains returns true if s is an element of list.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package privacy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pnelson/jwt"
+)
+
+func TestMinimize(t *testing.T) {
+	issuerKey := []byte("issuer")
+	token := jwt.New(jwt.HS256)
+	token.Claims["sub"] = "user"
+	token.Claims["email"] = "user@example.com"
+	token.Claims["name"] = "User"
+	token.Claims["country"] = "NZ"
+	Declare(token, "email", "name", "country")
+	Declare(token, "email")
+	token.Header["kid"] = "issuer-2024"
+	token.Header["x-user"] = "user@example.com"
+	raw, err := token.Sign(issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := jwt.Parse(jwt.HS256, raw, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := Declared(parsed)
+	if !reflect.DeepEqual(declared, []string{"email", "name", "country"}) {
+		t.Fatalf("Declared\nhave %v\nwant %v", declared, []string{"email", "name", "country"})
+	}
+	p := Profile{Processor: "analytics", Secret: []byte("pseudonyms"), Keep: []string{"country"}, Remove: []string{"name"}}
+	m, err := p.Minimize(parsed, jwt.HS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processorKey := []byte("processor")
+	raw, err = m.Sign(processorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minimized, err := jwt.Parse(jwt.HS256, raw, processorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email, _ := p.Pseudonym("email", "user@example.com")
+	want := map[string]interface{}{"sub": "user", "email": email, "country": "NZ"}
+	if !reflect.DeepEqual(minimized.Claims, want) {
+		t.Errorf("Minimize claims\nhave %v\nwant %v", minimized.Claims, want)
+	}
+	header := map[string]interface{}{"alg": "HS256", "typ": "JWT", Header: []interface{}{"country"}}
+	if !reflect.DeepEqual(minimized.Header, header) {
+		t.Errorf("Minimize header\nhave %v\nwant %v", minimized.Header, header)
+	}
+	other := Profile{Processor: "billing", Secret: []byte("pseudonyms")}
+	pseudonym, _ := other.Pseudonym("email", "user@example.com")
+	if pseudonym == email {
+		t.Errorf("pseudonyms should differ per processor")
+	}
+}
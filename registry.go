@@ -0,0 +1,72 @@
+package jwt
+
+import "sync"
+
+// DefaultRegistry is the registry of built-in signers.
+var DefaultRegistry = NewRegistry(
+	HS256, HS384, HS512,
+	RS256, RS384, RS512,
+	PS256, PS384, PS512,
+	ES256, ES384, ES512,
+	EdDSA,
+)
+
+// RegisterSigner makes the signer available by its algorithm name in
+// DefaultRegistry, allowing third-party algorithms to be verified from
+// configuration. It replaces any signer with the same name and panics
+// if s is nil.
+func RegisterSigner(s Signer) {
+	DefaultRegistry.Register(s)
+}
+
+// LookupSigner returns the signer for the algorithm name in DefaultRegistry.
+func LookupSigner(name string) (Signer, bool) {
+	return DefaultRegistry.Lookup(name)
+}
+
+// Registry is a set of signers by algorithm name. Registries are safe
+// for concurrent use, allowing subsystems of one binary to maintain
+// isolated algorithm policies.
+type Registry struct {
+	mu      sync.RWMutex
+	signers map[string]Signer
+}
+
+// NewRegistry returns a new Registry containing the signers s.
+func NewRegistry(s ...Signer) *Registry {
+	r := &Registry{signers: make(map[string]Signer)}
+	for _, signer := range s {
+		r.Register(signer)
+	}
+	return r
+}
+
+// Register adds the signer s, replacing any signer with the same name.
+// It panics if s is nil.
+func (r *Registry) Register(s Signer) {
+	if s == nil {
+		panic("jwt: Register signer is nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signers[s.String()] = s
+}
+
+// Lookup returns the signer for the algorithm name.
+func (r *Registry) Lookup(name string) (Signer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.signers[name]
+	return s, ok
+}
+
+// Signers returns the registered signers.
+func (r *Registry) Signers() []Signer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rv := make([]Signer, 0, len(r.signers))
+	for _, s := range r.signers {
+		rv = append(rv, s)
+	}
+	return rv
+}
@@ -0,0 +1,67 @@
+package jwt
+
+import (
+	"crypto"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	custom := NewHMACSigner("HS256-custom", crypto.SHA256)
+	r := NewRegistry(HS256)
+	r.Register(custom)
+	var tests = []struct {
+		r    *Registry
+		name string
+		ok   bool
+	}{
+		{r, "HS256", true},
+		{r, "HS256-custom", true},
+		{r, "RS256", false},
+		{DefaultRegistry, "RS256", true},
+		{DefaultRegistry, "HS256-custom", false},
+	}
+	for i, tt := range tests {
+		_, ok := tt.r.Lookup(tt.name)
+		if ok != tt.ok {
+			t.Errorf("%d. Lookup(%q)\nhave %v\nwant %v", i, tt.name, ok, tt.ok)
+		}
+	}
+	_, err := r.NewVerifierFromConfig(Config{
+		Algorithms: []string{"HS256-custom"},
+		JWKSURLs:   []string{"https://issuer.example/jwks"},
+	})
+	if err != nil {
+		t.Errorf("should resolve algorithms with the registry: %v", err)
+	}
+	_, err = NewVerifierFromConfig(Config{
+		Algorithms: []string{"HS256-custom"},
+		JWKSURLs:   []string{"https://issuer.example/jwks"},
+	})
+	if err == nil {
+		t.Errorf("should not resolve algorithms from other registries")
+	}
+}
+
+func TestRegisterSigner(t *testing.T) {
+	registry := DefaultRegistry
+	DefaultRegistry = NewRegistry(registry.Signers()...)
+	t.Cleanup(func() {
+		DefaultRegistry = registry
+	})
+	custom := NewHMACSigner("HS256-registered", crypto.SHA256)
+	RegisterSigner(custom)
+	s, ok := LookupSigner("HS256-registered")
+	if !ok || s != custom {
+		t.Fatalf("LookupSigner\nhave %v %v\nwant %v %v", s, ok, custom, true)
+	}
+}
+
+func TestRegisterNil(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r != "jwt: Register signer is nil" {
+			t.Errorf("Register panic\nhave %v\nwant %v", r, "jwt: Register signer is nil")
+		}
+	}()
+	NewRegistry().Register(nil)
+}
@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// WithRejectedCache returns an option that remembers up to size tokens
+// that failed signature verification for ttl. A remembered token is
+// rejected with ErrInvalidSignature without verifying the signature
+// again, reducing the cost of the same forged token being replayed.
+//
+// Only signature failures are remembered. Tokens are identified by
+// their SHA-256 hash and are never stored.
+func WithRejectedCache(size int, ttl time.Duration) Option {
+	return func(v *Verifier) {
+		if size <= 0 || ttl <= 0 {
+			v.rejected = nil
+			return
+		}
+		v.rejected = newRejectedCache(size, ttl)
+	}
+}
+
+// rejectedCache is a bounded set of token hashes with expiry. When the
+// cache is full the oldest entry is evicted.
+type rejectedCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]time.Time
+	order   [][sha256.Size]byte
+	next    int
+}
+
+func newRejectedCache(size int, ttl time.Duration) *rejectedCache {
+	return &rejectedCache{
+		ttl:     ttl,
+		entries: make(map[[sha256.Size]byte]time.Time, size),
+		order:   make([][sha256.Size]byte, 0, size),
+	}
+}
+
+// contains reports whether jwt was rejected within the ttl. Expired
+// entries are left in place until evicted so that each entry keeps
+// exactly one slot in the eviction order.
+func (c *rejectedCache) contains(jwt string) bool {
+	sum := sha256.Sum256([]byte(jwt))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exp, ok := c.entries[sum]
+	return ok && !time.Now().After(exp)
+}
+
+// add remembers jwt as rejected.
+func (c *rejectedCache) add(jwt string) {
+	sum := sha256.Sum256([]byte(jwt))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exp := time.Now().Add(c.ttl)
+	if _, ok := c.entries[sum]; ok {
+		c.entries[sum] = exp
+		return
+	}
+	if len(c.order) < cap(c.order) {
+		c.order = append(c.order, sum)
+	} else {
+		delete(c.entries, c.order[c.next])
+		c.order[c.next] = sum
+		c.next = (c.next + 1) % len(c.order)
+	}
+	c.entries[sum] = exp
+}
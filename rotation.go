@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrKeyPublic is returned when a signing key has no private parameters.
+var ErrKeyPublic = errors.New("jwt: signing key must be a private or symmetric key")
+
+// Rotator manages signing key rotation. Tokens are signed with the
+// newest key, while previous keys continue to verify tokens for a
+// grace period after they are replaced. A Rotator is safe for
+// concurrent use.
+type Rotator struct {
+	retain int
+	grace  time.Duration
+	mu     sync.RWMutex
+	keys   []rotatedKey
+}
+
+// rotatedKey is a key managed by a Rotator.
+type rotatedKey struct {
+	jwk     JWK
+	sign    []byte
+	verify  []byte
+	retired time.Time
+}
+
+// NewRotator returns a new Rotator that verifies with at most retain
+// previous keys, each for the grace period after it was replaced.
+func NewRotator(retain int, grace time.Duration) *Rotator {
+	return &Rotator{retain: retain, grace: grace}
+}
+
+// Rotate makes the private or symmetric key the signing key. The kid
+// defaults to the thumbprint of the key.
+func (r *Rotator) Rotate(key JWK) error {
+	if !key.IsPrivate() && key.Kty != "oct" {
+		return ErrKeyPublic
+	}
+	sign, err := key.Key()
+	if err != nil {
+		return err
+	}
+	verify, err := key.Public().Key()
+	if err != nil {
+		return err
+	}
+	if key.Kid == "" {
+		key.Kid, err = key.Thumbprint()
+		if err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.keys) > 0 {
+		r.keys[0].retired = now
+	}
+	r.keys = append([]rotatedKey{{jwk: key, sign: sign, verify: verify}}, r.keys...)
+	r.prune(now)
+	return nil
+}
+
+// prune removes previous keys beyond retain or past the grace period.
+func (r *Rotator) prune(now time.Time) {
+	n := 1
+	for _, k := range r.keys[1:] {
+		if n > r.retain || now.Sub(k.retired) > r.grace {
+			break
+		}
+		n++
+	}
+	r.keys = r.keys[:n]
+}
+
+// active returns the keys that may verify tokens.
+func (r *Rotator) active() []rotatedKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := time.Now()
+	keys := make([]rotatedKey, 0, len(r.keys))
+	for i, k := range r.keys {
+		if i > 0 && (i > r.retain || now.Sub(k.retired) > r.grace) {
+			break
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// KeyID returns the kid of the signing key, or the empty string if
+// no key has been registered.
+func (r *Rotator) KeyID() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 {
+		return ""
+	}
+	return r.keys[0].jwk.Kid
+}
+
+// Sign sets the kid header of the token to the signing key and returns
+// the signed token. It returns ErrKeyNotFound if no key has been
+// registered.
+func (r *Rotator) Sign(t *Token) (string, error) {
+	r.mu.RLock()
+	if len(r.keys) == 0 {
+		r.mu.RUnlock()
+		return "", ErrKeyNotFound
+	}
+	k := r.keys[0]
+	r.mu.RUnlock()
+	t.Header[HeaderKeyID] = k.jwk.Kid
+	return t.Sign(k.sign)
+}
+
+// Key implements the KeyProvider interface. The key is selected by the
+// kid header of the token from the signing key and previous keys still
+// within their grace period.
+func (r *Rotator) Key(ctx context.Context, t *Token) ([]byte, error) {
+	kid, _ := t.Header[HeaderKeyID].(string)
+	for _, k := range r.active() {
+		if k.jwk.Kid == kid {
+			return k.verify, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// KeySet returns the public keys of the signing key and previous keys
+// still within their grace period, for publishing as a JWKS document.
+// Symmetric keys are never published.
+func (r *Rotator) KeySet() KeySet {
+	var s KeySet
+	for _, k := range r.active() {
+		if k.jwk.Kty == "oct" {
+			continue
+		}
+		s.Keys = append(s.Keys, k.jwk.Public())
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+func TestRotator(t *testing.T) {
+	keys := make([]JWK, 3)
+	for i := range keys {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, err := NewJWK(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = *k
+	}
+	keys[0].Kid = "a"
+	r := NewRotator(1, time.Hour)
+	_, err := r.Sign(New(ES256))
+	if err != ErrKeyNotFound {
+		t.Errorf("Sign err\nhave %v\nwant %v", err, ErrKeyNotFound)
+	}
+	err = r.Rotate(keys[0].Public())
+	if err != ErrKeyPublic {
+		t.Errorf("Rotate err\nhave %v\nwant %v", err, ErrKeyPublic)
+	}
+	tokens := make([]string, len(keys))
+	for i, k := range keys {
+		err = r.Rotate(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[i], err = r.Sign(New(ES256))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	kid, err := keys[2].Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.KeyID() != kid {
+		t.Errorf("KeyID\nhave %v\nwant %v", r.KeyID(), kid)
+	}
+	var tests = []struct {
+		jwt string
+		err error
+	}{
+		{tokens[0], ErrKeyNotFound},
+		{tokens[1], nil},
+		{tokens[2], nil},
+	}
+	v := NewVerifier([]Signer{ES256}, r)
+	for i, tt := range tests {
+		_, err := v.Verify(context.Background(), tt.jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+	s := r.KeySet()
+	if len(s.Keys) != 2 || s.Keys[0].Kid != kid || s.Keys[0].IsPrivate() || s.Keys[1].IsPrivate() {
+		t.Errorf("KeySet\nhave %v\nwant 2 public keys", s.Keys)
+	}
+	r = NewRotator(5, 0)
+	r.Rotate(keys[0])
+	r.Rotate(keys[1])
+	time.Sleep(time.Millisecond)
+	if len(r.KeySet().Keys) != 1 {
+		t.Errorf("KeySet should exclude keys past the grace period")
+	}
+	r.Rotate(JWK{Kty: "oct", Kid: "hmac", K: "c2VjcmV0"})
+	for _, k := range r.KeySet().Keys {
+		if k.Kty == "oct" {
+			t.Errorf("KeySet should not publish symmetric keys")
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRule is returned by Any when it has no rules.
+var ErrRule = errors.New("jwt: no rule passed")
+
+// Rule is a check of the claims of a token. Rules compose with All and
+// Any into policies that a flat list of options cannot express, such
+// as accepting tokens from several issuers each with its own audience.
+type Rule func(ctx context.Context, t *Token) error
+
+// MatchIssuer returns a rule requiring the iss claim to be one of the
+// issuers. It fails with ErrClaimIssuer.
+func MatchIssuer(iss ...string) Rule {
+	return func(ctx context.Context, t *Token) error {
+		have, _ := t.Claims[ClaimIssuer].(string)
+		if !contains(iss, have) {
+			return ErrClaimIssuer
+		}
+		return nil
+	}
+}
+
+// MatchAudience returns a rule requiring the aud claim to contain one
+// of the audiences. It fails with ErrClaimAudience.
+func MatchAudience(aud ...string) Rule {
+	return func(ctx context.Context, t *Token) error {
+		for _, have := range audience(t.Claims[ClaimAudience]) {
+			if contains(aud, have) {
+				return nil
+			}
+		}
+		return ErrClaimAudience
+	}
+}
+
+// All returns a rule requiring every rule to pass. It fails with the
+// error of the first rule that fails.
+func All(rules ...Rule) Rule {
+	return func(ctx context.Context, t *Token) error {
+		for _, r := range rules {
+			err := r(ctx, t)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Any returns a rule requiring at least one rule to pass. It fails with
+// the errors of every rule joined, so errors.Is matches any of them.
+func Any(rules ...Rule) Rule {
+	return func(ctx context.Context, t *Token) error {
+		errs := make([]error, 0, len(rules))
+		for _, r := range rules {
+			err := r(ctx, t)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return ErrRule
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// WithRules returns an option that requires the claims to pass every
+// rule, for example
+//
+//	WithRules(Any(
+//		All(MatchIssuer("https://a.example"), MatchAudience("x")),
+//		All(MatchIssuer("https://b.example"), MatchAudience("y")),
+//	))
+func WithRules(rules ...Rule) Option {
+	return func(v *Verifier) {
+		v.rules = append(v.rules, rules...)
+	}
+}
+
+// WithValidator returns an option that requires the claims to pass fn,
+// for domain-specific checks such as the tenant or scopes. It runs
+// after the signature and registered claims are verified, with the
+// rules, and its error is returned unchanged.
+func WithValidator(fn func(t *Token) error) Option {
+	return WithRules(func(ctx context.Context, t *Token) error {
+		return fn(t)
+	})
+}
+
+func (v *Verifier) checkRules(ctx context.Context, s *verification) error {
+	if len(v.rules) == 0 {
+		return skipped("no rules are configured")
+	}
+	return All(v.rules...)(ctx, s.token)
+}
@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithRules(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	federation := Any(
+		All(MatchIssuer("https://a.example"), MatchAudience("x")),
+		All(MatchIssuer("https://b.example"), MatchAudience("y")),
+	)
+	var tests = []struct {
+		payload string
+		rules   []Rule
+		err     error
+	}{
+		{`{"iss":"https://a.example","aud":"x"}`, []Rule{federation}, nil},
+		{`{"iss":"https://b.example","aud":["z","y"]}`, []Rule{federation}, nil},
+		{`{"iss":"https://a.example","aud":"y"}`, []Rule{federation}, ErrClaimAudience},
+		{`{"iss":"https://c.example","aud":"x"}`, []Rule{federation}, ErrClaimIssuer},
+		{`{"iss":"https://a.example","aud":"x"}`, []Rule{federation, MatchAudience("y")}, ErrClaimAudience},
+		{`{}`, []Rule{Any()}, ErrRule},
+		{`{}`, []Rule{All()}, nil},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), WithRules(tt.rules...))
+		if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+	custom := Rule(func(ctx context.Context, t *Token) error {
+		if t.Claims["sub"] != "admin" {
+			return ErrClaimRequired
+		}
+		return nil
+	})
+	_, err := Parse(HS256, signRaw(t, header, `{"sub":"admin"}`), []byte("secret"), WithRules(custom))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	errTenant := errors.New("tenant mismatch")
+	tenant := func(t *Token) error {
+		if t.Claims["tid"] != "acme" {
+			return errTenant
+		}
+		return nil
+	}
+	var tests = []struct {
+		jwt string
+		err error
+	}{
+		{signRaw(t, header, `{"tid":"acme"}`), nil},
+		{signRaw(t, header, `{"tid":"other"}`), errTenant},
+		{signRaw(t, header, `{"tid":"acme","exp":1}`), ErrClaimExpired},
+		{signRaw(t, header, `{"tid":"other"}`) + "x", ErrInvalidSignature},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, tt.jwt, []byte("secret"), WithValidator(tenant))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrStarted is returned when starting a component that is running.
+var ErrStarted = errors.New("jwt: already started")
+
+// Component is the interface implemented by components that run in
+// the background, such as a RemoteKeySet refreshing its keys or a
+// TokenManager replacing its token.
+type Component interface {
+	// Start starts the component. Background work stops when ctx is
+	// done or the component is closed.
+	Start(ctx context.Context) error
+
+	// Close stops the component and waits for background work to
+	// terminate. Close is safe to call more than once.
+	Close() error
+}
+
+// Runtime owns a set of background components so they can be started
+// with the application and closed together on shutdown.
+type Runtime struct {
+	mu         sync.Mutex
+	components []Component
+	started    []Component
+}
+
+// NewRuntime returns a new Runtime owning the components c.
+func NewRuntime(c ...Component) *Runtime {
+	return &Runtime{components: c}
+}
+
+// Add adds the component c to be started by the next call to Start.
+func (r *Runtime) Add(c Component) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components = append(r.components, c)
+}
+
+// Start starts each component in the order added. If a component
+// fails to start, the components already started are closed.
+func (r *Runtime) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.started) > 0 {
+		return ErrStarted
+	}
+	for _, c := range r.components {
+		err := c.Start(ctx)
+		if err != nil {
+			return errors.Join(err, r.close())
+		}
+		r.started = append(r.started, c)
+	}
+	return nil
+}
+
+// Close closes the started components in reverse order and returns
+// their errors joined.
+func (r *Runtime) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.close()
+}
+
+func (r *Runtime) close() error {
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		errs = append(errs, r.started[i].Close())
+	}
+	r.started = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeComponent struct {
+	name string
+	err  error
+	log  *[]string
+}
+
+func (c fakeComponent) Start(ctx context.Context) error {
+	*c.log = append(*c.log, "start "+c.name)
+	return c.err
+}
+
+func (c fakeComponent) Close() error {
+	*c.log = append(*c.log, "close "+c.name)
+	return nil
+}
+
+func TestRuntime(t *testing.T) {
+	errStart := errors.New("start failed")
+	var tests = []struct {
+		errs []error
+		err  error
+		log  []string
+	}{
+		{[]error{nil, nil}, nil, []string{"start 0", "start 1", "close 1", "close 0"}},
+		{[]error{nil, errStart, nil}, errStart, []string{"start 0", "start 1", "close 0"}},
+	}
+	for i, tt := range tests {
+		var log []string
+		r := NewRuntime()
+		for j, err := range tt.errs {
+			r.Add(fakeComponent{name: string(rune('0' + j)), err: err, log: &log})
+		}
+		err := r.Start(context.Background())
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. Start err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && r.Start(context.Background()) != ErrStarted {
+			t.Errorf("%d. should not start twice", i)
+		}
+		err = r.Close()
+		if err != nil {
+			t.Errorf("%d. Close err\nhave %v\nwant %v", i, err, nil)
+		}
+		if !reflect.DeepEqual(log, tt.log) {
+			t.Errorf("%d. lifecycle\nhave %v\nwant %v", i, log, tt.log)
+		}
+	}
+}
+
+var _ Component = (*RemoteKeySet)(nil)
+var _ Component = (*TokenManager)(nil)
+var _ HealthReporter = (*RemoteKeySet)(nil)
+var _ HealthReporter = (*FileKey)(nil)
@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"crypto/mldsa"
+	"reflect"
+	"testing"
+)
+
+func TestSchemeSigner(t *testing.T) {
+	b := []byte("foo")
+	for _, s := range []Signer{MLDSA44, MLDSA65, MLDSA87} {
+		params := s.(SchemeSigner).scheme.(mldsaScheme).params
+		priv, err := mldsa.GenerateKey(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privateKey, publicKey := priv.Bytes(), priv.PublicKey().Bytes()
+		sig, err := s.Sign(b, privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != params.SignatureSize() {
+			t.Fatalf("%s: signature size\nhave %d\nwant %d", s, len(sig), params.SignatureSize())
+		}
+		err = s.Verify(b, sig, publicKey)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		err = s.Verify(b, sig[:len(sig)-1], publicKey)
+		if err != ErrInvalidSignature {
+			t.Fatalf("%s: should reject truncated signature", s)
+		}
+		sig[0] ^= 0xFF
+		err = s.Verify(b, sig, publicKey)
+		if err != ErrInvalidSignature {
+			t.Fatalf("%s: should be invalid", s)
+		}
+	}
+}
+
+func TestSignMLDSA(t *testing.T) {
+	priv, err := mldsa.GenerateKey(mldsa.MLDSA44())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := New(MLDSA44)
+	token.Claims["foo"] = "bar"
+	jwt, err := token.Sign(priv.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(MLDSA44, jwt, priv.PublicKey().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["alg"] != "ML-DSA-44" || !reflect.DeepEqual(parsed.Claims, token.Claims) {
+		t.Errorf("unexpected token %v %v", parsed.Header, parsed.Claims)
+	}
+}
@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEmptySecret is returned when a secret has no value.
+var ErrEmptySecret = errors.New("secrets: secret has no value")
+
+// AWSClient is the subset of the AWS Secrets Manager API used by
+// AWSSecretsManager. It is satisfied by a small wrapper around the
+// GetSecretValue operation of the AWS SDK client.
+type AWSClient interface {
+	GetSecretValue(ctx context.Context, secretID string) (AWSSecret, error)
+}
+
+// AWSSecret is the result of a GetSecretValue operation.
+type AWSSecret struct {
+	SecretString string
+	SecretBinary []byte
+	VersionID    string
+}
+
+// AWSSecretsManager is a Source that reads an AWS Secrets Manager secret.
+type AWSSecretsManager struct {
+	Client   AWSClient
+	SecretID string
+}
+
+// Fetch implements the Source interface.
+// The version is the secret version ID.
+func (s *AWSSecretsManager) Fetch(ctx context.Context) ([]byte, string, error) {
+	v, err := s.Client.GetSecretValue(ctx, s.SecretID)
+	if err != nil {
+		return nil, "", err
+	}
+	if v.SecretString != "" {
+		return []byte(v.SecretString), v.VersionID, nil
+	}
+	if len(v.SecretBinary) == 0 {
+		return nil, "", ErrEmptySecret
+	}
+	return v.SecretBinary, v.VersionID, nil
+}
+
+// GCPClient is the subset of the GCP Secret Manager API used by
+// GCPSecretManager. It is satisfied by a small wrapper around the
+// AccessSecretVersion operation of the Google Cloud client.
+type GCPClient interface {
+	// AccessSecretVersion returns the payload data and the resolved
+	// resource name of the secret version.
+	AccessSecretVersion(ctx context.Context, name string) (data []byte, version string, err error)
+}
+
+// GCPSecretManager is a Source that reads a GCP Secret Manager secret.
+type GCPSecretManager struct {
+	Client GCPClient
+
+	// Name is the secret version resource name, such as
+	// "projects/p/secrets/s/versions/latest".
+	Name string
+}
+
+// Fetch implements the Source interface.
+// The version is the resolved secret version resource name.
+func (s *GCPSecretManager) Fetch(ctx context.Context) ([]byte, string, error) {
+	data, version, err := s.Client.AccessSecretVersion(ctx, s.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) == 0 {
+		return nil, "", ErrEmptySecret
+	}
+	return data, version, nil
+}
@@ -0,0 +1,84 @@
+// Package secrets implements key providers backed by secret managers
+// such as HashiCorp Vault, AWS Secrets Manager and GCP Secret Manager.
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// Source fetches the current value of a secret.
+type Source interface {
+	// Fetch returns the secret value and an opaque version identifier
+	// that changes whenever the secret is rotated.
+	Fetch(ctx context.Context) (value []byte, version string, err error)
+}
+
+// Provider is a jwt.KeyProvider that caches the key fetched from a Source.
+type Provider struct {
+	src      Source
+	ttl      time.Duration
+	onRotate func(old, new string)
+	mu       sync.Mutex
+	key      []byte
+	version  string
+	fetched  time.Time
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithTTL returns an option that sets how long a fetched key is cached.
+// The default is five minutes.
+func WithTTL(d time.Duration) Option {
+	return func(p *Provider) {
+		p.ttl = d
+	}
+}
+
+// WithRotateHook returns an option that calls fn with the previous
+// and current versions whenever a refresh detects a rotated secret.
+func WithRotateHook(fn func(old, new string)) Option {
+	return func(p *Provider) {
+		p.onRotate = fn
+	}
+}
+
+// New returns a new Provider for the secret source.
+func New(src Source, opts ...Option) *Provider {
+	p := &Provider{src: src, ttl: 5 * time.Minute}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Key implements the jwt.KeyProvider interface.
+func (p *Provider) Key(ctx context.Context, t *jwt.Token) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.key != nil && time.Since(p.fetched) < p.ttl {
+		return p.key, nil
+	}
+	key, version, err := p.src.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.key != nil && version != p.version && p.onRotate != nil {
+		p.onRotate(p.version, version)
+	}
+	p.key = key
+	p.version = version
+	p.fetched = time.Now()
+	return key, nil
+}
+
+// Version returns the version of the cached secret.
+func (p *Provider) Version() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.version
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+type awsClient struct {
+	version int
+}
+
+func (c *awsClient) GetSecretValue(ctx context.Context, secretID string) (AWSSecret, error) {
+	return AWSSecret{
+		SecretString: fmt.Sprintf("%s-%d", secretID, c.version),
+		VersionID:    fmt.Sprintf("v%d", c.version),
+	}, nil
+}
+
+func TestProvider(t *testing.T) {
+	client := &awsClient{version: 1}
+	var rotated []string
+	p := New(&AWSSecretsManager{Client: client, SecretID: "key"}, WithTTL(0), WithRotateHook(func(old, new string) {
+		rotated = append(rotated, old, new)
+	}))
+	var tests = []struct {
+		version int
+		key     string
+	}{
+		{1, "key-1"},
+		{1, "key-1"},
+		{2, "key-2"},
+	}
+	for i, tt := range tests {
+		client.version = tt.version
+		key, err := p.Key(context.Background(), jwt.New(jwt.HS256))
+		if err != nil {
+			t.Errorf("%d. Key err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if string(key) != tt.key {
+			t.Errorf("%d. Key\nhave %s\nwant %s", i, key, tt.key)
+		}
+	}
+	if len(rotated) != 2 || rotated[0] != "v1" || rotated[1] != "v2" {
+		t.Errorf("rotate hook\nhave %v\nwant %v", rotated, []string{"v1", "v2"})
+	}
+}
+
+func TestProviderCache(t *testing.T) {
+	client := &awsClient{version: 1}
+	p := New(&AWSSecretsManager{Client: client, SecretID: "key"}, WithTTL(time.Hour))
+	_, err := p.Key(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.version = 2
+	key, err := p.Key(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != "key-1" || p.Version() != "v1" {
+		t.Errorf("should return cached key")
+	}
+}
+
+func TestVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/jwt/signing" || r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"key":"secret"},"metadata":{"version":3}}}`))
+	}))
+	defer srv.Close()
+	v := &Vault{Addr: srv.URL, Token: "token", Path: "jwt/signing", Field: "key"}
+	key, version, err := v.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != "secret" || version != "3" {
+		t.Errorf("have %s %s\nwant %s %s", key, version, "secret", "3")
+	}
+	v.Field = "missing"
+	_, _, err = v.Fetch(context.Background())
+	if err == nil {
+		t.Errorf("should return missing field error")
+	}
+}
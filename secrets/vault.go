@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pnelson/jwt"
+)
+
+// Vault is a Source that reads a field of a Vault KV version 2 secret.
+type Vault struct {
+	// Addr is the Vault server address, such as "https://vault:8200".
+	Addr string
+
+	// Token is the Vault token used to authenticate.
+	Token string
+
+	// Mount is the KV secrets engine mount path. Defaults to "secret".
+	Mount string
+
+	// Path is the secret path within the mount.
+	Path string
+
+	// Field is the secret data field containing the key.
+	Field string
+
+	// Client is the HTTP client. Defaults to jwt.DefaultHTTPClient.
+	Client *http.Client
+}
+
+// Fetch implements the Source interface.
+// The version is the KV metadata version of the secret.
+func (v *Vault) Fetch(ctx context.Context) ([]byte, string, error) {
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	url := strings.TrimSuffix(v.Addr, "/") + "/v1/" + mount + "/data/" + strings.TrimPrefix(v.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	client := v.Client
+	if client == nil {
+		client = jwt.DefaultHTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("secrets: vault %s: unexpected status %s", v.Path, resp.Status)
+	}
+	var body struct {
+		Data struct {
+			Data     map[string]string `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, "", err
+	}
+	value, ok := body.Data.Data[v.Field]
+	if !ok {
+		return nil, "", fmt.Errorf("secrets: vault %s: field %q not found", v.Path, v.Field)
+	}
+	return []byte(value), strconv.Itoa(body.Data.Metadata.Version), nil
+}
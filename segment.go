@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SyntaxError is returned when the header or claims segment of a token
+// is not valid JSON.
+type SyntaxError struct {
+	// Segment is the token segment, "header" or "claims".
+	Segment string
+
+	// Offset is the byte offset in the decoded segment after which
+	// the error occurred.
+	Offset int64
+
+	// Err is the underlying *json.SyntaxError.
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jwt: %s is not valid json at offset %d: %v", e.Segment, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// TypeError is returned when the header or claims segment of a token
+// is valid JSON but is not a JSON object. Claims that are well-formed
+// but fail validation return the ErrClaim errors instead.
+type TypeError struct {
+	// Segment is the token segment, "header" or "claims".
+	Segment string
+
+	// Offset is the byte offset in the decoded segment after which
+	// the error occurred.
+	Offset int64
+
+	// Value describes the JSON value, such as "array" or "string".
+	Value string
+
+	// Err is the underlying *json.UnmarshalTypeError.
+	Err error
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("jwt: %s is a json %s at offset %d, want object", e.Segment, e.Value, e.Offset)
+}
+
+// Unwrap returns the underlying error.
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalSegment decodes the JSON segment b into v, returning a
+// *SyntaxError or *TypeError identifying the segment on failure.
+func unmarshalSegment(segment string, b []byte, v interface{}) error {
+	err := json.Unmarshal(b, v)
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		return &SyntaxError{Segment: segment, Offset: syntax.Offset, Err: err}
+	}
+	var typ *json.UnmarshalTypeError
+	if errors.As(err, &typ) && typ.Field == "" {
+		return &TypeError{Segment: segment, Offset: typ.Offset, Value: typ.Value, Err: err}
+	}
+	return err
+}
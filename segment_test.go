@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSegmentErrors(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		header  string
+		payload string
+		segment string
+		offset  int64
+		syntax  bool
+	}{
+		{header, `{"sub":}`, "claims", 8, true},
+		{header, `{"sub":"user"`, "claims", 13, true},
+		{`{"alg":`, `{}`, "header", 7, true},
+		{header, `["sub"]`, "claims", 1, false},
+		{`"JWT"`, `{}`, "header", 5, false},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, tt.header, tt.payload), []byte("secret"))
+		var syntax *SyntaxError
+		var typ *TypeError
+		switch {
+		case errors.As(err, &syntax) && tt.syntax:
+			if syntax.Segment != tt.segment || syntax.Offset != tt.offset {
+				t.Errorf("%d. SyntaxError\nhave %s %d\nwant %s %d", i, syntax.Segment, syntax.Offset, tt.segment, tt.offset)
+			}
+		case errors.As(err, &typ) && !tt.syntax:
+			if typ.Segment != tt.segment || typ.Offset != tt.offset {
+				t.Errorf("%d. TypeError\nhave %s %d\nwant %s %d", i, typ.Segment, typ.Offset, tt.segment, tt.offset)
+			}
+		default:
+			t.Errorf("%d. Parse err\nhave %v\nwant syntax %v", i, err, tt.syntax)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRevoked is returned when a token has been revoked.
+var ErrRevoked = errors.New("jwt: token has been revoked")
+
+// SessionID returns the sid claim identifying the session in which the
+// token was issued. It returns false if the claim is not present or
+// not a non-empty string.
+//
+// See OpenID Connect Front-Channel Logout 1.0 Section 3.
+func (c Claims) SessionID() (string, bool) {
+	sid, ok := c[ClaimSessionID].(string)
+	return sid, ok && sid != ""
+}
+
+// SessionID returns the sid claim of the token.
+func (t *Token) SessionID() (string, bool) {
+	return Claims(t.Claims).SessionID()
+}
+
+// SetSessionID sets the sid claim of the token.
+func (t *Token) SetSessionID(sid string) {
+	if t.Claims == nil {
+		t.Claims = make(map[string]interface{})
+	}
+	t.Claims[ClaimSessionID] = sid
+}
+
+// Revocations is the interface that reports whether a verified token
+// has been revoked, such as by a blocklist of token or session
+// identifiers.
+type Revocations interface {
+	Revoked(ctx context.Context, t *Token) (bool, error)
+}
+
+// WithRevocations returns an option that rejects tokens revoked by r
+// with ErrRevoked.
+func WithRevocations(r Revocations) Option {
+	return func(v *Verifier) {
+		v.revocations = r
+	}
+}
+
+// SessionBlocklist is an in-memory Revocations that revokes every
+// token issued in a session, as required by back-channel logout.
+// Revoked sessions are forgotten once tokens issued in them have
+// expired.
+type SessionBlocklist struct {
+	ttl      time.Duration
+	mu       sync.Mutex
+	sessions map[string]time.Time
+}
+
+// NewSessionBlocklist returns a new SessionBlocklist. The ttl is the
+// maximum lifetime of tokens issued in a session.
+func NewSessionBlocklist(ttl time.Duration) *SessionBlocklist {
+	return &SessionBlocklist{ttl: ttl, sessions: make(map[string]time.Time)}
+}
+
+// RevokeSession revokes every token issued in the session sid.
+func (b *SessionBlocklist) RevokeSession(sid string) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, exp := range b.sessions {
+		if now.After(exp) {
+			delete(b.sessions, k)
+		}
+	}
+	b.sessions[sid] = now.Add(b.ttl)
+}
+
+// Logout revokes the session identified by the sid claim of a verified
+// back-channel logout token. ErrClaimRequired is returned if the
+// logout token does not identify a session.
+//
+// See OpenID Connect Back-Channel Logout 1.0 Section 2.4.
+func (b *SessionBlocklist) Logout(t *Token) error {
+	sid, ok := t.SessionID()
+	if !ok {
+		return ErrClaimRequired
+	}
+	b.RevokeSession(sid)
+	return nil
+}
+
+// Revoked implements the Revocations interface.
+func (b *SessionBlocklist) Revoked(ctx context.Context, t *Token) (bool, error) {
+	sid, ok := t.SessionID()
+	if !ok {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	exp, ok := b.sessions[sid]
+	return ok && time.Now().Before(exp), nil
+}
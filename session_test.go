@@ -0,0 +1,67 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionID(t *testing.T) {
+	var tests = []struct {
+		claims map[string]interface{}
+		sid    string
+		ok     bool
+	}{
+		{map[string]interface{}{"sid": "abc"}, "abc", true},
+		{map[string]interface{}{"sid": ""}, "", false},
+		{map[string]interface{}{"sid": 1.0}, "", false},
+		{map[string]interface{}{}, "", false},
+	}
+	for i, tt := range tests {
+		token := &Token{Claims: tt.claims}
+		sid, ok := token.SessionID()
+		if sid != tt.sid || ok != tt.ok {
+			t.Errorf("%d. SessionID\nhave %q %v\nwant %q %v", i, sid, ok, tt.sid, tt.ok)
+		}
+	}
+}
+
+func TestSessionBlocklist(t *testing.T) {
+	key := []byte("secret")
+	sign := func(claims map[string]interface{}) string {
+		token := New(HS256)
+		token.Claims = claims
+		jwt, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jwt
+	}
+	b := NewSessionBlocklist(time.Hour)
+	logout := New(HS256)
+	logout.SetSessionID("revoked")
+	err := b.Logout(logout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Logout(New(HS256))
+	if err != ErrClaimRequired {
+		t.Errorf("Logout err\nhave %v\nwant %v", err, ErrClaimRequired)
+	}
+	v := NewVerifier([]Signer{HS256}, StaticKey(key), WithRevocations(b))
+	var tests = []struct {
+		claims map[string]interface{}
+		err    error
+	}{
+		{map[string]interface{}{"sid": "active"}, nil},
+		{map[string]interface{}{"sid": "revoked"}, ErrRevoked},
+		{map[string]interface{}{"sid": 1.0}, ErrClaimType},
+		{map[string]interface{}{}, nil},
+	}
+	for i, tt := range tests {
+		_, err := v.Verify(context.Background(), sign(tt.claims))
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -3,14 +3,16 @@ package jwt
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
-	"encoding/pem"
+	"encoding/asn1"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -26,16 +28,25 @@ var (
 	RS384 = NewRSASigner("RS384", crypto.SHA384)
 	RS512 = NewRSASigner("RS512", crypto.SHA512)
 
+	// RSA-PSS
+	PS256 = NewRSAPSSSigner("PS256", crypto.SHA256)
+	PS384 = NewRSAPSSSigner("PS384", crypto.SHA384)
+	PS512 = NewRSAPSSSigner("PS512", crypto.SHA512)
+
 	// ECDSA
 	ES256 = NewECDSASigner("ES256", crypto.SHA256)
 	ES384 = NewECDSASigner("ES384", crypto.SHA384)
 	ES512 = NewECDSASigner("ES512", crypto.SHA512)
+
+	// EdDSA
+	EdDSA = NewEdDSASigner("EdDSA")
 )
 
 // Signer errors.
 var (
 	ErrHashUnavailable  = errors.New("jwt: hash unavailable")
 	ErrInvalidSignature = errors.New("jwt: invalid signature")
+	ErrKeyTooShort      = errors.New("jwt: key is shorter than the hash output")
 )
 
 // Signer is the interface that signs and verifies data.
@@ -52,8 +63,9 @@ type Signer interface {
 
 // HMACSigner is a signer for HMAC over the crypto.Hash interface.
 type HMACSigner struct {
-	name string
-	hash crypto.Hash
+	name   string
+	hash   crypto.Hash
+	strict bool
 }
 
 // NewHMACSigner returns a new HMACSigner.
@@ -61,6 +73,14 @@ func NewHMACSigner(name string, hash crypto.Hash) HMACSigner {
 	return HMACSigner{name: name, hash: hash}
 }
 
+// NewHMACSignerStrict returns a new HMACSigner that rejects keys shorter
+// than the hash output size with ErrKeyTooShort.
+//
+// See RFC 7518 Section 3.2.
+func NewHMACSignerStrict(name string, hash crypto.Hash) HMACSigner {
+	return HMACSigner{name: name, hash: hash, strict: true}
+}
+
 // Sign returns the signature of the data.
 func (s HMACSigner) Sign(b, key []byte) ([]byte, error) {
 	return s.digest(b, key)
@@ -78,6 +98,26 @@ func (s HMACSigner) Verify(b, sig, key []byte) error {
 	return nil
 }
 
+// SignKey returns the signature of the data.
+// The key must be a []byte secret.
+func (s HMACSigner) SignKey(b []byte, key interface{}) ([]byte, error) {
+	k, ok := key.([]byte)
+	if !ok {
+		return nil, keyTypeError(key)
+	}
+	return s.Sign(b, k)
+}
+
+// VerifyKey returns an error if the signature is invalid.
+// The key must be a []byte secret.
+func (s HMACSigner) VerifyKey(b, sig []byte, key interface{}) error {
+	k, ok := key.([]byte)
+	if !ok {
+		return keyTypeError(key)
+	}
+	return s.Verify(b, sig, k)
+}
+
 // String implements the fmt.Stringer interface.
 func (s HMACSigner) String() string {
 	return s.name
@@ -87,6 +127,9 @@ func (s HMACSigner) digest(b, key []byte) ([]byte, error) {
 	if !s.hash.Available() {
 		return nil, ErrHashUnavailable
 	}
+	if s.strict && len(key) < s.hash.Size() {
+		return nil, ErrKeyTooShort
+	}
 	h := hmac.New(s.hash.New, key)
 	_, err := h.Write(b)
 	if err != nil {
@@ -107,35 +150,129 @@ func NewRSASigner(name string, hash crypto.Hash) RSASigner {
 }
 
 // Sign returns the signature of the data.
-// The key is expected to be a PEM-encoded RSA private key.
+// The key is expected to be a PEM-encoded PKCS #1 or PKCS #8 RSA private key.
 func (e RSASigner) Sign(b, key []byte) ([]byte, error) {
-	priv, err := e.decodePrivateKey(key)
+	priv, err := decodeRSAPrivateKey(key)
 	if err != nil {
 		return nil, err
 	}
+	return e.sign(b, priv)
+}
+
+// SignKey returns the signature of the data.
+// The key may be an *rsa.PrivateKey, a crypto.Signer with an RSA public
+// key, such as a key held in an HSM or KMS, or a PEM-encoded RSA private key.
+func (e RSASigner) SignKey(b []byte, key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		return e.Sign(b, k)
+	case crypto.Signer:
+		if _, ok := k.Public().(*rsa.PublicKey); !ok {
+			return nil, keyTypeError(key)
+		}
+		return e.sign(b, k)
+	}
+	return nil, keyTypeError(key)
+}
+
+func (e RSASigner) sign(b []byte, priv crypto.Signer) ([]byte, error) {
 	hash, err := hash(e.hash, b)
 	if err != nil {
 		return nil, err
 	}
-	return rsa.SignPKCS1v15(rand.Reader, priv, e.hash, hash)
+	return priv.Sign(rand.Reader, hash, e.hash)
 }
 
-// decodePrivateKey decodes a PEM-encoded RSA private key.
-func (e RSASigner) decodePrivateKey(b []byte) (*rsa.PrivateKey, error) {
-	block, _ := pem.Decode(b)
-	if block == nil || block.Type != "RSA PRIVATE KEY" {
-		return nil, errors.New("jwt: invalid rsa private key")
+// decodeRSAPrivateKey decodes a PEM-encoded PKCS #1 or PKCS #8 RSA
+// private key. The key is validated for consistency and errors identify
+// whether the encoding, type or key material is at fault.
+func decodeRSAPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block := decodeKeyBlock(b)
+	if block == nil {
+		return nil, ErrKeyNotPEM
+	}
+	if block.Type == "PRIVATE KEY" {
+		return decodePKCS8RSAPrivateKey(block.Bytes)
+	}
+	if block.Type != "RSA PRIVATE KEY" {
+		return nil, fmt.Errorf("%w: have %s, want RSA PRIVATE KEY", ErrKeyType, block.Type)
+	}
+	var k pkcs1PrivateKey
+	rest, err := asn1.Unmarshal(block.Bytes, &k)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrKeyCorrupt)
 	}
-	return x509.ParsePKCS1PrivateKey(block.Bytes)
+	if len(k.AdditionalPrimes) > 0 {
+		return nil, ErrKeyMultiPrime
+	}
+	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyInconsistent, err)
+	}
+	err = priv.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyInconsistent, err)
+	}
+	return priv, nil
+}
+
+// decodePKCS8RSAPrivateKey decodes a DER-encoded PKCS #8 RSA private key.
+func decodePKCS8RSAPrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
+	}
+	priv, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: have %T, want rsa private key", ErrKeyType, key)
+	}
+	if len(priv.Primes) > 2 {
+		return nil, ErrKeyMultiPrime
+	}
+	err = priv.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyInconsistent, err)
+	}
+	return priv, nil
+}
+
+// pkcs1PrivateKey is the structure of a PKCS #1 RSA private key.
+//
+// See RFC 8017 Appendix A.1.2.
+type pkcs1PrivateKey struct {
+	Version          int
+	N, E, D, P, Q    *big.Int
+	Dp, Dq, Qinv     *big.Int
+	AdditionalPrimes []asn1.RawValue `asn1:"optional,omitempty"`
 }
 
 // Verify returns an error if the signature is invalid.
 // The key is expected to be a PEM-encoded RSA public key.
 func (e RSASigner) Verify(b, sig, key []byte) error {
-	pub, err := e.decodePublicKey(key)
+	pub, err := decodeRSAPublicKey(key)
+	if err != nil {
+		return err
+	}
+	return e.verify(b, sig, pub)
+}
+
+// VerifyKey returns an error if the signature is invalid.
+// The key may be an *rsa.PublicKey or a PEM-encoded RSA public key.
+func (e RSASigner) VerifyKey(b, sig []byte, key interface{}) error {
+	pub, err := rsaPublicKey(key)
 	if err != nil {
 		return err
 	}
+	if pub == nil {
+		return e.Verify(b, sig, key.([]byte))
+	}
+	return e.verify(b, sig, pub)
+}
+
+func (e RSASigner) verify(b, sig []byte, pub *rsa.PublicKey) error {
 	hash, err := hash(e.hash, b)
 	if err != nil {
 		return err
@@ -147,34 +284,159 @@ func (e RSASigner) Verify(b, sig, key []byte) error {
 	return nil
 }
 
-// decodePublicKey decodes a PEM-encoded RSA public key.
-func (e RSASigner) decodePublicKey(b []byte) (*rsa.PublicKey, error) {
-	block, _ := pem.Decode(b)
-	if block == nil || block.Type != "PUBLIC KEY" {
-		return nil, errors.New("jwt: invalid rsa public key")
+// decodeRSAPublicKey decodes a PEM-encoded RSA public key.
+func decodeRSAPublicKey(b []byte) (*rsa.PublicKey, error) {
+	block := decodeKeyBlock(b)
+	if block == nil {
+		return nil, ErrKeyNotPEM
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%w: have %s, want PUBLIC KEY", ErrKeyType, block.Type)
 	}
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrKeyCorrupt, err)
 	}
 	key, ok := pub.(*rsa.PublicKey)
 	if !ok {
-		return nil, errors.New("jwt: invalid rsa public key")
+		return nil, fmt.Errorf("%w: have %T, want rsa public key", ErrKeyType, pub)
 	}
 	return key, nil
 }
 
+// rsaPublicKey returns the native RSA public key, the public part of a
+// native private key, or nil if key is []byte.
+func rsaPublicKey(key interface{}) (*rsa.PublicKey, error) {
+	switch k := key.(type) {
+	case []byte:
+		return nil, nil
+	case *rsa.PublicKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return &k.PublicKey, nil
+	case crypto.Signer:
+		if pub, ok := k.Public().(*rsa.PublicKey); ok {
+			return pub, nil
+		}
+	}
+	return nil, keyTypeError(key)
+}
+
 // String implements the fmt.Stringer interface.
 func (e RSASigner) String() string {
 	return e.name
 }
 
+// RSAPSSSigner is a signer for RSASSA-PSS signatures.
+type RSAPSSSigner struct {
+	name       string
+	hash       crypto.Hash
+	saltLength int
+}
+
+// NewRSAPSSSigner returns a new RSAPSSSigner with a salt the size of
+// the hash output as required by RFC 7518 Section 3.5.
+func NewRSAPSSSigner(name string, hash crypto.Hash) RSAPSSSigner {
+	return NewRSAPSSSignerSaltLength(name, hash, rsa.PSSSaltLengthEqualsHash)
+}
+
+// NewRSAPSSSignerSaltLength returns a new RSAPSSSigner with the salt
+// length, in bytes, or one of rsa.PSSSaltLengthAuto and
+// rsa.PSSSaltLengthEqualsHash. With rsa.PSSSaltLengthAuto, signatures
+// use the maximum salt length and signatures with any salt length
+// are accepted.
+func NewRSAPSSSignerSaltLength(name string, hash crypto.Hash, saltLength int) RSAPSSSigner {
+	return RSAPSSSigner{name: name, hash: hash, saltLength: saltLength}
+}
+
+// Sign returns the signature of the data.
+// The key is expected to be a PEM-encoded PKCS #1 or PKCS #8 RSA private key.
+func (e RSAPSSSigner) Sign(b, key []byte) ([]byte, error) {
+	priv, err := decodeRSAPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return e.sign(b, priv)
+}
+
+// SignKey returns the signature of the data.
+// The key may be an *rsa.PrivateKey, a crypto.Signer with an RSA public
+// key, such as a key held in an HSM or KMS, or a PEM-encoded RSA private key.
+func (e RSAPSSSigner) SignKey(b []byte, key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		return e.Sign(b, k)
+	case crypto.Signer:
+		if _, ok := k.Public().(*rsa.PublicKey); !ok {
+			return nil, keyTypeError(key)
+		}
+		return e.sign(b, k)
+	}
+	return nil, keyTypeError(key)
+}
+
+func (e RSAPSSSigner) sign(b []byte, priv crypto.Signer) ([]byte, error) {
+	hash, err := hash(e.hash, b)
+	if err != nil {
+		return nil, err
+	}
+	return priv.Sign(rand.Reader, hash, e.options())
+}
+
+// Verify returns an error if the signature is invalid.
+// The key is expected to be a PEM-encoded RSA public key.
+func (e RSAPSSSigner) Verify(b, sig, key []byte) error {
+	pub, err := decodeRSAPublicKey(key)
+	if err != nil {
+		return err
+	}
+	return e.verify(b, sig, pub)
+}
+
+// VerifyKey returns an error if the signature is invalid.
+// The key may be an *rsa.PublicKey or a PEM-encoded RSA public key.
+func (e RSAPSSSigner) VerifyKey(b, sig []byte, key interface{}) error {
+	pub, err := rsaPublicKey(key)
+	if err != nil {
+		return err
+	}
+	if pub == nil {
+		return e.Verify(b, sig, key.([]byte))
+	}
+	return e.verify(b, sig, pub)
+}
+
+func (e RSAPSSSigner) verify(b, sig []byte, pub *rsa.PublicKey) error {
+	hash, err := hash(e.hash, b)
+	if err != nil {
+		return err
+	}
+	err = rsa.VerifyPSS(pub, e.hash, hash, sig, e.options())
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// options returns the PSS options.
+func (e RSAPSSSigner) options() *rsa.PSSOptions {
+	return &rsa.PSSOptions{SaltLength: e.saltLength, Hash: e.hash}
+}
+
+// String implements the fmt.Stringer interface.
+func (e RSAPSSSigner) String() string {
+	return e.name
+}
+
 // ECDSASigner is a signer for ECDSA signatures.
 type ECDSASigner struct {
-	name      string
-	hash      crypto.Hash
-	keySize   int
-	curveBits int
+	name          string
+	hash          crypto.Hash
+	keySize       int
+	curveBits     int
+	curve         *curve
+	der           bool
+	deterministic bool
 }
 
 // NewECDSASigner returns a new ECDSASigner.
@@ -182,36 +444,89 @@ func NewECDSASigner(name string, hash crypto.Hash) ECDSASigner {
 	return ECDSASigner{name: name, hash: hash}
 }
 
+// NewECDSASignerDER returns a new ECDSASigner whose Verify also accepts
+// ASN.1 DER encoded signatures, as produced by many HSMs and other
+// systems. Signatures are always produced in the fixed width R || S
+// format required by JWS; the DER signatures of a crypto.Signer, such
+// as a KMS or HSM key, are converted automatically.
+func NewECDSASignerDER(name string, hash crypto.Hash) ECDSASigner {
+	return ECDSASigner{name: name, hash: hash, der: true}
+}
+
+// NewECDSASignerDeterministic returns a new ECDSASigner that derives
+// the nonce from the key and message, so signing the same data with the
+// same key yields the same signature without relying on entropy.
+// Only the P-256, P-384 and P-521 curves are supported.
+//
+// See RFC 6979.
+func NewECDSASignerDeterministic(name string, hash crypto.Hash) ECDSASigner {
+	return ECDSASigner{name: name, hash: hash, deterministic: true}
+}
+
 // Sign returns the signature of the data.
-// The key is expected to be a PEM-encoded ECDSA private key.
+// The key is expected to be a PEM-encoded SEC 1 or PKCS #8 ECDSA private key.
 func (e ECDSASigner) Sign(b, key []byte) ([]byte, error) {
 	priv, err := e.decodePrivateKey(key)
 	if err != nil {
 		return nil, err
 	}
+	return e.sign(b, priv)
+}
+
+// SignKey returns the signature of the data.
+// The key may be an *ecdsa.PrivateKey, a crypto.Signer with an ECDSA
+// public key or a PEM-encoded ECDSA private key. Deterministic signers
+// only sign deterministically with an *ecdsa.PrivateKey.
+func (e ECDSASigner) SignKey(b []byte, key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		return e.Sign(b, k)
+	case crypto.Signer:
+		if _, ok := k.Public().(*ecdsa.PublicKey); !ok {
+			return nil, keyTypeError(key)
+		}
+		return e.sign(b, k)
+	}
+	return nil, keyTypeError(key)
+}
+
+func (e ECDSASigner) sign(b []byte, priv crypto.Signer) ([]byte, error) {
 	hash, err := hash(e.hash, b)
 	if err != nil {
 		return nil, err
 	}
-	r, s, err := ecdsa.Sign(rand.Reader, priv, hash)
+	var r io.Reader = rand.Reader
+	if _, ok := priv.(*ecdsa.PrivateKey); ok && e.deterministic {
+		r = nil
+	}
+	sig, err := priv.Sign(r, hash, e.hash)
 	if err != nil {
 		return nil, err
 	}
-	n := e.getKeySize(priv.Curve)
-	rb := r.Bytes()
-	sb := s.Bytes()
-	sig := make([]byte, 2*n)
-	copy(sig[n-len(rb):], rb)
-	copy(sig[n*2-len(sb):], sb)
-	return sig, nil
+	return ECDSASignatureToRaw(sig, priv.Public().(*ecdsa.PublicKey).Curve)
 }
 
-// decodePrivateKey decodes a PEM-encoded ECDSA private key.
+// decodePrivateKey decodes a PEM-encoded SEC 1 or PKCS #8 ECDSA
+// private key.
 func (e ECDSASigner) decodePrivateKey(b []byte) (*ecdsa.PrivateKey, error) {
-	block, _ := pem.Decode(b)
+	block := decodeKeyBlock(b)
+	if block != nil && block.Type == "PRIVATE KEY" && e.curve == nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("jwt: invalid ecdsa private key")
+		}
+		return priv, nil
+	}
 	if block == nil || block.Type != "EC PRIVATE KEY" {
 		return nil, errors.New("jwt: invalid ecdsa private key")
 	}
+	if e.curve != nil {
+		return e.curve.parsePrivateKey(block.Bytes)
+	}
 	return x509.ParseECPrivateKey(block.Bytes)
 }
 
@@ -222,7 +537,36 @@ func (e ECDSASigner) Verify(b, sig, key []byte) error {
 	if err != nil {
 		return err
 	}
+	return e.verify(b, sig, pub)
+}
+
+// VerifyKey returns an error if the signature is invalid.
+// The key may be an *ecdsa.PublicKey or a PEM-encoded ECDSA public key.
+func (e ECDSASigner) VerifyKey(b, sig []byte, key interface{}) error {
+	switch k := key.(type) {
+	case []byte:
+		return e.Verify(b, sig, k)
+	case *ecdsa.PublicKey:
+		return e.verify(b, sig, k)
+	case *ecdsa.PrivateKey:
+		return e.verify(b, sig, &k.PublicKey)
+	case crypto.Signer:
+		if pub, ok := k.Public().(*ecdsa.PublicKey); ok {
+			return e.verify(b, sig, pub)
+		}
+	}
+	return keyTypeError(key)
+}
+
+func (e ECDSASigner) verify(b, sig []byte, pub *ecdsa.PublicKey) error {
+	var err error
 	keySize := e.getKeySize(pub.Curve)
+	if e.der && len(sig) != 2*keySize {
+		sig, err = ECDSASignatureToRaw(sig, pub.Curve)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+	}
 	if len(sig) != 2*keySize {
 		return ErrInvalidSignature
 	}
@@ -240,10 +584,13 @@ func (e ECDSASigner) Verify(b, sig, key []byte) error {
 
 // decodePublicKey decodes a PEM-encoded ECDSA public key.
 func (e ECDSASigner) decodePublicKey(b []byte) (*ecdsa.PublicKey, error) {
-	block, _ := pem.Decode(b)
+	block := decodeKeyBlock(b)
 	if block == nil || block.Type != "PUBLIC KEY" {
 		return nil, errors.New("jwt: invalid ecdsa public key")
 	}
+	if e.curve != nil {
+		return e.curve.parsePublicKey(block.Bytes)
+	}
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
 		return nil, err
@@ -260,6 +607,48 @@ func (e ECDSASigner) String() string {
 	return e.name
 }
 
+// ecdsaSignature is an ASN.1 DER encoded ECDSA signature.
+//
+// See RFC 3279 Section 2.2.3.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// ECDSASignatureToRaw converts an ASN.1 DER encoded ECDSA signature to
+// the fixed width R || S format used by JWS for the curve.
+//
+// See RFC 7518 Section 3.4.
+func ECDSASignatureToRaw(der []byte, curve elliptic.Curve) ([]byte, error) {
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 || sig.R == nil || sig.S == nil || sig.R.Sign() <= 0 || sig.S.Sign() <= 0 {
+		return nil, ErrInvalidSignature
+	}
+	n := ECDSASigner{}.getKeySize(curve)
+	if sig.R.BitLen() > 8*n || sig.S.BitLen() > 8*n {
+		return nil, ErrInvalidSignature
+	}
+	raw := make([]byte, 2*n)
+	sig.R.FillBytes(raw[:n])
+	sig.S.FillBytes(raw[n:])
+	return raw, nil
+}
+
+// ECDSASignatureToDER converts a fixed width R || S signature used by
+// JWS to ASN.1 DER encoding.
+func ECDSASignatureToDER(raw []byte) ([]byte, error) {
+	if len(raw) == 0 || len(raw)%2 != 0 {
+		return nil, ErrInvalidSignature
+	}
+	n := len(raw) / 2
+	r := new(big.Int).SetBytes(raw[:n])
+	s := new(big.Int).SetBytes(raw[n:])
+	return asn1.Marshal(ecdsaSignature{r, s})
+}
+
 // getKeySize returns the size of the r/s key with padding.
 func (e ECDSASigner) getKeySize(curve elliptic.Curve) int {
 	n := curve.Params().BitSize / 8
@@ -268,3 +657,148 @@ func (e ECDSASigner) getKeySize(curve elliptic.Curve) int {
 	}
 	return n
 }
+
+// EdDSASigner is a signer for Ed25519 signatures.
+//
+// See RFC 8037.
+type EdDSASigner struct {
+	name string
+}
+
+// NewEdDSASigner returns a new EdDSASigner.
+func NewEdDSASigner(name string) EdDSASigner {
+	return EdDSASigner{name: name}
+}
+
+// Sign returns the signature of the data.
+// The key is expected to be a PEM-encoded PKCS #8 Ed25519 private key.
+func (e EdDSASigner) Sign(b, key []byte) ([]byte, error) {
+	priv, err := decodeEd25519PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(priv, b), nil
+}
+
+// SignKey returns the signature of the data.
+// The key may be an ed25519.PrivateKey, a crypto.Signer with an Ed25519
+// public key or a PEM-encoded private key.
+func (e EdDSASigner) SignKey(b []byte, key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		return e.Sign(b, k)
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, keyTypeError(key)
+		}
+		return ed25519.Sign(k, b), nil
+	case crypto.Signer:
+		if _, ok := k.Public().(ed25519.PublicKey); !ok {
+			return nil, keyTypeError(key)
+		}
+		return k.Sign(rand.Reader, b, crypto.Hash(0))
+	}
+	return nil, keyTypeError(key)
+}
+
+// decodeEd25519PrivateKey decodes a PEM-encoded Ed25519 private key.
+func decodeEd25519PrivateKey(b []byte) (ed25519.PrivateKey, error) {
+	block := decodeKeyBlock(b)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("jwt: invalid ed25519 private key")
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := priv.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("jwt: invalid ed25519 private key")
+	}
+	return key, nil
+}
+
+// Verify returns an error if the signature is invalid.
+// The key is expected to be a PEM-encoded Ed25519 public key.
+func (e EdDSASigner) Verify(b, sig, key []byte) error {
+	pub, err := decodeEd25519PublicKey(key)
+	if err != nil {
+		return err
+	}
+	return e.verify(b, sig, pub)
+}
+
+// VerifyKey returns an error if the signature is invalid.
+// The key may be an ed25519.PublicKey or a PEM-encoded public key.
+func (e EdDSASigner) VerifyKey(b, sig []byte, key interface{}) error {
+	switch k := key.(type) {
+	case []byte:
+		return e.Verify(b, sig, k)
+	case ed25519.PublicKey:
+		return e.verify(b, sig, k)
+	case ed25519.PrivateKey:
+		return e.verify(b, sig, k.Public().(ed25519.PublicKey))
+	case crypto.Signer:
+		if pub, ok := k.Public().(ed25519.PublicKey); ok {
+			return e.verify(b, sig, pub)
+		}
+	}
+	return keyTypeError(key)
+}
+
+func (e EdDSASigner) verify(b, sig []byte, pub ed25519.PublicKey) error {
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, b, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// decodeEd25519PublicKey decodes a PEM-encoded Ed25519 public key.
+func decodeEd25519PublicKey(b []byte) (ed25519.PublicKey, error) {
+	block := decodeKeyBlock(b)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("jwt: invalid ed25519 public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("jwt: invalid ed25519 public key")
+	}
+	return key, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (e EdDSASigner) String() string {
+	return e.name
+}
+
+// UnsecuredSigner is a signer for unsecured tokens with the "none"
+// algorithm. Unsecured tokens have an empty signature and provide no
+// integrity protection. The signer is not registered by default and
+// must be passed explicitly to New, Parse or NewVerifier, so the "none"
+// algorithm is never accepted because a token header names it.
+//
+// See RFC 7519 Section 6.
+type UnsecuredSigner struct{}
+
+// Sign returns an empty signature. The key is ignored.
+func (UnsecuredSigner) Sign(b, key []byte) ([]byte, error) {
+	return []byte{}, nil
+}
+
+// Verify returns an error if the signature is not empty.
+// The key is ignored.
+func (UnsecuredSigner) Verify(b, sig, key []byte) error {
+	if len(sig) != 0 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (UnsecuredSigner) String() string {
+	return "none"
+}
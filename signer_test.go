@@ -1,15 +1,22 @@
 package jwt
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
 	"testing"
 
 	_ "crypto/sha256"
+	_ "crypto/sha512"
 )
 
 func TestHMACSigner(t *testing.T) {
@@ -30,6 +37,56 @@ func TestHMACSigner(t *testing.T) {
 	}
 }
 
+func TestHMACSignerStrict(t *testing.T) {
+	b := []byte("foo")
+	s := NewHMACSignerStrict("HS256", crypto.SHA256)
+	var tests = []struct {
+		key []byte
+		err error
+	}{
+		{[]byte("secret"), ErrKeyTooShort},
+		{make([]byte, 31), ErrKeyTooShort},
+		{make([]byte, 32), nil},
+		{make([]byte, 64), nil},
+	}
+	for i, tt := range tests {
+		sig, err := s.Sign(b, tt.key)
+		if err != tt.err {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		sig, _ = HS256.Sign(b, tt.key)
+		err = s.Verify(b, sig, tt.key)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestUnsecuredSigner(t *testing.T) {
+	b := []byte("foo")
+	s := UnsecuredSigner{}
+	sig, err := s.Sign(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 0 {
+		t.Fatal("should return empty signature")
+	}
+	err = s.Verify(b, sig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Verify(b, []byte{0}, nil)
+	if err != ErrInvalidSignature {
+		t.Fatal("should be invalid")
+	}
+	_, ok := DefaultRegistry.Lookup("none")
+	if ok {
+		t.Fatal("should not register unsecured signer by default")
+	}
+}
+
 func TestRSASigner(t *testing.T) {
 	b := []byte("foo")
 	priv, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -55,6 +112,158 @@ func TestRSASigner(t *testing.T) {
 	}
 }
 
+func TestDecodeRSAPrivateKey(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := rsa.GenerateMultiPrimeKey(rand.Reader, 3, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ecKey, err := encodeECDSA(ec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := *priv
+	bad.D = new(big.Int).Add(priv.D, big.NewInt(2))
+	corrupt := x509.MarshalPKCS1PrivateKey(priv)
+	var tests = []struct {
+		key []byte
+		err error
+	}{
+		{encodeRSAPrivateKey(priv), nil},
+		{[]byte("secret"), ErrKeyNotPEM},
+		{ecKey, ErrKeyType},
+		{encodePrivateKey("RSA", corrupt[:len(corrupt)/2]), ErrKeyCorrupt},
+		{encodeRSAPrivateKey(multi), ErrKeyMultiPrime},
+		{encodeRSAPrivateKey(&bad), ErrKeyInconsistent},
+	}
+	for i, tt := range tests {
+		_, err := decodeRSAPrivateKey(tt.key)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. decodeRSAPrivateKey err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestPKCS8PrivateKey(t *testing.T) {
+	b := []byte("foo")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		signer Signer
+		priv   interface{}
+		pub    interface{}
+		err    error
+	}{
+		{RS256, rsaKey, &rsaKey.PublicKey, nil},
+		{PS384, rsaKey, &rsaKey.PublicKey, nil},
+		{ES384, ecKey, &ecKey.PublicKey, nil},
+		{RS256, ecKey, nil, ErrKeyType},
+	}
+	for i, tt := range tests {
+		der, err := x509.MarshalPKCS8PrivateKey(tt.priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privateKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		sig, err := tt.signer.Sign(b, privateKey)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		publicKey, err := encodePublicKey(tt.pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tt.signer.Verify(b, sig, publicKey)
+		if err != nil {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, nil)
+		}
+	}
+}
+
+func TestRSAPSSSigner(t *testing.T) {
+	b := []byte("foo")
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, privateKey, err := encodeRSA(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []Signer{PS256, PS384, PS512} {
+		sig, err := s.Sign(b, privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.Verify(b, sig, publicKey)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		err = RS256.Verify(b, sig, publicKey)
+		if err != ErrInvalidSignature {
+			t.Fatalf("%s: should not verify as PKCS #1 v1.5", s)
+		}
+		sig[0] ^= 0xFF
+		err = s.Verify(b, sig, publicKey)
+		if err != ErrInvalidSignature {
+			t.Fatalf("%s: should be invalid", s)
+		}
+	}
+}
+
+func TestRSAPSSSignerSaltLength(t *testing.T) {
+	b := []byte("foo")
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, privateKey, err := encodeRSA(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auto := NewRSAPSSSignerSaltLength("PS256", crypto.SHA256, rsa.PSSSaltLengthAuto)
+	fixed := NewRSAPSSSignerSaltLength("PS256", crypto.SHA256, 16)
+	var tests = []struct {
+		signer   Signer
+		verifier Signer
+		err      error
+	}{
+		{PS256, auto, nil},
+		{fixed, auto, nil},
+		{auto, auto, nil},
+		{auto, PS256, ErrInvalidSignature},
+		{fixed, PS256, ErrInvalidSignature},
+		{fixed, fixed, nil},
+	}
+	for i, tt := range tests {
+		sig, err := tt.signer.Sign(b, privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tt.verifier.Verify(b, sig, publicKey)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
 func TestECDSASigner(t *testing.T) {
 	b := []byte("foo")
 	curve := elliptic.P256()
@@ -81,6 +290,140 @@ func TestECDSASigner(t *testing.T) {
 	}
 }
 
+func TestECDSASignerDER(t *testing.T) {
+	b := []byte("foo")
+	priv, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, privateKey, err := encodeECDSA(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewECDSASignerDER("ES512", crypto.SHA512)
+	raw, err := s.Sign(b, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 2*66 {
+		t.Fatalf("Sign should produce a jose signature, have %d bytes", len(raw))
+	}
+	err = ES512.Verify(b, raw, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Verify(b, raw, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := ECDSASignatureToDER(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Verify(b, der, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ES512.Verify(b, der, publicKey)
+	if err != ErrInvalidSignature {
+		t.Fatal("should reject der signature in jose format")
+	}
+	have, err := ECDSASignatureToRaw(der, elliptic.P521())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, raw) {
+		t.Fatalf("ECDSASignatureToRaw\nhave %x\nwant %x", have, raw)
+	}
+	jwt, err := New(s).SignKey(opaqueSigner{priv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(ES512, jwt, publicKey)
+	if err != nil {
+		t.Fatalf("standard verifier should accept the token, have %v", err)
+	}
+	der[len(der)-1] ^= 0xFF
+	err = s.Verify(b, der, publicKey)
+	if err != ErrInvalidSignature {
+		t.Fatal("should be invalid")
+	}
+}
+
+// TestECDSASignerDeterministic signs the example from RFC 6979
+// Appendix A.2.5 with P-256 and SHA-256.
+func TestECDSASignerDeterministic(t *testing.T) {
+	d, _ := new(big.Int).SetString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", 16)
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.Curve = elliptic.P256()
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(d.Bytes())
+	publicKey, privateKey, err := encodeECDSA(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewECDSASignerDeterministic("ES256", crypto.SHA256)
+	sig, err := s.Sign([]byte("sample"), privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716" +
+		"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8"
+	if have := fmt.Sprintf("%X", sig); have != want {
+		t.Fatalf("Sign\nhave %s\nwant %s", have, want)
+	}
+	err = ES256.Verify([]byte("sample"), sig, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEdDSASigner(t *testing.T) {
+	b := []byte("foo")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := encodePublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	sig, err := EdDSA.Sign(b, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = EdDSA.Verify(b, sig, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[0] ^= 0xFF
+	err = EdDSA.Verify(b, sig, publicKey)
+	if err != ErrInvalidSignature {
+		t.Fatal("should be invalid")
+	}
+}
+
+// TestEdDSAVector verifies the example from RFC 8037 Appendix A.4.
+func TestEdDSAVector(t *testing.T) {
+	k := JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	publicKey, err := k.Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := decode("hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = EdDSA.Verify([]byte("eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"), sig, publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // encodeRSA encodes a RSA private key to PEM-formatted
 // public and private keys.
 func encodeRSA(priv *rsa.PrivateKey) ([]byte, []byte, error) {
@@ -106,16 +449,6 @@ func encodeECDSA(priv *ecdsa.PrivateKey) ([]byte, []byte, error) {
 	return publicKey, privateKey, nil
 }
 
-// encodePublicKey encodes a RSA or ECDSA public key to PEM format.
-func encodePublicKey(pub interface{}) ([]byte, error) {
-	der, err := x509.MarshalPKIXPublicKey(pub)
-	if err != nil {
-		return nil, err
-	}
-	block := &pem.Block{Type: "PUBLIC KEY", Bytes: der}
-	return pem.EncodeToMemory(block), nil
-}
-
 // encodeRSAPrivateKey encodes a RSA private key to PEM format.
 func encodeRSAPrivateKey(priv *rsa.PrivateKey) []byte {
 	der := x509.MarshalPKCS1PrivateKey(priv)
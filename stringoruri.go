@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrClaimURI is returned when an iss, sub or aud claim containing a
+// colon is not a valid URI.
+var ErrClaimURI = errors.New("jwt: registered claim is not a valid StringOrURI")
+
+// uriClaims is the set of registered claims holding a StringOrURI.
+var uriClaims = []string{ClaimIssuer, ClaimSubject, ClaimAudience}
+
+// WithStringOrURI returns an option that requires iss, sub and aud
+// values containing a colon to be valid URIs, as the StringOrURI rule
+// of RFC 7519 Section 2 requires. See ValidStringOrURI.
+func WithStringOrURI() Option {
+	return func(v *Verifier) {
+		v.stringOrURI = true
+	}
+}
+
+// ValidStringOrURI returns true if s is a valid StringOrURI. Any string
+// without a colon is valid and any string with a colon must be an
+// absolute URI as defined by RFC 3986.
+func ValidStringOrURI(s string) bool {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return true
+	}
+	if !validScheme(s[:i]) {
+		return false
+	}
+	for j := 0; j < len(s); j++ {
+		c := s[j]
+		if c == '%' {
+			if j+2 >= len(s) || !isHex(s[j+1]) || !isHex(s[j+2]) {
+				return false
+			}
+			j += 2
+			continue
+		}
+		if !isURIChar(c) {
+			return false
+		}
+	}
+	_, err := url.Parse(s)
+	return err == nil
+}
+
+// validScheme returns true if s is a URI scheme.
+func validScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isURIChar returns true if c is an unreserved or reserved URI character.
+func isURIChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~:/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+// isHex returns true if c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func (v *Verifier) checkStringOrURI(ctx context.Context, s *verification) error {
+	if !v.stringOrURI {
+		return skipped("StringOrURI validation is not enabled")
+	}
+	for _, name := range uriClaims {
+		c, ok := s.token.Claims[name]
+		if !ok {
+			continue
+		}
+		var values []string
+		if name == ClaimAudience {
+			values = audience(c)
+		} else if str, ok := c.(string); ok {
+			values = []string{str}
+		}
+		for _, value := range values {
+			if !ValidStringOrURI(value) {
+				return ErrClaimURI
+			}
+		}
+	}
+	return nil
+}
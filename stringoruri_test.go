@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidStringOrURI(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want bool
+	}{
+		{"", true},
+		{"api", true},
+		{"user 1", true},
+		{"https://issuer.example", true},
+		{"https://issuer.example/tenants/a?x=1#f", true},
+		{"urn:example:api", true},
+		{"spiffe://example.org/ns/a", true},
+		{"https://issuer.example/%7Euser", true},
+		{"https//issuer.example:443", false},
+		{":api", false},
+		{"1https://issuer.example", false},
+		{"https://issuer example", false},
+		{"urn:example:a b", false},
+		{"https://issuer.example/%zz", false},
+		{"https://issuer.example:port", false},
+	}
+	for i, tt := range tests {
+		have := ValidStringOrURI(tt.s)
+		if have != tt.want {
+			t.Errorf("%d. ValidStringOrURI(%q)\nhave %v\nwant %v", i, tt.s, have, tt.want)
+		}
+	}
+}
+
+func TestWithStringOrURI(t *testing.T) {
+	key := []byte("secret")
+	var tests = []struct {
+		claims map[string]interface{}
+		err    error
+	}{
+		{map[string]interface{}{"iss": "https://issuer.example", "sub": "a", "aud": "api"}, nil},
+		{map[string]interface{}{"iss": "https://issuer.example", "aud": []string{"api", "urn:example:api"}}, nil},
+		{map[string]interface{}{"iss": "https//issuer.example:443"}, ErrClaimURI},
+		{map[string]interface{}{"sub": "user:a b"}, ErrClaimURI},
+		{map[string]interface{}{"aud": []string{"api", "https://api example"}}, ErrClaimURI},
+	}
+	v := NewVerifier([]Signer{HS256}, StaticKey(key), WithStringOrURI())
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims = tt.claims
+		jwt, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = v.Verify(context.Background(), jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"math"
+	"time"
+)
+
+// WithSubsecondPrecision returns an option that compares the exp and
+// nbf claims with the current time at nanosecond rather than second
+// precision, honoring fractional NumericDate values as RFC 7519 allows.
+// This permits tokens living less than a second, such as for hop-by-hop
+// calls within a service mesh. Issuers set fractional dates with
+// SubsecondDate. By default fractional seconds are truncated.
+func WithSubsecondPrecision() Option {
+	return func(v *Verifier) {
+		v.subsecond = true
+	}
+}
+
+// SubsecondDate returns t as seconds since the Unix epoch with
+// fractional seconds, for use as a date claim verified with
+// WithSubsecondPrecision. The float64 encoding preserves microsecond
+// precision for present day dates.
+func SubsecondDate(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
+
+// expired returns true if the current time is after the exp date.
+func (v *Verifier) expired(exp time.Time) bool {
+	if v.subsecond {
+		return v.time().After(exp.Add(v.leeway))
+	}
+	return v.seconds().After(exp.Add(v.leeway.Truncate(time.Second)))
+}
+
+// notYetValid returns true if the current time is before the nbf date.
+func (v *Verifier) notYetValid(nbf time.Time) bool {
+	if v.subsecond {
+		return v.time().Before(nbf.Add(-v.leeway))
+	}
+	return v.seconds().Before(nbf.Add(-v.leeway.Truncate(time.Second)))
+}
+
+// seconds returns the current time truncated to whole seconds, for
+// comparison with dates truncated to whole seconds.
+func (v *Verifier) seconds() time.Time {
+	return time.Unix(v.time().Unix(), 0)
+}
+
+// unixTime returns the time of the date in seconds since the epoch.
+func unixTime(f float64) time.Time {
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
@@ -0,0 +1,90 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithSubsecondPrecision(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1700000000, 500e6)
+	var tests = []struct {
+		claims    map[string]interface{}
+		subsecond bool
+		err       error
+	}{
+		{map[string]interface{}{"exp": SubsecondDate(now.Add(250 * time.Millisecond))}, true, nil},
+		{map[string]interface{}{"exp": SubsecondDate(now.Add(-250 * time.Millisecond))}, true, ErrClaimExpired},
+		{map[string]interface{}{"exp": SubsecondDate(now.Add(-250 * time.Millisecond))}, false, nil},
+		{map[string]interface{}{"nbf": SubsecondDate(now.Add(250 * time.Millisecond))}, true, ErrClaimNotBefore},
+		{map[string]interface{}{"nbf": SubsecondDate(now.Add(250 * time.Millisecond))}, false, nil},
+		{map[string]interface{}{"nbf": SubsecondDate(now.Add(-250 * time.Millisecond))}, true, nil},
+		{map[string]interface{}{"exp": now.Unix() - 1}, false, ErrClaimExpired},
+		{map[string]interface{}{"exp": now.Unix()}, false, nil},
+		{map[string]interface{}{"exp": float64(now.Unix()) - 0.1}, false, ErrClaimExpired},
+		{map[string]interface{}{"nbf": float64(now.Unix()) + 0.9}, false, nil},
+		{map[string]interface{}{"nbf": now.Unix() + 1}, false, ErrClaimNotBefore},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims = tt.claims
+		jwt, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := []Option{WithEvaluationTime(now)}
+		if tt.subsecond {
+			opts = append(opts, WithSubsecondPrecision())
+		}
+		_, err = NewVerifier([]Signer{HS256}, StaticKey(key), opts...).Verify(context.Background(), jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestDateRangeBoundary(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	end := time.Unix(maxTimestamp, 0)
+	var tests = []struct {
+		now time.Time
+		err error
+	}{
+		{end, nil},
+		{end.Add(999 * time.Millisecond), nil},
+		{end.Add(time.Second), ErrClaimExpired},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, `{"exp":253402300799}`), []byte("secret"), WithEvaluationTime(tt.now))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestDateLiterals(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	now := time.Unix(1700000000, 501e6)
+	var tests = []struct {
+		payload string
+		opts    []Option
+		err     error
+	}{
+		{`{"exp":1700000000501}`, []Option{WithMilliseconds(), WithSubsecondPrecision()}, nil},
+		{`{"exp":1700000000500}`, []Option{WithMilliseconds(), WithSubsecondPrecision()}, ErrClaimExpired},
+		{`{"exp":1700000000.0}`, nil, nil},
+		{`{"exp":17000000001e-1}`, nil, nil},
+		{`{"exp":1699999999,"EXP":1700000001}`, nil, ErrClaimExpired},
+		{`{"EXP":1699999999,"exp":1700000001}`, nil, nil},
+		{`{"exp":9223372036854775807}`, nil, ErrClaimRange},
+		{`{"exp":-9223372036854775809}`, nil, ErrClaimRange},
+	}
+	for i, tt := range tests {
+		opts := append([]Option{WithEvaluationTime(now)}, tt.opts...)
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
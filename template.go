@@ -0,0 +1,115 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTemplateValues is returned when the number of values passed to a
+// Template does not match the number of variable claims.
+var ErrTemplateValues = errors.New("jwt: template values do not match variables")
+
+// Template is a token precompiled to bytes with a small number of
+// variable claims, such as sub and exp. Signing a token from a template
+// only patches the variable claims into the precompiled claims rather
+// than marshaling the header and claims, which is useful for issuing
+// many small tokens on a hot path.
+type Template struct {
+	signer Signer
+	header string
+	parts  [][]byte
+}
+
+// NewTemplate returns a new Template with the header and claims of t
+// and the named variable claims. Variable claims present in t are
+// replaced by the values passed to Sign.
+func NewTemplate(t *Token, vars ...string) (*Template, error) {
+	static := &Token{Header: t.Header, Claims: make(map[string]interface{}, len(t.Claims)), signer: t.signer}
+	for k, v := range t.Claims {
+		if !contains(vars, k) {
+			static.Claims[k] = v
+		}
+	}
+	input, err := static.SigningInput()
+	if err != nil {
+		return nil, err
+	}
+	c, err := json.Marshal(static.Claims)
+	if err != nil {
+		return nil, err
+	}
+	tp := &Template{signer: t.signer, header: input[:strings.Index(input, sep)+len(sep)]}
+	if len(vars) == 0 {
+		tp.parts = [][]byte{c}
+		return tp, nil
+	}
+	part := append([]byte{'{'}, c[1:len(c)-1]...)
+	if len(part) > 1 {
+		part = append(part, ',')
+	}
+	for _, name := range vars {
+		k, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		tp.parts = append(tp.parts, append(append(part, k...), ':'))
+		part = []byte{','}
+	}
+	tp.parts = append(tp.parts, []byte{'}'})
+	return tp, nil
+}
+
+// Sign returns a signed token with the variable claims set to values,
+// in the order the variables were named. Strings, integers and times,
+// encoded as NumericDate, are appended without reflection and other
+// values are marshaled to JSON.
+func (tp *Template) Sign(key []byte, values ...interface{}) (string, error) {
+	if len(values) != len(tp.parts)-1 {
+		return "", ErrTemplateValues
+	}
+	b := make([]byte, 0, 128)
+	var err error
+	for i, v := range values {
+		b = append(b, tp.parts[i]...)
+		b, err = appendValue(b, v)
+		if err != nil {
+			return "", err
+		}
+	}
+	b = append(b, tp.parts[len(tp.parts)-1]...)
+	jwt := tp.header + encode(b)
+	sig, err := tp.signer.Sign([]byte(jwt), key)
+	if err != nil {
+		return "", err
+	}
+	return jwt + sep + encode(sig), nil
+}
+
+// appendValue appends the JSON encoding of v to b.
+func appendValue(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		for i := 0; i < len(v); i++ {
+			if c := v[i]; c < 0x20 || c == '"' || c == '\\' || c >= 0x7f || c == '<' || c == '>' || c == '&' {
+				s, err := json.Marshal(v)
+				return append(b, s...), err
+			}
+		}
+		b = append(b, '"')
+		b = append(b, v...)
+		return append(b, '"'), nil
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case NumericDate:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case time.Time:
+		return strconv.AppendInt(b, v.Unix(), 10), nil
+	}
+	s, err := json.Marshal(v)
+	return append(b, s...), err
+}
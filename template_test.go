@@ -0,0 +1,67 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	key := []byte("secret")
+	exp := time.Now().Add(time.Hour)
+	var tests = []struct {
+		claims map[string]interface{}
+		vars   []string
+		values []interface{}
+		want   map[string]interface{}
+	}{
+		{
+			map[string]interface{}{"iss": "issuer", "sub": "ignored"},
+			[]string{"sub", "exp"},
+			[]interface{}{"user", exp},
+			map[string]interface{}{"iss": "issuer", "sub": "user", "exp": float64(exp.Unix())},
+		},
+		{
+			map[string]interface{}{},
+			[]string{"sub", "n", "scope"},
+			[]interface{}{"quote\"<d>", 42, []string{"a"}},
+			map[string]interface{}{"sub": "quote\"<d>", "n": float64(42), "scope": []interface{}{"a"}},
+		},
+		{
+			map[string]interface{}{"iss": "issuer"},
+			nil,
+			nil,
+			map[string]interface{}{"iss": "issuer"},
+		},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims = tt.claims
+		tp, err := NewTemplate(token, tt.vars...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jwt, err := tp.Sign(key, tt.values...)
+		if err != nil {
+			t.Errorf("%d. Sign err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		parsed, err := Parse(HS256, jwt, key)
+		if err != nil {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		have, _ := Claims(parsed.Claims).Hash()
+		want, _ := Claims(tt.want).Hash()
+		if have != want {
+			t.Errorf("%d. Sign claims\nhave %v\nwant %v", i, parsed.Claims, tt.want)
+		}
+	}
+	tp, err := NewTemplate(New(HS256), "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tp.Sign(key)
+	if err != ErrTemplateValues {
+		t.Errorf("Sign err\nhave %v\nwant %v", err, ErrTemplateValues)
+	}
+}
@@ -0,0 +1,64 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClaimTenant is returned when the tenant claim of a token does not
+// match the expected tenant of the request.
+var ErrClaimTenant = errors.New("jwt: tenant claim does not match the expected tenant")
+
+// tenantKey is the context key of the expected tenant.
+type tenantKey struct{}
+
+// ContextWithTenant returns a copy of ctx carrying the expected tenant
+// of the request, such as the organization in the request path.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the expected tenant stored in ctx by
+// ContextWithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// WithTenant returns an option that requires the claim, such as
+// "org_id" or "tid", to equal the expected tenant of the request
+// returned by source, preventing tokens issued for one tenant from
+// being used with another. The default source is TenantFromContext.
+// Tokens are rejected with ErrClaimTenant if the claim is missing or
+// differs, or if the request has no expected tenant.
+func WithTenant(claim string, source func(ctx context.Context) (string, bool)) Option {
+	if source == nil {
+		source = TenantFromContext
+	}
+	return func(v *Verifier) {
+		v.tenantClaim = claim
+		v.tenant = source
+	}
+}
+
+func (v *Verifier) checkTenant(ctx context.Context, s *verification) error {
+	if v.tenant == nil {
+		return skipped("no tenant claim is configured")
+	}
+	want, ok := v.tenant(ctx)
+	if !ok || want == "" {
+		return ErrClaimTenant
+	}
+	c, ok := s.token.Claims[v.tenantClaim]
+	if !ok {
+		return ErrClaimTenant
+	}
+	have, ok := c.(string)
+	if !ok {
+		return ErrClaimType
+	}
+	if have != want {
+		return ErrClaimTenant
+	}
+	return nil
+}
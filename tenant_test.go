@@ -0,0 +1,34 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithTenant(t *testing.T) {
+	var tests = []struct {
+		claims map[string]interface{}
+		ctx    context.Context
+		err    error
+	}{
+		{map[string]interface{}{"org_id": "a"}, ContextWithTenant(context.Background(), "a"), nil},
+		{map[string]interface{}{"org_id": "a"}, ContextWithTenant(context.Background(), "b"), ErrClaimTenant},
+		{map[string]interface{}{"org_id": "a"}, context.Background(), ErrClaimTenant},
+		{map[string]interface{}{"org_id": "a"}, ContextWithTenant(context.Background(), ""), ErrClaimTenant},
+		{map[string]interface{}{}, ContextWithTenant(context.Background(), "a"), ErrClaimTenant},
+		{map[string]interface{}{"org_id": 1}, ContextWithTenant(context.Background(), "1"), ErrClaimType},
+	}
+	v := NewVerifier([]Signer{HS256}, StaticKey([]byte("secret")), WithTenant("org_id", nil))
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims = tt.claims
+		jwt, err := token.Sign([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = v.Verify(tt.ctx, jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+)
+
+// ErrClaimConfirmation is returned when the key does not match the
+// confirmation claim of a token.
+var ErrClaimConfirmation = errors.New("jwt: key does not match cnf claim")
+
+// Thumbprint returns the base64url-encoded SHA-256 JWK thumbprint of
+// the key. Only the required public key parameters are hashed, so a
+// private key and its public key have the same thumbprint.
+//
+// See RFC 7638.
+func (k JWK) Thumbprint() (string, error) {
+	_, err := k.NativeKey()
+	if err != nil {
+		return "", err
+	}
+	var m map[string]string
+	switch k.Kty {
+	case "RSA":
+		m = map[string]string{"e": k.E, "kty": k.Kty, "n": k.N}
+	case "EC":
+		m = map[string]string{"crv": k.Crv, "kty": k.Kty, "x": k.X, "y": k.Y}
+	case "OKP":
+		m = map[string]string{"crv": k.Crv, "kty": k.Kty, "x": k.X}
+	case "oct":
+		m = map[string]string{"k": k.K, "kty": k.Kty}
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return encode(sum[:]), nil
+}
+
+// Thumbprint returns the base64url-encoded SHA-256 JWK thumbprint of
+// the native key, suitable as a kid header value.
+//
+// See NewJWK and RFC 7638.
+func Thumbprint(key interface{}) (string, error) {
+	k, err := NewJWK(key)
+	if err != nil {
+		return "", err
+	}
+	return k.Thumbprint()
+}
+
+// VerifyConfirmation returns ErrClaimConfirmation unless the jkt member
+// of the cnf claim of the token is the thumbprint of key, binding the
+// token to the key presented by the client.
+//
+// See RFC 7800 and RFC 9449 Section 6.
+func VerifyConfirmation(t *Token, key interface{}) error {
+	cnf, _ := t.Claims[ClaimConfirmation].(map[string]interface{})
+	jkt, _ := cnf["jkt"].(string)
+	if jkt == "" {
+		return ErrClaimConfirmation
+	}
+	want, err := Thumbprint(key)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(jkt), []byte(want)) != 1 {
+		return ErrClaimConfirmation
+	}
+	return nil
+}
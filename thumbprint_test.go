@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestThumbprint(t *testing.T) {
+	// RFC 7638 Section 3.1.
+	k := JWK{
+		Kty: "RSA",
+		Kid: "2011-04-29",
+		Alg: "RS256",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	have, err := k.Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+	if have != want {
+		t.Errorf("Thumbprint\nhave %s\nwant %s", have, want)
+	}
+}
+
+func TestVerifyConfirmation(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jkt, err := Thumbprint(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := Thumbprint(&priv.PublicKey)
+	if err != nil || pub != jkt {
+		t.Fatalf("private and public thumbprints should match")
+	}
+	var tests = []struct {
+		claims map[string]interface{}
+		key    interface{}
+		err    error
+	}{
+		{map[string]interface{}{"cnf": map[string]interface{}{"jkt": jkt}}, &priv.PublicKey, nil},
+		{map[string]interface{}{"cnf": map[string]interface{}{"jkt": jkt}}, &other.PublicKey, ErrClaimConfirmation},
+		{map[string]interface{}{"cnf": map[string]interface{}{}}, &priv.PublicKey, ErrClaimConfirmation},
+		{map[string]interface{}{}, &priv.PublicKey, ErrClaimConfirmation},
+	}
+	for i, tt := range tests {
+		err := VerifyConfirmation(&Token{Claims: tt.claims}, tt.key)
+		if err != tt.err {
+			t.Errorf("%d. VerifyConfirmation err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
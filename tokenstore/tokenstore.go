@@ -0,0 +1,194 @@
+// Package tokenstore implements encrypted storage of tokens on disk
+// for command line applications.
+package tokenstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Store errors.
+var (
+	ErrNotFound  = errors.New("tokenstore: not found")
+	ErrDecrypt   = errors.New("tokenstore: unable to decrypt token")
+	ErrMalformed = errors.New("tokenstore: malformed token file")
+)
+
+// iterations is the PBKDF2 iteration count for passphrase-derived keys.
+const iterations = 600000
+
+// KeySource provides the key used to encrypt and decrypt tokens.
+type KeySource interface {
+	// Key returns a 32 byte key for the salt.
+	Key(salt []byte) ([]byte, error)
+}
+
+type passphrase string
+
+// Passphrase returns a KeySource that derives keys from p with PBKDF2.
+func Passphrase(p string) KeySource {
+	return passphrase(p)
+}
+
+// Key implements the KeySource interface.
+func (p passphrase) Key(salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, string(p), salt, iterations, 32)
+}
+
+// Keyring is the interface to an operating system keyring.
+// Get must return ErrNotFound if the secret does not exist.
+type Keyring interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+}
+
+type keyring struct {
+	kr      Keyring
+	service string
+	user    string
+}
+
+// KeyringKey returns a KeySource that stores a random key in the
+// keyring kr, creating it on first use.
+func KeyringKey(kr Keyring, service, user string) KeySource {
+	return keyring{kr: kr, service: service, user: user}
+}
+
+// Key implements the KeySource interface.
+// The salt is unused as the key is random.
+func (k keyring) Key(salt []byte) ([]byte, error) {
+	s, err := k.kr.Get(k.service, k.user)
+	if err == ErrNotFound {
+		key := make([]byte, 32)
+		_, err = rand.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		err = k.kr.Set(k.service, k.user, base64.StdEncoding.EncodeToString(key))
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != 32 {
+		return nil, ErrDecrypt
+	}
+	return key, nil
+}
+
+// file is the on-disk representation of an encrypted token.
+type file struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Save encrypts token with a key from ks and writes it to path
+// with permissions restricted to the current user.
+func Save(path, token string, ks KeySource) error {
+	f := file{Salt: make([]byte, 16)}
+	_, err := rand.Read(f.Salt)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(ks, f.Salt)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(f.Nonce)
+	if err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, []byte(token), nil)
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, b)
+}
+
+// writeFile atomically replaces the file at path with b. The data is
+// written to a uniquely named file in the same directory, created with
+// mode 0600, which is then renamed over path.
+func writeFile(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Load reads the token at path and decrypts it with a key from ks.
+// ErrNotFound is returned if no token has been saved.
+func Load(path string, ks KeySource) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	var f file
+	err = json.Unmarshal(b, &f)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	aead, err := newAEAD(ks, f.Salt)
+	if err != nil {
+		return "", err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	token, err := aead.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(token), nil
+}
+
+// newAEAD returns an AES-256-GCM cipher keyed from ks.
+func newAEAD(ks KeySource, salt []byte) (cipher.AEAD, error) {
+	key, err := ks.Key(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
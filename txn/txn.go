@@ -0,0 +1,162 @@
+// Package txn implements transaction tokens, which propagate the
+// identity and authorization context of an external request along an
+// internal call chain.
+//
+// See draft-ietf-oauth-transaction-tokens.
+package txn
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+// Type is the typ header value of a transaction token.
+const Type = "txntoken+jwt"
+
+// MaxTTL is the maximum lifetime of a transaction token.
+const MaxTTL = 5 * time.Minute
+
+// Transaction token errors.
+var (
+	ErrTTL         = errors.New("txn: lifetime must be positive and at most MaxTTL")
+	ErrTransaction = errors.New("txn: txn claim is required")
+	ErrPurpose     = errors.New("txn: purp claim is required")
+	ErrSubject     = errors.New("txn: sub claim is required")
+	ErrAudience    = errors.New("txn: aud claim is required")
+	ErrContext     = errors.New("txn: rctx claim values must be strings")
+	ErrNotTxnToken = errors.New("txn: token is not a transaction token")
+	ErrLifetime    = errors.New("txn: exp must be after iat and within MaxTTL")
+)
+
+// Claims is the claim set of a transaction token.
+type Claims struct {
+	// Transaction is the unique identifier of the call chain.
+	Transaction string `json:"txn"`
+
+	// Subject is the principal on whose behalf the call chain executes.
+	Subject string `json:"sub"`
+
+	// Audience is the trust domain in which the token is valid.
+	Audience jwt.Audience `json:"aud"`
+
+	// Purpose is the intended purpose of the call chain.
+	Purpose string `json:"purp"`
+
+	// AuthorizationDetails is the authorization context of the call chain.
+	AuthorizationDetails map[string]interface{} `json:"azd,omitempty"`
+
+	// RequestContext describes the external request, such as the
+	// req_ip, authn and req_wl parameters.
+	RequestContext map[string]interface{} `json:"rctx,omitempty"`
+
+	IssuedAt  jwt.NumericDate `json:"iat"`
+	ExpiresAt jwt.NumericDate `json:"exp"`
+}
+
+// Validate checks the structure of the claims.
+func (c *Claims) Validate() error {
+	if c.Transaction == "" {
+		return ErrTransaction
+	}
+	if c.Subject == "" {
+		return ErrSubject
+	}
+	if len(c.Audience) == 0 {
+		return ErrAudience
+	}
+	if c.Purpose == "" {
+		return ErrPurpose
+	}
+	for _, v := range c.RequestContext {
+		if _, ok := v.(string); !ok {
+			return ErrContext
+		}
+	}
+	ttl := c.ExpiresAt.Time().Sub(c.IssuedAt.Time())
+	if ttl <= 0 || ttl > MaxTTL {
+		return ErrLifetime
+	}
+	return nil
+}
+
+// Issue signs a transaction token with the claims c valid for ttl.
+// The iat and exp claims are set from the current time and a random
+// txn claim is generated if none is set.
+func Issue(s jwt.Signer, key []byte, c Claims, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > MaxTTL {
+		return "", ErrTTL
+	}
+	if c.Transaction == "" {
+		b := make([]byte, 16)
+		_, err := rand.Read(b)
+		if err != nil {
+			return "", err
+		}
+		c.Transaction = base64.RawURLEncoding.EncodeToString(b)
+	}
+	now := time.Now()
+	c.IssuedAt = jwt.NewNumericDate(now)
+	c.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	err := c.Validate()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	t := jwt.New(s)
+	t.Header[jwt.HeaderType] = Type
+	err = json.Unmarshal(b, &t.Claims)
+	if err != nil {
+		return "", err
+	}
+	return t.Sign(key)
+}
+
+// NewVerifier returns a jwt.Verifier that accepts only transaction
+// tokens. Use FromToken to access the claims of a verified token.
+func NewVerifier(s []jwt.Signer, keys jwt.KeyProvider, opts ...jwt.Option) *jwt.Verifier {
+	policy := []jwt.Option{
+		jwt.WithAcceptedTypes(Type),
+		jwt.WithRequired("txn", "sub", "aud", "purp", "iat", "exp"),
+	}
+	return jwt.NewVerifier(s, keys, append(policy, opts...)...)
+}
+
+// Verify verifies raw with v and returns the transaction token claims.
+func Verify(ctx context.Context, v jwt.TokenVerifier, raw string) (*Claims, error) {
+	t, err := v.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	return FromToken(t)
+}
+
+// FromToken returns the validated claims of a verified transaction token.
+func FromToken(t *jwt.Token) (*Claims, error) {
+	typ, _ := t.Header[jwt.HeaderType].(string)
+	if !jwt.TypeEqual(typ, Type) {
+		return nil, ErrNotTxnToken
+	}
+	b, err := json.Marshal(t.Claims)
+	if err != nil {
+		return nil, err
+	}
+	var c Claims
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,94 @@
+package txn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnelson/jwt"
+)
+
+func TestIssue(t *testing.T) {
+	key := []byte("secret")
+	c := Claims{
+		Subject:        "user",
+		Audience:       jwt.Audience{"example.com"},
+		Purpose:        "checkout",
+		RequestContext: map[string]interface{}{"req_ip": "198.51.100.1", "authn": "mfa"},
+	}
+	raw, err := Issue(jwt.HS256, key, c, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	have, err := Verify(context.Background(), v, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have.Transaction == "" || have.Subject != "user" || have.Purpose != "checkout" {
+		t.Errorf("unexpected claims %+v", have)
+	}
+	if have.ExpiresAt-have.IssuedAt != 60 {
+		t.Errorf("lifetime\nhave %d\nwant %d", have.ExpiresAt-have.IssuedAt, 60)
+	}
+	token := jwt.New(jwt.HS256)
+	token.Claims["sub"] = "user"
+	plain, err := token.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Verify(context.Background(), v, plain)
+	if err != jwt.ErrHeaderTyp {
+		t.Errorf("should reject tokens that are not transaction tokens")
+	}
+}
+
+func TestIssueInvalid(t *testing.T) {
+	valid := Claims{Subject: "user", Audience: jwt.Audience{"example.com"}, Purpose: "checkout"}
+	var tests = []struct {
+		mutate func(c *Claims)
+		ttl    time.Duration
+		err    error
+	}{
+		{func(c *Claims) {}, time.Hour, ErrTTL},
+		{func(c *Claims) {}, 0, ErrTTL},
+		{func(c *Claims) { c.Subject = "" }, time.Minute, ErrSubject},
+		{func(c *Claims) { c.Audience = nil }, time.Minute, ErrAudience},
+		{func(c *Claims) { c.Purpose = "" }, time.Minute, ErrPurpose},
+		{func(c *Claims) { c.RequestContext = map[string]interface{}{"req_ip": 1} }, time.Minute, ErrContext},
+	}
+	for i, tt := range tests {
+		c := valid
+		tt.mutate(&c)
+		_, err := Issue(jwt.HS256, []byte("secret"), c, tt.ttl)
+		if err != tt.err {
+			t.Errorf("%d. Issue err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestVerifyMediaType(t *testing.T) {
+	key := []byte("secret")
+	v := NewVerifier([]jwt.Signer{jwt.HS256}, jwt.StaticKey(key))
+	now := time.Now()
+	for i, typ := range []string{Type, "application/" + Type, "TxnToken+JWT"} {
+		token := jwt.New(jwt.HS256)
+		token.Header[jwt.HeaderType] = typ
+		token.Claims = map[string]interface{}{
+			"txn":  "t",
+			"sub":  "user",
+			"aud":  "example.com",
+			"purp": "checkout",
+			"iat":  now.Unix(),
+			"exp":  now.Add(time.Minute).Unix(),
+		}
+		raw, err := token.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Verify(context.Background(), v, raw)
+		if err != nil {
+			t.Errorf("%d. Verify %q err\nhave %v\nwant %v", i, typ, err, nil)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrInvalidNumericDate is returned when a NumericDate can not be decoded.
+var ErrInvalidNumericDate = errors.New("jwt: invalid numeric date")
+
+// NumericDate is the number of seconds since the Unix epoch.
+//
+// See RFC 7519 Section 2.
+type NumericDate int64
+
+// NewNumericDate returns the NumericDate for t.
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate(t.Unix())
+}
+
+// Time returns the time represented by the date.
+func (d NumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Fractional seconds are truncated.
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	err := json.Unmarshal(b, &n)
+	if err != nil || len(b) == 0 || b[0] == '"' {
+		return ErrInvalidNumericDate
+	}
+	v, ok := numberDate(n)
+	if !ok {
+		return ErrInvalidNumericDate
+	}
+	*d = v
+	return nil
+}
+
+// numberDate returns n as a NumericDate. Integers are converted exactly
+// rather than through a float64, which is exact only up to 2^53.
+func numberDate(n json.Number) (NumericDate, bool) {
+	i, err := n.Int64()
+	if err == nil {
+		return NumericDate(i), true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return floatDate(f)
+}
+
+// floatDate returns f as a NumericDate, truncating fractional seconds.
+// It returns false if f is not a number or is out of the int64 range.
+func floatDate(f float64) (NumericDate, bool) {
+	if math.IsNaN(f) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, false
+	}
+	return NumericDate(f), true
+}
+
+// Audience is the aud claim, which may be either a single
+// string or an array of strings.
+//
+// See RFC 7519 Section 4.1.3.
+type Audience []string
+
+// Contains returns true if aud is one of the audiences.
+func (a Audience) Contains(aud string) bool {
+	return contains(a, aud)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// A single audience is encoded as a string.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var v []string
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNumericDate(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out NumericDate
+		err error
+	}{
+		{"1700000000", 1700000000, nil},
+		{"1.7e9", 1700000000, nil},
+		{"1700000000.9", 1700000000, nil},
+		{`"1700000000"`, 0, ErrInvalidNumericDate},
+		{"9999999999999999999", 0, ErrInvalidNumericDate},
+		{"9007199254740993", 9007199254740993, nil},
+		{"-1", -1, nil},
+	}
+	for i, tt := range tests {
+		var d NumericDate
+		err := json.Unmarshal([]byte(tt.in), &d)
+		if err != tt.err {
+			t.Errorf("%d. Unmarshal err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if d != tt.out {
+			t.Errorf("%d. Unmarshal\nhave %v\nwant %v", i, d, tt.out)
+		}
+	}
+}
+
+func TestAudience(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out Audience
+	}{
+		{`"a"`, Audience{"a"}},
+		{`["a","b"]`, Audience{"a", "b"}},
+	}
+	for i, tt := range tests {
+		var a Audience
+		err := json.Unmarshal([]byte(tt.in), &a)
+		if err != nil {
+			t.Errorf("%d. Unmarshal err\nhave %v\nwant %v", i, err, nil)
+			continue
+		}
+		if !reflect.DeepEqual(a, tt.out) {
+			t.Errorf("%d. Unmarshal\nhave %v\nwant %v", i, a, tt.out)
+		}
+		b, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.in {
+			t.Errorf("%d. Marshal\nhave %s\nwant %s", i, b, tt.in)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto"
 	"crypto/subtle"
 	"encoding/base64"
+	"strings"
 )
 
 var b64 = base64.RawURLEncoding
@@ -40,3 +41,26 @@ func hash(hash crypto.Hash, b []byte) ([]byte, error) {
 	}
 	return h.Sum(nil), nil
 }
+
+// contains returns true if s is an element of list.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaType returns the normalized form of a typ or cty header value.
+// Media types are compared case-insensitively and the "application/"
+// prefix is omitted when no other slash is present.
+//
+// See RFC 7515 Section 4.1.9.
+func mediaType(typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if t := strings.TrimPrefix(typ, "application/"); !strings.Contains(t, "/") {
+		return t
+	}
+	return typ
+}
@@ -0,0 +1,761 @@
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TokenVerifier is the interface that verifies raw tokens.
+// Application code may depend on this interface to substitute
+// fakes in tests.
+type TokenVerifier interface {
+	Verify(ctx context.Context, raw string) (*Token, error)
+}
+
+// Verifier validates tokens against a verification policy.
+type Verifier struct {
+	signers       map[string]Signer
+	keys          KeyProvider
+	issuers       []string
+	audiences     []string
+	audienceEqual AudienceComparator
+	leeway        time.Duration
+	required      []string
+	millis        []string
+	millisAll     bool
+	malformed     func(sample, reason string)
+	strictTyp     bool
+	types         []string
+	expRequired   bool
+	replicated    ReplicatedClaims
+	logger        *slog.Logger
+	rejected      *rejectedCache
+	revocations   Revocations
+	now           func() time.Time
+	limits        Limits
+	tenantClaim   string
+	tenant        func(ctx context.Context) (string, bool)
+	intern        bool
+	stringOrURI   bool
+	subsecond     bool
+	nonce         *string
+	legacyBase64  bool
+	jtiRequired   bool
+	jtiFormats    []JTIFormat
+	rules         []Rule
+	killSwitch    *KillSwitch
+	maxAge        time.Duration
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithIssuer returns an option that requires the iss claim
+// to match one of the provided issuers.
+func WithIssuer(iss ...string) Option {
+	return func(v *Verifier) {
+		v.issuers = append(v.issuers, iss...)
+	}
+}
+
+// WithAudience returns an option that requires the aud claim
+// to contain at least one of the provided audiences.
+func WithAudience(aud ...string) Option {
+	return func(v *Verifier) {
+		v.audiences = append(v.audiences, aud...)
+	}
+}
+
+// WithLeeway returns an option that allows for clock skew
+// when validating the exp and nbf claims. The leeway is truncated to
+// whole seconds unless WithSubsecondPrecision is used.
+func WithLeeway(d time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = d
+	}
+}
+
+// WithClock returns an option that evaluates the exp and nbf claims at
+// the time returned by now rather than time.Now, such as a fake clock
+// in tests or a trusted time service. See WithEvaluationTime.
+func WithClock(now func() time.Time) Option {
+	return func(v *Verifier) {
+		v.now = now
+	}
+}
+
+// WithRequired returns an option that rejects tokens missing any of
+// the named claims with ErrClaimRequired. Claims accumulate across
+// uses. A claim that is present with a null value is not missing.
+func WithRequired(claims ...string) Option {
+	return func(v *Verifier) {
+		v.required = append(v.required, claims...)
+	}
+}
+
+// WithExpirationRequired returns an option that rejects tokens without
+// an exp claim with ErrClaimRequired. By default a token without an
+// exp claim never expires.
+func WithExpirationRequired() Option {
+	return func(v *Verifier) {
+		v.expRequired = true
+	}
+}
+
+// WithMilliseconds returns an option that accepts exp, nbf and iat
+// claims expressed in milliseconds rather than seconds, for interop
+// with legacy issuers. It applies to tokens from the provided issuers,
+// or to all tokens if none are provided. Values too large to be a
+// plausible date in seconds are treated as milliseconds.
+func WithMilliseconds(iss ...string) Option {
+	return func(v *Verifier) {
+		v.millis = append(v.millis, iss...)
+		v.millisAll = v.millisAll || len(iss) == 0
+	}
+}
+
+// WithMalformedHook returns an option that calls fn whenever a token
+// is rejected because a segment count, encoding or JSON document is
+// malformed. The sample is the token truncated to
+// at most 32 bytes so that complete tokens are never exposed to the hook.
+func WithMalformedHook(fn func(sample, reason string)) Option {
+	return func(v *Verifier) {
+		v.malformed = fn
+	}
+}
+
+// WithAcceptedTypes returns an option that replaces the accepted
+// typ header values. The default is "JWT". The empty string accepts
+// tokens without a typ header.
+func WithAcceptedTypes(types ...string) Option {
+	return func(v *Verifier) {
+		v.types = types
+	}
+}
+
+// TypeEqual returns true if the typ header values a and b name the same
+// media type, ignoring case and an "application/" prefix as RFC 7515
+// allows. This is the comparison used for accepted types.
+func TypeEqual(a, b string) bool {
+	return mediaType(a) == mediaType(b)
+}
+
+// WithStrictType returns an option that requires the typ header to be
+// exactly an accepted type rather than comparing media types as
+// RFC 7515 allows.
+func WithStrictType() Option {
+	return func(v *Verifier) {
+		v.strictTyp = true
+	}
+}
+
+// ReplicatedClaims is the policy for registered claims replicated as
+// header parameters, as permitted by RFC 7519 Section 5.3.
+type ReplicatedClaims int
+
+// Replicated claims policies.
+const (
+	// ReplicatedIgnore ignores claims replicated in the header.
+	ReplicatedIgnore ReplicatedClaims = iota
+
+	// ReplicatedRequireMatch requires claims replicated in the header
+	// to be present in the payload with an identical value.
+	ReplicatedRequireMatch
+
+	// ReplicatedPreferPayload uses claims replicated in the header only
+	// when they are absent from the payload.
+	ReplicatedPreferPayload
+)
+
+// replicable is the set of registered claims that may be replicated.
+var replicable = []string{ClaimIssuer, ClaimSubject, ClaimAudience, ClaimExpiration, ClaimNotBefore, ClaimIssuedAt, ClaimJWTID}
+
+// WithReplicatedClaims returns an option that sets the policy for
+// registered claims replicated in the header.
+func WithReplicatedClaims(policy ReplicatedClaims) Option {
+	return func(v *Verifier) {
+		v.replicated = policy
+	}
+}
+
+// WithLogger returns an option that logs verification failures to l at
+// debug level. Tokens are identified by fingerprint and never logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(v *Verifier) {
+		v.logger = l
+	}
+}
+
+// NewVerifier returns a new Verifier that accepts tokens signed
+// by any of the signers s using keys from the key provider.
+//
+// The alg header is only used to select from the accepted signers.
+func NewVerifier(s []Signer, keys KeyProvider, opts ...Option) *Verifier {
+	v := &Verifier{
+		signers: make(map[string]Signer),
+		keys:    keys,
+	}
+	for _, signer := range s {
+		if signer != nil {
+			v.signers[signer.String()] = signer
+		}
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.intern {
+		internStrings(v.issuers)
+		internStrings(v.audiences)
+	}
+	return v
+}
+
+// Verify parses and validates jwt.
+func (v *Verifier) Verify(ctx context.Context, jwt string) (*Token, error) {
+	if v.rejected != nil && v.rejected.contains(jwt) {
+		return nil, ErrInvalidSignature
+	}
+	var err error
+	t := v.run(ctx, jwt, func(c Check) bool {
+		err = c.Err
+		if v.rejected != nil && c.Name == "signature" && errors.Is(c.Err, ErrInvalidSignature) {
+			v.rejected.add(jwt)
+		}
+		if c.Status == CheckFail && v.logger != nil {
+			v.logger.LogAttrs(ctx, slog.LevelDebug, "jwt: verification failed",
+				slog.String("check", c.Name),
+				slog.String("token", fingerprint(jwt)),
+				slog.Any("error", c.Err),
+			)
+		}
+		return c.Status != CheckFail
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// verification is the state shared by the stages of a verification.
+type verification struct {
+	jwt   string
+	parts []string
+	token *Token
+	key   interface{}
+	dates claimDates
+}
+
+// stage is a single named step of a verification.
+type stage struct {
+	name  string
+	needs string
+	fn    func(v *Verifier, ctx context.Context, s *verification) error
+}
+
+// skipped is returned by a stage that does not apply to the token.
+type skipped string
+
+func (s skipped) Error() string {
+	return string(s)
+}
+
+// stages are the verification steps in evaluation order. A stage is
+// skipped when the stage it needs did not pass.
+var stages = []stage{
+	{"format", "", (*Verifier).checkFormat},
+	{"header", "format", (*Verifier).checkHeader},
+	{"killswitch", "header", (*Verifier).checkKillSwitch},
+	{"typ", "header", (*Verifier).checkType},
+	{"alg", "header", (*Verifier).checkAlgorithm},
+	{"key", "alg", (*Verifier).checkKey},
+	{"signature", "key", (*Verifier).checkSignature},
+	{"claims", "format", (*Verifier).checkClaims},
+	{"replicated", "claims", (*Verifier).checkReplicated},
+	{"exp", "claims", (*Verifier).checkExpiration},
+	{"nbf", "claims", (*Verifier).checkNotBefore},
+	{"iat", "claims", (*Verifier).checkIssuedAt},
+	{"required", "claims", (*Verifier).checkRequired},
+	{"uri", "claims", (*Verifier).checkStringOrURI},
+	{"iss", "claims", (*Verifier).checkIssuer},
+	{"aud", "claims", (*Verifier).checkAudience},
+	{"tenant", "claims", (*Verifier).checkTenant},
+	{"sid", "claims", (*Verifier).checkSession},
+	{"jti", "claims", (*Verifier).checkJTI},
+	{"nonce", "claims", (*Verifier).checkNonce},
+	{"rules", "claims", (*Verifier).checkRules},
+	{"revoked", "claims", (*Verifier).checkRevoked},
+}
+
+// run evaluates each stage against jwt, passing the result of each to
+// emit. Evaluation stops early if emit returns false.
+func (v *Verifier) run(ctx context.Context, jwt string, emit func(Check) bool) *Token {
+	s := &verification{jwt: jwt, token: &Token{}}
+	passed := make(map[string]bool)
+	for _, st := range stages {
+		c := Check{Name: st.name, Status: CheckPass}
+		if st.needs != "" && !passed[st.needs] {
+			c.Status = CheckSkip
+			c.Reason = st.needs + " check did not pass"
+		} else if err := st.fn(v, ctx, s); err != nil {
+			if reason, ok := err.(skipped); ok {
+				c.Status = CheckSkip
+				c.Reason = string(reason)
+			} else {
+				c.Status = CheckFail
+				c.Reason = err.Error()
+				c.Err = err
+			}
+		}
+		passed[st.name] = c.Status == CheckPass
+		if !emit(c) {
+			break
+		}
+	}
+	return s.token
+}
+
+func (v *Verifier) checkFormat(ctx context.Context, s *verification) error {
+	if len(v.signers) == 0 {
+		return ErrSigner
+	}
+	s.parts = strings.Split(s.jwt, sep)
+	if len(s.parts) != 3 {
+		v.reportMalformed(s, fmt.Sprintf("token has %d segments, want 3", len(s.parts)))
+		return ErrMalformed
+	}
+	return nil
+}
+
+// reportMalformed calls the malformed hook, if any, with a sample of
+// the token and the reason it was rejected.
+func (v *Verifier) reportMalformed(s *verification, reason string) {
+	if v.malformed != nil {
+		v.malformed(sample(s.jwt), reason)
+	}
+}
+
+// sampleSize is the maximum length of a token passed to the malformed hook.
+const sampleSize = 32
+
+// sample returns jwt truncated to sampleSize bytes.
+func sample(jwt string) string {
+	if len(jwt) > sampleSize {
+		return jwt[:sampleSize]
+	}
+	return jwt
+}
+
+func (v *Verifier) checkHeader(ctx context.Context, s *verification) error {
+	h, err := v.decode(s.parts[0])
+	if err != nil {
+		v.reportMalformed(s, "header is not valid base64url")
+		return err
+	}
+	err = v.limits.check("header", h)
+	if err != nil {
+		return err
+	}
+	err = unmarshalSegment("header", h, &s.token.Header)
+	if err != nil {
+		v.reportMalformed(s, "header is not a valid JSON object")
+		return err
+	}
+	return nil
+}
+
+func (v *Verifier) checkType(ctx context.Context, s *verification) error {
+	types := v.types
+	if len(types) == 0 {
+		types = []string{"JWT"}
+	}
+	h, ok := s.token.Header[HeaderType]
+	if !ok {
+		if contains(types, "") {
+			return nil
+		}
+		return ErrHeaderTyp
+	}
+	typ, ok := h.(string)
+	if !ok {
+		return ErrHeaderTyp
+	}
+	for _, want := range types {
+		if want == "" {
+			continue
+		}
+		if v.strictTyp && typ == want {
+			return nil
+		}
+		if !v.strictTyp && TypeEqual(typ, want) {
+			return nil
+		}
+	}
+	return ErrHeaderTyp
+}
+
+func (v *Verifier) checkAlgorithm(ctx context.Context, s *verification) error {
+	alg, _ := s.token.Header[HeaderAlgorithm].(string)
+	signer, ok := v.signers[alg]
+	if !ok {
+		return ErrHeaderAlg
+	}
+	s.token.signer = signer
+	return nil
+}
+
+func (v *Verifier) checkKey(ctx context.Context, s *verification) error {
+	if p, ok := v.keys.(NativeKeyProvider); ok {
+		key, err := p.NativeKey(ctx, s.token)
+		if err != nil {
+			return err
+		}
+		s.key = key
+		return v.checkKeyFamily(s.token.signer, key)
+	}
+	key, err := v.keys.Key(ctx, s.token)
+	if err != nil {
+		return err
+	}
+	s.key = key
+	return v.checkKeyFamily(s.token.signer, key)
+}
+
+func (v *Verifier) checkSignature(ctx context.Context, s *verification) error {
+	b := strings.Join(s.parts[:2], sep)
+	sig, err := v.decode(s.parts[2])
+	if err != nil {
+		v.reportMalformed(s, "signature is not valid base64url")
+		return err
+	}
+	return verifyKey(s.token.signer, []byte(b), sig, s.key)
+}
+
+func (v *Verifier) checkClaims(ctx context.Context, s *verification) error {
+	c, err := v.decode(s.parts[1])
+	if err != nil {
+		v.reportMalformed(s, "claims are not valid base64url")
+		return err
+	}
+	err = v.limits.check("claims", c)
+	if err != nil {
+		return err
+	}
+	err = unmarshalSegment("claims", c, &s.token.Claims)
+	var e *json.UnmarshalTypeError
+	if errors.As(err, &e) && strings.HasPrefix(e.Value, "number") && contains(dateClaims, e.Field) {
+		return ErrClaimRange
+	}
+	if err != nil {
+		v.reportMalformed(s, "claims are not a valid JSON object")
+		return err
+	}
+	if s.token.Claims == nil {
+		s.token.Claims = make(map[string]interface{})
+	}
+	s.dates.decode(c, s.token.Claims)
+	if v.intern {
+		internClaims(s.token.Claims)
+	}
+	return nil
+}
+
+func (v *Verifier) checkReplicated(ctx context.Context, s *verification) error {
+	if v.replicated == ReplicatedIgnore {
+		return skipped("replicated claims are ignored")
+	}
+	for _, name := range replicable {
+		h, ok := s.token.Header[name]
+		if !ok {
+			continue
+		}
+		c, ok := s.token.Claims[name]
+		switch v.replicated {
+		case ReplicatedRequireMatch:
+			if !ok || !reflect.DeepEqual(h, c) {
+				return ErrClaimReplicated
+			}
+		case ReplicatedPreferPayload:
+			if !ok {
+				s.token.Claims[name] = h
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) checkExpiration(ctx context.Context, s *verification) error {
+	exp, ok, err := v.timestamp(s, ClaimExpiration)
+	if err != nil {
+		return err
+	}
+	if !ok && v.expRequired {
+		return ErrClaimRequired
+	}
+	if !ok {
+		return skipped("exp claim is not present")
+	}
+	if v.expired(exp) {
+		return ErrClaimExpired
+	}
+	return nil
+}
+
+func (v *Verifier) checkNotBefore(ctx context.Context, s *verification) error {
+	nbf, ok, err := v.timestamp(s, ClaimNotBefore)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return skipped("nbf claim is not present")
+	}
+	if v.notYetValid(nbf) {
+		return ErrClaimNotBefore
+	}
+	return nil
+}
+
+func (v *Verifier) checkRequired(ctx context.Context, s *verification) error {
+	if len(v.required) == 0 {
+		return skipped("no claims are required")
+	}
+	for _, name := range v.required {
+		if _, ok := s.token.Claims[name]; !ok {
+			return ErrClaimRequired
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) checkIssuer(ctx context.Context, s *verification) error {
+	if len(v.issuers) == 0 {
+		return skipped("no issuers are configured")
+	}
+	iss, _ := s.token.Claims[ClaimIssuer].(string)
+	if !contains(v.issuers, iss) {
+		return ErrClaimIssuer
+	}
+	return nil
+}
+
+func (v *Verifier) checkAudience(ctx context.Context, s *verification) error {
+	if len(v.audiences) == 0 {
+		return skipped("no audiences are configured")
+	}
+	equal := v.audienceEqual
+	if equal == nil {
+		equal = AudienceExact
+	}
+	for _, aud := range audience(s.token.Claims[ClaimAudience]) {
+		for _, want := range v.audiences {
+			if equal(want, aud) {
+				return nil
+			}
+		}
+	}
+	return ErrClaimAudience
+}
+
+func (v *Verifier) checkSession(ctx context.Context, s *verification) error {
+	c, ok := s.token.Claims[ClaimSessionID]
+	if !ok {
+		return skipped("sid claim is not present")
+	}
+	if sid, ok := c.(string); !ok || sid == "" {
+		return ErrClaimType
+	}
+	return nil
+}
+
+func (v *Verifier) checkRevoked(ctx context.Context, s *verification) error {
+	if v.revocations == nil {
+		return skipped("no revocations are configured")
+	}
+	revoked, err := v.revocations.Revoked(ctx, s.token)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrRevoked
+	}
+	return nil
+}
+
+// time returns the time at which tokens are evaluated.
+func (v *Verifier) time() time.Time {
+	if v.now != nil {
+		return v.now()
+	}
+	return time.Now()
+}
+
+// millisecondThreshold is the smallest value treated as milliseconds.
+// As seconds it is a date in the year 5138, as milliseconds in 1973.
+const millisecondThreshold = 1e11
+
+// dateClaims is the set of registered claims holding a NumericDate.
+var dateClaims = []string{ClaimExpiration, ClaimNotBefore, ClaimIssuedAt}
+
+// Bounds of date claims, the first and last seconds of years 1 and 9999.
+const (
+	minTimestamp = -62135596800
+	maxTimestamp = 253402300799
+)
+
+// claimDates holds the literal values of the date claims decoded from
+// the claims segment, so that they are compared as a json.Number rather
+// than the float64 in the claims.
+type claimDates struct {
+	Exp json.RawMessage `json:"exp"`
+	Nbf json.RawMessage `json:"nbf"`
+	Iat json.RawMessage `json:"iat"`
+}
+
+// decode decodes the date claims of the claims segment b. Field names
+// are matched case-insensitively when decoding a struct, so the values
+// are discarded if claims has a name differing from a date claim only
+// in case, falling back to the float64 in claims.
+func (d *claimDates) decode(b []byte, claims map[string]interface{}) {
+	*d = claimDates{}
+	for name := range claims {
+		if len(name) == 3 && !contains(dateClaims, name) && containsFold(dateClaims, name) {
+			return
+		}
+	}
+	err := json.Unmarshal(b, d)
+	if err != nil {
+		*d = claimDates{}
+	}
+}
+
+// number returns the named date claim as a json.Number. It returns
+// false if the claim was not decoded or is not a number.
+func (d *claimDates) number(name string) (json.Number, bool) {
+	var b json.RawMessage
+	switch name {
+	case ClaimExpiration:
+		b = d.Exp
+	case ClaimNotBefore:
+		b = d.Nbf
+	case ClaimIssuedAt:
+		b = d.Iat
+	}
+	if len(b) == 0 || b[0] != '-' && (b[0] < '0' || b[0] > '9') {
+		return "", false
+	}
+	return json.Number(b), true
+}
+
+// containsFold returns true if s is in values under Unicode case-folding.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// timestamp returns the named date claim as a time and whether the
+// claim is present. The claim is compared as a json.Number, exactly for
+// integers. Fractional seconds are kept with subsecond precision and
+// otherwise truncated as for a NumericDate. A present claim that is not
+// a number returns ErrClaimType and a claim outside of the years 1
+// through 9999 returns ErrClaimRange rather than being ignored or
+// silently wrapping around when converted to an integer.
+func (v *Verifier) timestamp(s *verification, name string) (time.Time, bool, error) {
+	c, ok := s.token.Claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	f, ok := c.(float64)
+	if !ok {
+		return time.Time{}, true, ErrClaimType
+	}
+	millis := v.milliseconds(s.token)
+	if n, ok := s.dates.number(name); ok {
+		if i, err := n.Int64(); err == nil {
+			return v.integerTime(i, millis)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return time.Time{}, true, ErrClaimRange
+		}
+		return v.floatTime(f, millis)
+	}
+	return v.floatTime(f, millis)
+}
+
+// integerTime returns the date claim i as a time. It is exact for any
+// integer, including those too large to be represented by a float64.
+func (v *Verifier) integerTime(i int64, millis bool) (time.Time, bool, error) {
+	sec, nsec := i, int64(0)
+	if i >= millisecondThreshold && millis {
+		sec, nsec = i/1000, i%1000*int64(time.Millisecond)
+	}
+	if sec < minTimestamp || sec > maxTimestamp {
+		return time.Time{}, true, ErrClaimRange
+	}
+	if !v.subsecond {
+		nsec = 0
+	}
+	return time.Unix(sec, nsec), true, nil
+}
+
+// floatTime returns the date claim f with a fractional part as a time.
+func (v *Verifier) floatTime(f float64, millis bool) (time.Time, bool, error) {
+	if f >= millisecondThreshold && millis {
+		f /= 1000
+	}
+	if f < minTimestamp || f > maxTimestamp {
+		return time.Time{}, true, ErrClaimRange
+	}
+	if v.subsecond {
+		return unixTime(f), true, nil
+	}
+	d, _ := floatDate(f)
+	return d.Time(), true, nil
+}
+
+// milliseconds returns true if the token dates may be in milliseconds.
+func (v *Verifier) milliseconds(t *Token) bool {
+	if v.millisAll {
+		return true
+	}
+	iss, _ := t.Claims[ClaimIssuer].(string)
+	return len(v.millis) > 0 && contains(v.millis, iss)
+}
+
+// audience returns the aud claim value as a slice.
+// The aud claim may be either a single string or an array of strings.
+func audience(v interface{}) []string {
+	switch aud := v.(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		rv := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				rv = append(rv, s)
+			}
+		}
+		return rv
+	case []string:
+		return aud
+	}
+	return nil
+}
+
+// fingerprint returns a short digest identifying jwt in logs without
+// revealing the token.
+func fingerprint(jwt string) string {
+	sum := sha256.Sum256([]byte(jwt))
+	return hex.EncodeToString(sum[:8])
+}
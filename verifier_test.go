@@ -0,0 +1,433 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMilliseconds(t *testing.T) {
+	future := time.Now().Add(time.Hour).UnixMilli()
+	past := time.Now().Add(-time.Hour).UnixMilli()
+	var tests = []struct {
+		claims map[string]interface{}
+		opts   []Option
+		err    error
+	}{
+		{map[string]interface{}{"exp": past}, nil, ErrClaimRange},
+		{map[string]interface{}{"exp": past}, []Option{WithMilliseconds()}, ErrClaimExpired},
+		{map[string]interface{}{"exp": future}, []Option{WithMilliseconds()}, nil},
+		{map[string]interface{}{"nbf": future}, []Option{WithMilliseconds()}, ErrClaimNotBefore},
+		{map[string]interface{}{"exp": past, "iss": "legacy"}, []Option{WithMilliseconds("legacy")}, ErrClaimExpired},
+		{map[string]interface{}{"exp": past, "iss": "modern"}, []Option{WithMilliseconds("legacy")}, ErrClaimRange},
+		{map[string]interface{}{"exp": expired}, []Option{WithMilliseconds()}, ErrClaimExpired},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Claims = tt.claims
+		jwt, err := token.Sign([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Parse(HS256, jwt, []byte("secret"), tt.opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestWithMalformedHook(t *testing.T) {
+	var tests = []struct {
+		jwt    string
+		sample string
+		reason string
+	}{
+		{"abc", "abc", "token has 1 segments, want 3"},
+		{"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJmb28iOiJiYXIifQ", "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpX", "token has 2 segments, want 3"},
+		{"e!.e30.c2ln", "e!.e30.c2ln", "header is not valid base64url"},
+		{"bm90IGpzb24.e30.c2ln", "bm90IGpzb24.e30.c2ln", "header is not a valid JSON object"},
+		{signEncoded(t, "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e!"), "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpX", "claims are not valid base64url"},
+		{signEncoded(t, "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.W10"), "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpX", "claims are not a valid JSON object"},
+		{"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.c!", "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpX", "signature is not valid base64url"},
+	}
+	for i, tt := range tests {
+		var sample, reason string
+		_, err := Parse(HS256, tt.jwt, []byte("secret"), WithMalformedHook(func(s, r string) {
+			sample, reason = s, r
+		}))
+		if err == nil {
+			t.Errorf("%d. Parse should reject a malformed token", i)
+		}
+		if sample != tt.sample {
+			t.Errorf("%d. hook sample\nhave %v\nwant %v", i, sample, tt.sample)
+		}
+		if reason != tt.reason {
+			t.Errorf("%d. hook reason\nhave %v\nwant %v", i, reason, tt.reason)
+		}
+	}
+}
+
+func TestHeaderType(t *testing.T) {
+	var tests = []struct {
+		typ  interface{}
+		opts []Option
+		err  error
+	}{
+		{"JWT", nil, nil},
+		{"jwt", nil, nil},
+		{" Jwt ", nil, nil},
+		{"application/jwt", nil, nil},
+		{"JOSE", nil, ErrHeaderTyp},
+		{"text/jwt", nil, ErrHeaderTyp},
+		{nil, nil, ErrHeaderTyp},
+		{"JWT", []Option{WithStrictType()}, nil},
+		{"jwt", []Option{WithStrictType()}, ErrHeaderTyp},
+		{nil, []Option{WithAcceptedTypes("JWT", "")}, nil},
+		{"at+jwt", []Option{WithAcceptedTypes("JWT", "at+jwt", "")}, nil},
+		{"JOSE", []Option{WithAcceptedTypes("JWT", "")}, ErrHeaderTyp},
+		{"", []Option{WithAcceptedTypes("")}, ErrHeaderTyp},
+	}
+	for i, tt := range tests {
+		h := encode([]byte(`{"alg":"HS256"}`))
+		if tt.typ != nil {
+			h = encode([]byte(`{"alg":"HS256","typ":"` + tt.typ.(string) + `"}`))
+		}
+		c := encode([]byte(`{}`))
+		sig, err := HS256.Sign([]byte(h+sep+c), []byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Parse(HS256, h+sep+c+sep+encode(sig), []byte("secret"), tt.opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestWithReplicatedClaims(t *testing.T) {
+	var tests = []struct {
+		header map[string]interface{}
+		claims map[string]interface{}
+		policy ReplicatedClaims
+		iss    interface{}
+		err    error
+	}{
+		{map[string]interface{}{"iss": "a"}, map[string]interface{}{}, ReplicatedIgnore, nil, nil},
+		{map[string]interface{}{"iss": "a"}, map[string]interface{}{"iss": "a"}, ReplicatedRequireMatch, "a", nil},
+		{map[string]interface{}{"iss": "a"}, map[string]interface{}{"iss": "b"}, ReplicatedRequireMatch, nil, ErrClaimReplicated},
+		{map[string]interface{}{"iss": "a"}, map[string]interface{}{}, ReplicatedRequireMatch, nil, ErrClaimReplicated},
+		{map[string]interface{}{"iss": "a"}, map[string]interface{}{}, ReplicatedPreferPayload, "a", nil},
+		{map[string]interface{}{"iss": "a"}, map[string]interface{}{"iss": "b"}, ReplicatedPreferPayload, "b", nil},
+		{map[string]interface{}{"exp": expired}, map[string]interface{}{}, ReplicatedPreferPayload, nil, ErrClaimExpired},
+	}
+	for i, tt := range tests {
+		token := New(HS256)
+		token.Header = tt.header
+		token.Claims = tt.claims
+		jwt, err := token.Sign([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(HS256, jwt, []byte("secret"), WithReplicatedClaims(tt.policy))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && parsed.Claims["iss"] != tt.iss {
+			t.Errorf("%d. Parse iss\nhave %v\nwant %v", i, parsed.Claims["iss"], tt.iss)
+		}
+	}
+}
+
+// signRaw returns a HS256 token with the raw header and payload.
+func signRaw(t *testing.T, header, payload string) string {
+	return signEncoded(t, encode([]byte(header))+sep+encode([]byte(payload)))
+}
+
+func signEncoded(t *testing.T, input string) string {
+	sig, err := HS256.Sign([]byte(input), []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + sep + encode(sig)
+}
+
+func TestMissingClaims(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		payload string
+		opts    []Option
+		err     error
+	}{
+		{`{}`, nil, nil},
+		{`null`, nil, nil},
+		{`{}`, []Option{WithExpirationRequired()}, ErrClaimRequired},
+		{`{"exp":9999999999}`, []Option{WithExpirationRequired()}, nil},
+		{`{"exp":"9999999999"}`, nil, ErrClaimType},
+		{`{"nbf":true}`, nil, ErrClaimType},
+		{`{"exp":null}`, nil, ErrClaimType},
+		{`{}`, []Option{WithRequired("sub")}, ErrClaimRequired},
+	}
+	for i, tt := range tests {
+		token, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), tt.opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && token.Claims == nil {
+			t.Errorf("%d. Parse claims should not be nil", i)
+		}
+	}
+	_, err := Parse(HS256, signRaw(t, header, `[]`), []byte("secret"))
+	if err == nil {
+		t.Errorf("should reject non-object claims")
+	}
+}
+
+func TestWithRequired(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		payload string
+		opts    []Option
+		err     error
+	}{
+		{`{"exp":9999999999,"iss":"a","sub":"b"}`, []Option{WithRequired("exp", "iss", "sub")}, nil},
+		{`{"iss":"a","sub":"b"}`, []Option{WithRequired("exp", "iss", "sub")}, ErrClaimRequired},
+		{`{"exp":9999999999,"sub":"b"}`, []Option{WithRequired("exp", "iss", "sub")}, ErrClaimRequired},
+		{`{"exp":9999999999,"iss":"a"}`, []Option{WithRequired("exp", "iss", "sub")}, ErrClaimRequired},
+		{`{"exp":9999999999}`, []Option{WithRequired("exp"), WithRequired("sub")}, ErrClaimRequired},
+		{`{"exp":9999999999,"sub":"b"}`, []Option{WithRequired("exp"), WithRequired("sub")}, nil},
+		{`{"sub":null}`, []Option{WithRequired("sub")}, nil},
+		{`{}`, []Option{WithRequired()}, nil},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), tt.opts...)
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestWithIssuer(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		payload string
+		issuers []string
+		err     error
+	}{
+		{`{"iss":"https://issuer.example"}`, []string{"https://issuer.example"}, nil},
+		{`{"iss":"https://b.example"}`, []string{"https://a.example", "https://b.example"}, nil},
+		{`{"iss":"https://other.example"}`, []string{"https://issuer.example"}, ErrClaimIssuer},
+		{`{"iss":"https://issuer.example/"}`, []string{"https://issuer.example"}, ErrClaimIssuer},
+		{`{}`, []string{"https://issuer.example"}, ErrClaimIssuer},
+		{`{"iss":1}`, []string{"https://issuer.example"}, ErrClaimIssuer},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), WithIssuer(tt.issuers...))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestWithLeeway(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	now := time.Unix(1700000000, 0)
+	var tests = []struct {
+		payload string
+		leeway  time.Duration
+		err     error
+	}{
+		{`{"exp":1699999970}`, 0, ErrClaimExpired},
+		{`{"exp":1699999970}`, time.Minute, nil},
+		{`{"exp":1699999970}`, 30 * time.Second, nil},
+		{`{"exp":1699999970}`, 29 * time.Second, ErrClaimExpired},
+		{`{"nbf":1700000030}`, 0, ErrClaimNotBefore},
+		{`{"nbf":1700000030}`, time.Minute, nil},
+		{`{"nbf":1700000030}`, 29 * time.Second, ErrClaimNotBefore},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"), WithEvaluationTime(now), WithLeeway(tt.leeway))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := NewVerifier([]Signer{HS256}, StaticKey([]byte("secret")), WithClock(func() time.Time {
+		return now
+	}))
+	jwt := signRaw(t, `{"alg":"HS256","typ":"JWT"}`, `{"nbf":1700000000,"exp":1700000060}`)
+	var tests = []struct {
+		now time.Time
+		err error
+	}{
+		{time.Unix(1699999999, 0), ErrClaimNotBefore},
+		{time.Unix(1700000000, 0), nil},
+		{time.Unix(1700000060, 0), nil},
+		{time.Unix(1700000061, 0), ErrClaimExpired},
+	}
+	for i, tt := range tests {
+		now = tt.now
+		_, err := v.Verify(context.Background(), jwt)
+		if err != tt.err {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestClaimRange(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	var tests = []struct {
+		payload string
+		err     error
+	}{
+		{`{"exp":253402300799}`, nil},
+		{`{"exp":253402300800}`, ErrClaimRange},
+		{`{"exp":9999999999999999999}`, ErrClaimRange},
+		{`{"nbf":9999999999999999999}`, ErrClaimRange},
+		{`{"nbf":-9999999999999999999}`, ErrClaimRange},
+		{`{"exp":1e308}`, ErrClaimRange},
+		{`{"nbf":-62135596800}`, nil},
+		{`{"nbf":1e700}`, ErrClaimRange},
+	}
+	for i, tt := range tests {
+		_, err := Parse(HS256, signRaw(t, header, tt.payload), []byte("secret"))
+		if err != tt.err {
+			t.Errorf("%d. Parse err\nhave %v\nwant %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	token := New(HS256)
+	token.Claims["sub"] = "user"
+	jwt, err := token.Sign([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(HS256, jwt, []byte("other"), WithLogger(l))
+	if err != ErrInvalidSignature {
+		t.Fatalf("Parse err\nhave %v\nwant %v", err, ErrInvalidSignature)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "verification failed") || !strings.Contains(out, "check=signature") {
+		t.Errorf("should log failed check, have %q", out)
+	}
+	for _, part := range strings.Split(jwt, ".") {
+		if strings.Contains(out, part) {
+			t.Errorf("should redact token, have %q", out)
+		}
+	}
+}
+
+type countingKey struct {
+	key []byte
+	n   int
+}
+
+func (k *countingKey) Key(ctx context.Context, t *Token) ([]byte, error) {
+	k.n++
+	return k.key, nil
+}
+
+func TestWithRejectedCache(t *testing.T) {
+	forged := make([]string, 3)
+	for i := range forged {
+		token := New(HS256)
+		token.Claims["sub"] = strconv.Itoa(i)
+		jwt, err := token.Sign([]byte("other"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		forged[i] = jwt
+	}
+	keys := &countingKey{key: []byte("secret")}
+	v := NewVerifier([]Signer{HS256}, keys, WithRejectedCache(2, time.Minute))
+	var tests = []struct {
+		jwt string
+		n   int
+	}{
+		{forged[0], 1},
+		{forged[0], 1},
+		{forged[1], 2},
+		{forged[2], 3},
+		{forged[1], 3},
+		{forged[0], 4},
+	}
+	for i, tt := range tests {
+		_, err := v.Verify(context.Background(), tt.jwt)
+		if err != ErrInvalidSignature {
+			t.Errorf("%d. Verify err\nhave %v\nwant %v", i, err, ErrInvalidSignature)
+		}
+		if keys.n != tt.n {
+			t.Errorf("%d. key lookups\nhave %d\nwant %d", i, keys.n, tt.n)
+		}
+	}
+	token := New(HS256)
+	jwt, err := token.Sign(keys.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = v.Verify(context.Background(), jwt)
+	if err != nil {
+		t.Errorf("should verify valid token: %v", err)
+	}
+}
+
+func TestRejectedCacheExpired(t *testing.T) {
+	c := newRejectedCache(2, time.Minute)
+	c.add("a")
+	c.entries[sha256.Sum256([]byte("a"))] = time.Now().Add(-time.Second)
+	if c.contains("a") {
+		t.Fatalf("should not contain an expired entry")
+	}
+	c.add("a")
+	c.add("b")
+	if !c.contains("a") {
+		t.Errorf("should contain an entry rejected again after expiry")
+	}
+	if !c.contains("b") {
+		t.Errorf("should contain the newest entry")
+	}
+	c.add("c")
+	if c.contains("a") {
+		t.Errorf("should evict the oldest entry")
+	}
+	if len(c.entries) != len(c.order) {
+		t.Errorf("entries\nhave %d\nwant %d", len(c.entries), len(c.order))
+	}
+}
+
+func FuzzClaimDates(f *testing.F) {
+	for _, seed := range []string{"0", "-1", "1.5", "1e308", "-1e308", "9223372036854775807", "9223372036854775808", "253402300799", "1e-400", "1e700"} {
+		f.Add(seed, seed)
+	}
+	header := `{"alg":"HS256","typ":"JWT"}`
+	f.Fuzz(func(t *testing.T, exp, nbf string) {
+		jwt := signRaw(t, header, `{"exp":`+exp+`,"nbf":`+nbf+`}`)
+		_, err := Parse(HS256, jwt, []byte("secret"), WithMilliseconds(), WithLeeway(time.Hour))
+		switch err {
+		case nil, ErrClaimExpired, ErrClaimNotBefore, ErrClaimType, ErrClaimRange:
+		default:
+			var syntax *json.SyntaxError
+			var typ *json.UnmarshalTypeError
+			if !errors.As(err, &syntax) && !errors.As(err, &typ) {
+				t.Errorf("unexpected error %v", err)
+			}
+		}
+	})
+}
+
+var _ TokenVerifier = (*Verifier)(nil)